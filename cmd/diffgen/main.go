@@ -0,0 +1,283 @@
+/*
+Command diffgen generates reflection-free diff functions for
+struct types, producing the same output as diff.ObjectsF.
+
+It is intended to be run by go generate:
+
+	//go:generate diffgen -type Config,Limits
+
+For each named type T, diffgen writes a function
+
+	func DiffT(format diff.Format, before, after T, opts ...diff.Option) ([]string, error)
+
+to the file given by -output, which defaults to the lowercased
+name of the first type followed by "_diff.go".
+
+Fields of basic types, types defined in the package whose
+underlying type is basic, and structs listed in -type are
+compared directly. Any other field, such as a slice, map, or
+type from another package, is handed to the reflection based
+differ so that the output remains identical to diff.ObjectsF.
+Options are applied to every field and struct as they would
+be by diff.ObjectsF, with the help of diff.Recorder, so they
+needn't be known when generating.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+
+	types := flag.String("type", "", "comma separated list of struct type names (required)")
+	output := flag.String("output", "", "output file name (default <type>_diff.go)")
+	flag.Parse()
+
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	names := strings.Split(*types, ",")
+	if *output == "" {
+		*output = strings.ToLower(names[0]) + "_diff.go"
+	}
+	out := filepath.Join(dir, *output)
+
+	src, err := generate(dir, names, filepath.Base(out))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diffgen:", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "diffgen:", err)
+		os.Exit(1)
+	}
+}
+
+/*
+generate parses the package in dir, excluding tests and the
+file it is about to overwrite, and returns the source for the
+diff functions of the named types.
+*/
+func generate(dir string, names []string, exclude string) ([]byte, error) {
+
+	fset := token.NewFileSet()
+	filter := func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != exclude
+	}
+
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	return generateFiles(pkg.Name, fileList(pkg), names)
+}
+
+func fileList(pkg *ast.Package) []*ast.File {
+	var names []string
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*ast.File, len(names))
+	for i, name := range names {
+		files[i] = pkg.Files[name]
+	}
+	return files
+}
+
+func generateFiles(pkgName string, files []*ast.File, names []string) ([]byte, error) {
+
+	g := generator{
+		decls:   map[string]ast.Expr{},
+		targets: map[string]bool{},
+	}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.decls[ts.Name.Name] = ts.Type
+			}
+		}
+	}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if _, ok := g.decls[name].(*ast.StructType); !ok {
+			return nil, fmt.Errorf("%s is not a struct type in package %s", name, pkgName)
+		}
+		g.targets[name] = true
+		g.order = append(g.order, name)
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by diffgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&g.buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(&g.buf, "import \"github.com/jakebowkett/go-diff/diff\"\n")
+
+	for _, name := range g.order {
+		g.genType(name)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+
+	return src, nil
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	decls   map[string]ast.Expr
+	targets map[string]bool
+	order   []string
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) genType(name string) {
+
+	st := g.decls[name].(*ast.StructType)
+
+	g.printf(`
+// Diff%[1]s returns the differences between before and after
+// exactly as diff.ObjectsF would, without using reflection for
+// fields that can be compared directly.
+func Diff%[1]s(format diff.Format, before, after %[1]s, opts ...diff.Option) ([]string, error) {
+	r, err := diff.NewRecorder(format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return r.Run(&before, &after, func(i int) error {
+		return diff%[1]sField(r, &before, &after, i)
+	})
+}
+
+func diff%[1]s(r *diff.Recorder, before, after *%[1]s) error {
+	return r.Struct(before, after, func(i int) error {
+		return diff%[1]sField(r, before, after, i)
+	})
+}
+
+func diff%[1]sField(r *diff.Recorder, before, after *%[1]s, i int) error {
+	switch i {`, name)
+
+	// Fields are numbered as reflect numbers them, which
+	// is how the Recorder refers to them.
+	index := 0
+	for _, f := range st.Fields.List {
+
+		var fieldNames []string
+		for _, n := range f.Names {
+			fieldNames = append(fieldNames, n.Name)
+		}
+		if len(fieldNames) == 0 {
+			fieldNames = []string{embeddedName(f.Type)}
+		}
+
+		for _, fn := range fieldNames {
+			// Blank fields can't be referred to directly
+			// so the Recorder diffs them by reflection.
+			if fn != "_" {
+				g.genField(index, fn, f.Type)
+			}
+			index++
+		}
+	}
+
+	g.printf("\n\t}\n\treturn nil\n}\n")
+}
+
+func (g *generator) genField(index int, name string, typ ast.Expr) {
+
+	g.printf("\n\tcase %d: // %s\n", index, name)
+
+	switch {
+	case g.isBasic(typ, 0):
+		g.printf(`		if before.%[1]s != after.%[1]s || r.Exhaustive() {
+			return r.Change(before.%[1]s, after.%[1]s)
+		}`, name)
+
+	case g.isTarget(typ):
+		g.printf("\t\treturn diff%s(r, &before.%s, &after.%s)", typ.(*ast.Ident).Name, name, name)
+
+	default:
+		g.printf("\t\treturn r.Diff(&before.%[1]s, &after.%[1]s)", name)
+	}
+}
+
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"byte": true, "rune": true,
+}
+
+/*
+isBasic reports whether typ is a basic type, or a type
+declared in the package whose underlying type is basic.
+Such values are compared by the differ with ==.
+*/
+func (g *generator) isBasic(typ ast.Expr, depth int) bool {
+	id, ok := typ.(*ast.Ident)
+	if !ok || depth > 10 {
+		return false
+	}
+	if decl, ok := g.decls[id.Name]; ok {
+		return g.isBasic(decl, depth+1)
+	}
+	return basicTypes[id.Name]
+}
+
+func (g *generator) isTarget(typ ast.Expr) bool {
+	id, ok := typ.(*ast.Ident)
+	return ok && g.targets[id.Name]
+}
+
+func embeddedName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFiles(t *testing.T) {
+
+	src := `package p
+
+type level int
+
+type T struct {
+	A, B   string
+	L      level
+	Secret string ` + "`diff:\"redact\"`" + `
+	_      int
+	S      []int
+	U      U
+}
+
+type U struct{ X int }
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := generateFiles("p", []*ast.File{f}, []string{"T"})
+	if err != nil {
+		t.Fatalf("generateFiles returned error: %v", err)
+	}
+
+	wants := []string{
+		"func DiffT(format diff.Format, before, after T, opts ...diff.Option) ([]string, error) {",
+		"return r.Run(&before, &after, func(i int) error {",
+		"return r.Struct(before, after, func(i int) error {",
+		"case 0: // A\n\t\tif before.A != after.A || r.Exhaustive() {",
+		"case 1: // B\n\t\tif before.B != after.B || r.Exhaustive() {",
+		"case 2: // L\n\t\tif before.L != after.L || r.Exhaustive() {",
+		"case 3: // Secret\n\t\tif before.Secret != after.Secret || r.Exhaustive() {",
+		"case 5: // S\n\t\treturn r.Diff(&before.S, &after.S)",
+		"case 6: // U\n\t\treturn r.Diff(&before.U, &after.U)",
+	}
+	for _, want := range wants {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated code is missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := generateFiles("p", []*ast.File{f}, []string{"level"}); err == nil {
+		t.Errorf("generateFiles for a non-struct type returned nil error")
+	}
+}
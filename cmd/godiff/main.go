@@ -0,0 +1,164 @@
+/*
+Command godiff prints the differences between two JSON, YAML,
+or TOML files, one per line, using package diff.
+
+	godiff [flags] before after
+
+The format of the files is taken from the extension of the
+first, or may be given with -type. Each difference is
+rendered with the package's default templates unless they
+are replaced with -change, -add, -delete, or -move, which
+take text/template strings as diff.Format does.
+
+	godiff -path-style json-pointer -change '{{.Name}}: {{.After}}' old.yaml new.yaml
+
+As with diff(1) the exit status is 0 if the files are the
+same, 1 if they differ, and 2 if something went wrong.
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jakebowkett/go-diff/diff"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+var pathStyles = map[string]diff.PathStyle{
+	"default":      diff.PathDefault,
+	"json-pointer": diff.PathJSONPointer,
+	"json-path":    diff.PathJSONPath,
+	"jq":           diff.PathJQ,
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+
+	fs := flag.NewFlagSet("godiff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: godiff [flags] before after")
+		fs.PrintDefaults()
+	}
+
+	var format diff.Format
+	typ := fs.String("type", "", "file format: json, yaml, or toml (default from the file extension)")
+	style := fs.String("path-style", "default", "path notation: default, json-pointer, json-path, or jq")
+	locale := fs.String("locale", "", "locale of the default templates, such as de")
+	moves := fs.Bool("moves", false, "report reordered elements as moves")
+	fs.StringVar(&format.Change, "change", "", "template for changed values")
+	fs.StringVar(&format.Add, "add", "", "template for added values")
+	fs.StringVar(&format.Delete, "delete", "", "template for deleted values")
+	fs.StringVar(&format.Move, "move", "", "template for moved elements")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	fail := func(err error) int {
+		fmt.Fprintln(stderr, "godiff:", err)
+		return 2
+	}
+
+	ps, ok := pathStyles[*style]
+	if !ok {
+		return fail(fmt.Errorf("unknown path style %q", *style))
+	}
+	opts := []diff.Option{diff.WithPathStyle(ps)}
+	if *locale != "" {
+		opts = append(opts, diff.WithLocale(*locale))
+	}
+	if *moves {
+		opts = append(opts, diff.WithMoves())
+	}
+
+	if *typ == "" {
+		*typ = fileType(fs.Arg(0))
+	}
+
+	before, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fail(err)
+	}
+	after, err := ioutil.ReadFile(fs.Arg(1))
+	if err != nil {
+		return fail(err)
+	}
+
+	var changes []string
+	switch *typ {
+	case "json":
+		changes, err = diffJSON(format, before, after, opts)
+	case "yaml":
+		changes, err = diff.YAMLF(format, before, after, opts...)
+	case "toml":
+		changes, err = diff.TOMLF(format, before, after, opts...)
+	default:
+		return fail(fmt.Errorf("unknown file type %q, use -type", *typ))
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, c := range changes {
+		fmt.Fprintln(stdout, c)
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func fileType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return ""
+}
+
+/*
+diffJSON decodes before and after and diffs them. Numbers are
+kept as they were written so that large integers aren't
+rounded.
+*/
+func diffJSON(format diff.Format, before, after []byte, opts []diff.Option) ([]string, error) {
+
+	decode := func(data []byte) (interface{}, error) {
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		err := d.Decode(&v)
+		return v, err
+	}
+
+	v1, err := decode(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %v", err)
+	}
+	v2, err := decode(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %v", err)
+	}
+
+	return diff.ObjectsF(format, v1, v2, opts...)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "godiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.json": `{"name": "a", "port": 80, "id": 9007199254740993}`,
+		"b.json": `{"name": "b", "port": 80, "id": 9007199254740993}`,
+		"a.yaml": "server:\n  port: 80\n",
+		"b.yaml": "server:\n  port: 443\n",
+		"a.toml": "[server]\nport = 80\n",
+		"b.txt":  "[server]\nport = 8080\n",
+		"bad":    "{",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		args   []string
+		stdout string
+		status int
+	}{
+		{
+			[]string{"a.json", "a.json"},
+			"",
+			0,
+		},
+		{
+			[]string{"a.json", "b.json"},
+			`["name"] changed from "a" to "b"` + "\n",
+			1,
+		},
+		{
+			[]string{"-path-style", "json-pointer", "-change", "{{.Name}}: {{.After}}", "a.yaml", "b.yaml"},
+			"/server/port: 443\n",
+			1,
+		},
+		{
+			[]string{"-locale", "de", "a.toml", "b.txt"},
+			`["server"]["port"] geändert von 80 zu 8080` + "\n",
+			1,
+		},
+		{
+			[]string{"a.json", "bad"},
+			"",
+			2,
+		},
+		{
+			[]string{"-path-style", "xml", "a.json", "b.json"},
+			"",
+			2,
+		},
+		{
+			[]string{"bad", "bad"},
+			"",
+			2,
+		},
+		{
+			[]string{"a.json"},
+			"",
+			2,
+		},
+	}
+
+	for i, c := range cases {
+
+		args := make([]string, len(c.args))
+		for j, a := range c.args {
+			if _, ok := files[a]; ok {
+				a = filepath.Join(dir, a)
+			}
+			args[j] = a
+		}
+
+		var stdout, stderr bytes.Buffer
+		status := run(args, &stdout, &stderr)
+
+		if stdout.String() != c.stdout || status != c.status {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"godiff %s\n"+
+					"    return %q, %d\n"+
+					"    wanted %q, %d\n"+
+					"    stderr %s",
+				strings.Join(c.args, " "), stdout.String(), status, c.stdout, c.status, stderr.String())
+		}
+	}
+}
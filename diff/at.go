@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/*
+At works the same as Objects but diffs only what lies at
+path within before and after, such as ".Spec.Containers",
+rather than the whole of them. The leading dot of a path
+beginning with a field name may be left out. Struct fields,
+including unexported ones, slice and array elements, and map
+entries are looked up as ParsePath reads them, following
+pointers and interfaces along the way.
+
+The differences are named by their full paths, as Objects
+would name them, so that options such as WithPathFormat see
+the same paths, unless WithRelativePaths is used. If what
+lies at path is absent from before or after, such as a map
+entry that doesn't exist or an element beyond the end of a
+slice, the whole of the other side is reported as added or
+deleted. An error is returned if path is invalid or names a
+field the objects' types don't have.
+*/
+func At(path string, before, after interface{}, opts ...Option) (changes []string, err error) {
+	return at(Format{}, path, before, after, opts)
+}
+
+/*
+AtF works the same as At with an additional parameter
+allowing for custom formatting, as for ObjectsF.
+*/
+func AtF(format Format, path string, before, after interface{}, opts ...Option) (changes []string, err error) {
+	return at(format, path, before, after, opts)
+}
+
+func at(format Format, path string, before, after interface{}, opts []Option) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return nil, err
+	}
+
+	if path != "" && path[0] != '.' && path[0] != '[' {
+		path = "." + path
+	}
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	v1, err := lookup(reflect.ValueOf(before), p)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := lookup(reflect.ValueOf(after), p)
+	if err != nil {
+		return nil, err
+	}
+	if v1 == nil && v2 == nil {
+		return nil, nil
+	}
+
+	d := differ{opts: o, templates: t}
+	if !o.relativePaths {
+		d.path = p
+	}
+	err = d.run(v1, v2)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.errs) > 0 {
+		return d.changes, errors.Join(d.errs...)
+	}
+
+	return d.changes, nil
+}
+
+/*
+lookup returns the value at path within v, or nil if it is
+absent, such as when a map has no entry for a key or a
+pointer on the way to it is nil.
+*/
+func lookup(v reflect.Value, path Path) (*reflect.Value, error) {
+
+	for i, s := range path {
+
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+
+		var err error
+		var ok bool
+		if v, ok, err = step(v, s); err != nil {
+			return nil, &PathError{Path: path[:i+1].String(), Err: err}
+		}
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	return &v, nil
+}
+
+/*
+step returns the value s refers to within v, and false if it
+is absent. An error is returned if s can't refer to anything
+within a value of v's type.
+*/
+func step(v reflect.Value, s Segment) (reflect.Value, bool, error) {
+
+	switch {
+	case s.Kind == FieldSegment && v.Kind() == reflect.Struct:
+		sf, ok := v.Type().FieldByName(s.Name)
+		if !ok {
+			return reflect.Value{}, false, fmt.Errorf("%s has no field %s", v.Type(), s.Name)
+		}
+		if sf.IsExported() {
+			f, err := v.FieldByIndexErr(sf.Index)
+			return f, err == nil, nil
+		}
+		// Unexported fields are read as the differ reads them,
+		// through an addressable copy of the struct if need be.
+		if !v.CanAddr() {
+			c := reflect.New(v.Type()).Elem()
+			c.Set(v)
+			v = c
+		}
+		f, err := v.FieldByIndexErr(sf.Index)
+		if err != nil {
+			return reflect.Value{}, false, nil
+		}
+		return *field(f), true, nil
+
+	case s.Kind == IndexSegment && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		if s.Index >= v.Len() {
+			return reflect.Value{}, false, nil
+		}
+		return v.Index(s.Index), true, nil
+
+	case s.Kind != FieldSegment && v.Kind() == reflect.Map:
+		k := s.Key
+		if s.Kind == IndexSegment {
+			k = s.Index
+		}
+		kv := reflect.ValueOf(k)
+		kt := v.Type().Key()
+		switch {
+		case !kv.IsValid():
+			kv = reflect.Zero(kt)
+		case kt.Kind() == reflect.Interface && kv.Type().Implements(kt):
+		case kv.Type().ConvertibleTo(kt) && kv.Kind() == kt.Kind():
+			kv = kv.Convert(kt)
+		case kv.CanConvert(kt) && isBare(k) && isBare(reflect.Zero(kt).Interface()):
+			kv = kv.Convert(kt)
+		default:
+			return reflect.Value{}, false, fmt.Errorf("%v can't be a key of %s", k, v.Type())
+		}
+		e := v.MapIndex(kv)
+		return e, e.IsValid(), nil
+	}
+
+	return reflect.Value{}, false, fmt.Errorf("%s can't be used with %s", s, v.Type())
+}
@@ -0,0 +1,196 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+
+	type container struct {
+		Name  string
+		Image string
+	}
+	type spec struct {
+		Replicas   int
+		Containers []container
+		Labels     map[string]string
+		Ports      map[int64]string
+	}
+	type deployment struct {
+		Name string
+		Spec *spec
+	}
+
+	before := deployment{"api", &spec{
+		Replicas:   1,
+		Containers: []container{{"app", "app:1"}},
+		Labels:     map[string]string{"a.b": "x"},
+		Ports:      map[int64]string{80: "http"},
+	}}
+	after := deployment{"web", &spec{
+		Replicas:   2,
+		Containers: []container{{"app", "app:2"}, {"log", "log:1"}},
+		Labels:     map[string]string{"a.b": "y"},
+		Ports:      map[int64]string{},
+	}}
+
+	cases := []struct {
+		path string
+		opts []Option
+		want []string
+	}{
+		{
+			".Spec.Containers",
+			nil,
+			[]string{
+				`.Spec.Containers[0].Image changed from "app:1" to "app:2"`,
+				`.Spec.Containers[1].Name added "log"`,
+				`.Spec.Containers[1].Image added "log:1"`,
+			},
+		},
+		{
+			"Spec.Containers[0]",
+			[]Option{WithRelativePaths()},
+			[]string{
+				`.Image changed from "app:1" to "app:2"`,
+			},
+		},
+		{
+			`.Spec.Labels["a.b"]`,
+			nil,
+			[]string{
+				`.Spec.Labels["a.b"] changed from "x" to "y"`,
+			},
+		},
+		{
+			".Spec.Containers[1]",
+			nil,
+			[]string{
+				`.Spec.Containers[1].Name added "log"`,
+				`.Spec.Containers[1].Image added "log:1"`,
+			},
+		},
+		{
+			".Spec.Ports[80]",
+			nil,
+			[]string{
+				`.Spec.Ports[80] deleted "http"`,
+			},
+		},
+		{
+			".Spec.Containers[5]",
+			nil,
+			nil,
+		},
+		{
+			".Spec.Containers",
+			[]Option{WithPathFormat(".Spec.Containers[*].Name", Format{Add: "{{.Name}} is new"})},
+			[]string{
+				`.Spec.Containers[0].Image changed from "app:1" to "app:2"`,
+				`.Spec.Containers[1].Name is new`,
+				`.Spec.Containers[1].Image added "log:1"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := At(c.path, before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"At(%q, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.path, before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestAtErrors(t *testing.T) {
+
+	type inner struct {
+		Port   int
+		secret string
+	}
+	type outer struct {
+		Inner inner
+		Tags  map[string]int
+	}
+
+	cases := []string{
+		".Missing",
+		".Inner.Port.X",
+		".Tags[1]",
+		".Inner[",
+	}
+
+	for i, path := range cases {
+		got, err := At(path, outer{}, outer{})
+		if err == nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"At(%q, ...)\n"+
+					"    return %q, %v\n"+
+					"    wanted nil, error",
+				path, got, err)
+		}
+	}
+}
+
+func TestAtDiffPaths(t *testing.T) {
+
+	type inner struct {
+		Port   int
+		secret string
+	}
+	type outer struct {
+		Inner  inner
+		hidden *inner
+		tags   map[string]int
+		List   []inner
+	}
+
+	before := outer{
+		Inner:  inner{80, "a"},
+		hidden: &inner{1, "x"},
+		tags:   map[string]int{"env": 1},
+		List:   []inner{{1, "p"}},
+	}
+	after := outer{
+		Inner:  inner{443, "b"},
+		hidden: &inner{2, "y"},
+		tags:   map[string]int{"env": 2},
+		List:   []inner{{1, "q"}},
+	}
+
+	changes, err := Objects(before, after)
+	if err != nil || len(changes) != 5 {
+		t.Fatalf("Objects(%v, %v)\n"+
+			"    return %q, %v\n"+
+			"    wanted 5 changes, nil",
+			before, after, changes, err)
+	}
+	diffs, err := Diffs(before, after)
+	if err != nil || len(diffs) != len(changes) {
+		t.Fatalf("Diffs(%v, %v)\n"+
+			"    return %v, %v\n"+
+			"    wanted %d diffs, nil",
+			before, after, diffs, err, len(changes))
+	}
+
+	// The path of every difference must resolve with At to
+	// that same difference.
+	for i, d := range diffs {
+		got, err := At(d.Path.String(), before, after)
+		want := []string{changes[i]}
+		if !equal(got, want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"At(%q, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				d.Path, before, after, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Package audit records the differences made to an entity
+along with who made them, when, and why, as a Record that
+can be serialised and written to one or more Sinks.
+
+	logger := audit.NewLogger(audit.JSONSink(os.Stdout))
+	err := logger.Log(ctx, audit.Record{
+		Entity: "user/42",
+		Actor:  "ann@example.com",
+		Reason: "support ticket 1234",
+	}, before, after)
+*/
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jakebowkett/go-diff/diff"
+)
+
+/*
+Record describes the changes made to an entity. Its fields
+other than Changes are supplied by the caller and are not
+interpreted by this package.
+*/
+type Record struct {
+	Entity  string    `json:"entity"`
+	Actor   string    `json:"actor"`
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason,omitempty"`
+	Changes []Change  `json:"changes"`
+}
+
+/*
+Change is a single difference within a Record. Kind is the
+name of its diff.Kind, such as "change" or "add", and Path is
+the diff.Diff's Name. Before and After are rendered as they
+would be by diff's templates and are empty if the value
+didn't exist. To is only set for differences of kind "move".
+*/
+type Change struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	To     *int   `json:"to,omitempty"`
+}
+
+/*
+New diffs before and after with diff.Diffs and opts and
+returns r with its Changes set to the differences found. If
+r.Time is zero it is set to the current time.
+*/
+func New(r Record, before, after interface{}, opts ...diff.Option) (Record, error) {
+
+	diffs, err := diff.Diffs(before, after, opts...)
+	if err != nil {
+		return Record{}, err
+	}
+
+	r.Changes = make([]Change, len(diffs))
+	for i, d := range diffs {
+		c := Change{
+			Kind:   d.Kind.String(),
+			Path:   d.Name,
+			Before: fmt.Sprint(d.Before),
+			After:  fmt.Sprint(d.After),
+		}
+		if d.Kind == diff.Move {
+			to := d.To
+			c.To = &to
+		}
+		r.Changes[i] = c
+	}
+
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	return r, nil
+}
+
+/*
+Sink is a destination for Records, such as a log file or a
+database table. Write may be called concurrently.
+*/
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+/*
+SinkFunc adapts a function to a Sink.
+*/
+type SinkFunc func(ctx context.Context, r Record) error
+
+func (f SinkFunc) Write(ctx context.Context, r Record) error {
+	return f(ctx, r)
+}
+
+/*
+JSONSink returns a Sink writing each Record to w as a single
+line of JSON.
+*/
+func JSONSink(w io.Writer) Sink {
+	s := &jsonSink{}
+	s.enc = json.NewEncoder(w)
+	return s
+}
+
+type jsonSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (s *jsonSink) Write(ctx context.Context, r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+/*
+Logger creates Records and writes them to its Sinks.
+*/
+type Logger struct {
+	sinks []Sink
+}
+
+/*
+NewLogger returns a Logger writing to sinks.
+*/
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+/*
+Log creates a Record from r as New does and writes it to
+every Sink of l, returning the errors of those that failed
+joined with errors.Join. A failing Sink doesn't stop the
+Record from being written to the others. Nothing is written
+if there are no differences between before and after.
+*/
+func (l *Logger) Log(ctx context.Context, r Record, before, after interface{}, opts ...diff.Option) error {
+
+	r, err := New(r, before, after, opts...)
+	if err != nil {
+		return err
+	}
+	if len(r.Changes) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, s := range l.sinks {
+		if err := s.Write(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
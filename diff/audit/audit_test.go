@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jakebowkett/go-diff/diff"
+)
+
+type user struct {
+	Name     string
+	Roles    []string
+	Password string `diff:"redact"`
+}
+
+func TestNew(t *testing.T) {
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	two := 2
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []diff.Option
+		want   []Change
+	}{
+		{
+			user{"ann", []string{"dev"}, "a"},
+			user{"anne", []string{"dev", "ops"}, "b"},
+			nil,
+			[]Change{
+				{Kind: "change", Path: ".Name", Before: `"ann"`, After: `"anne"`},
+				{Kind: "add", Path: ".Roles[1]", After: `"ops"`},
+				{Kind: "change", Path: ".Password", Before: "[REDACTED]", After: "[REDACTED]"},
+			},
+		},
+		{
+			[]int{1, 2, 3},
+			[]int{2, 3, 1},
+			[]diff.Option{diff.WithMoves()},
+			[]Change{
+				{Kind: "move", Path: "[0]", Before: "1", After: "1", To: &two},
+			},
+		},
+		{
+			user{},
+			user{},
+			nil,
+			[]Change{},
+		},
+	}
+
+	for i, c := range cases {
+		in := Record{Entity: "user/42", Actor: "root", Time: at}
+		got, err := New(in, c.before, c.after, c.opts...)
+		want := in
+		want.Changes = c.want
+		if !reflect.DeepEqual(got, want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"New(%v, %v, %v)\n"+
+					"    return %+v, %v\n"+
+					"    wanted %+v, nil",
+				in, c.before, c.after, got, err, want)
+		}
+	}
+}
+
+func TestNewTime(t *testing.T) {
+
+	start := time.Now()
+	r, err := New(Record{}, user{}, user{Name: "a"})
+	if err != nil || r.Time.Before(start) {
+		t.Errorf(
+			"New(Record{}, ...)\n"+
+				"    return time %v, %v\n"+
+				"    wanted time after %v, nil",
+			r.Time, err, start)
+	}
+
+	if _, err := New(Record{}, 1, 2); err == nil {
+		t.Errorf("New(Record{}, 1, 2) returned nil error")
+	}
+}
+
+func TestLogger(t *testing.T) {
+
+	var buf bytes.Buffer
+	var records []Record
+	fail := errors.New("sink failed")
+
+	logger := NewLogger(
+		JSONSink(&buf),
+		SinkFunc(func(ctx context.Context, r Record) error {
+			records = append(records, r)
+			return nil
+		}),
+		SinkFunc(func(ctx context.Context, r Record) error {
+			return fail
+		}),
+	)
+
+	r := Record{
+		Entity: "user/42",
+		Actor:  "root",
+		Time:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Reason: "rename",
+	}
+
+	err := logger.Log(context.Background(), r, user{Name: "a"}, user{Name: "b"})
+	if !errors.Is(err, fail) {
+		t.Errorf("Log returned %v, wanted %v", err, fail)
+	}
+
+	want := `{"entity":"user/42","actor":"root","time":"2024-01-02T03:04:05Z","reason":"rename",` +
+		`"changes":[{"kind":"change","path":".Name","before":"\"a\"","after":"\"b\""}]}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf(
+			"JSONSink wrote\n"+
+				"    %s\n"+
+				"    wanted\n"+
+				"    %s",
+			got, want)
+	}
+	if len(records) != 1 {
+		t.Errorf("SinkFunc received %d records, wanted 1", len(records))
+	}
+
+	// Nothing is written without changes.
+	buf.Reset()
+	err = logger.Log(context.Background(), r, user{Name: "a"}, user{Name: "a"})
+	if err != nil || buf.Len() > 0 || len(records) != 1 {
+		t.Errorf("Log without changes returned %v and wrote %q", err, buf.String())
+	}
+}
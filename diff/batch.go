@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Pair is a before and after value to be diffed by Batch.
+*/
+type Pair struct {
+	Before interface{}
+	After  interface{}
+}
+
+/*
+Result holds what Objects would have returned for a Pair
+diffed by Batch.
+*/
+type Result struct {
+	Changes []string
+	Err     error
+}
+
+/*
+Batch diffs each of pairs as Objects would, returning a
+Result for each in the same order. The options and templates
+are prepared once and shared by every pair, so diffing many
+small pairs costs little more than the diffs themselves. The
+pairs are diffed one after another unless WithWorkers is
+used.
+
+An error in one pair, such as the pair's values being of
+different types, is given in its Result and doesn't stop
+the others being diffed. The error returned by Batch itself
+is for problems common to every pair, such as a template
+that can't be parsed, in which case no Results are returned.
+*/
+func Batch(pairs []Pair, opts ...Option) ([]Result, error) {
+	return BatchF(Format{}, pairs, opts...)
+}
+
+/*
+BatchF works the same as Batch with an additional parameter
+allowing for custom formatting, as for ObjectsF.
+*/
+func BatchF(format Format, pairs []Pair, opts ...Option) ([]Result, error) {
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(pairs))
+
+	diffPair := func(i int) {
+		var err error
+		defer observe(time.Now(), &err)
+		p := pairs[i]
+		if err = validate(p.Before, p.After); err != nil {
+			results[i].Err = err
+			return
+		}
+		results[i].Changes, err = objectsWith(o, t, p.Before, p.After)
+		results[i].Err = err
+	}
+
+	workers := minInt(o.workers, len(pairs))
+	if workers <= 1 {
+		for i := range pairs {
+			diffPair(i)
+		}
+		return results, nil
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				diffPair(i)
+			}
+		}()
+	}
+	for i := range pairs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+
+	type record struct {
+		ID    int
+		Email string
+	}
+
+	var pairs []Pair
+	var want []Result
+	for i := 0; i < 50; i++ {
+		before := record{i, "a@example.com"}
+		after := before
+		var changes []string
+		if i%3 == 0 {
+			after.Email = "b@example.com"
+			changes = []string{`.Email changed from "a@example.com" to "b@example.com"`}
+		}
+		pairs = append(pairs, Pair{before, after})
+		want = append(want, Result{Changes: changes})
+	}
+	pairs = append(pairs, Pair{record{}, []int{}})
+
+	for _, workers := range []int{0, 1, 4, 100} {
+
+		got, err := Batch(pairs, WithWorkers(workers))
+		if err != nil || len(got) != len(pairs) {
+			t.Fatalf("Batch(pairs, WithWorkers(%d)) returned %d results, %v", workers, len(got), err)
+		}
+
+		for i, w := range want {
+			if !equal(got[i].Changes, w.Changes) || got[i].Err != nil {
+				fmt.Printf("Case #%d:\n", i+1)
+				t.Errorf(
+					"Batch(pairs, WithWorkers(%d))[%d]\n"+
+						"    return %q, %v\n"+
+						"    wanted %q, nil",
+					workers, i, got[i].Changes, got[i].Err, w.Changes)
+			}
+		}
+
+		last := got[len(got)-1]
+		if last.Changes != nil || !errors.Is(last.Err, ErrKindMismatch) {
+			t.Errorf(
+				"Batch(pairs, WithWorkers(%d)) last result\n"+
+					"    return %q, %v\n"+
+					"    wanted nil, ErrKindMismatch",
+				workers, last.Changes, last.Err)
+		}
+	}
+
+	if got, err := BatchF(Format{Change: "{{"}, pairs); got != nil || err == nil {
+		t.Errorf("BatchF with an invalid template returned %d results, %v, wanted nil, error", len(got), err)
+	}
+}
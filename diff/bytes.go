@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// isBytes reports whether t is a slice of bytes.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+/*
+diffBytes compares two byte slices as leaves, reporting them
+by their summaries. See WithByteSummaries.
+*/
+func (d *differ) diffBytes(v1, v2 *reflect.Value) error {
+
+	var kind Kind
+
+	switch {
+	case v1 == nil:
+		kind = Add
+	case v2 == nil:
+		kind = Delete
+	case !bytes.Equal(v1.Bytes(), v2.Bytes()):
+		kind = Change
+	case d.opts.unchanged:
+		kind = Same
+	default:
+		return nil
+	}
+
+	return d.report(Diff{}, kind, byteSummary(v1), byteSummary(v2))
+}
+
+// byteSummary returns the summary of the bytes held by v.
+func byteSummary(v *reflect.Value) *reflect.Value {
+
+	if v == nil {
+		return nil
+	}
+
+	b := v.Bytes()
+	r := reflect.ValueOf(summary(int64(len(b)), sha256.Sum256(b)))
+	return &r
+}
+
+// summary renders the size and hash of some data.
+func summary(size int64, sum [sha256.Size]byte) verbatim {
+	s := fmt.Sprintf("<%s, sha256 %s…>", byteSize(size), hex.EncodeToString(sum[:4]))
+	return verbatim(s)
+}
+
+// byteSize renders n bytes in binary units.
+func byteSize(n int64) string {
+
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	size := float64(n)
+	unit := ""
+	for _, u := range []string{"KiB", "MiB", "GiB", "TiB"} {
+		size /= 1024
+		unit = u
+		if size < 1024 {
+			break
+		}
+	}
+
+	return fmt.Sprintf("%.1f %s", size, unit)
+}
@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestWithByteSummaries(t *testing.T) {
+
+	type file struct {
+		Name string
+		Data []byte
+		Raw  json.RawMessage
+	}
+
+	big := bytes.Repeat([]byte{1}, 1300)
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []string
+	}{
+		{
+			file{"a", []byte("abc"), nil},
+			file{"a", big, json.RawMessage(`{}`)},
+			[]Option{WithByteSummaries()},
+			[]string{
+				`.Data changed from <3 B, sha256 ba7816bf…> to <1.3 KiB, sha256 3f0dcf74…>`,
+				`.Raw changed from <0 B, sha256 e3b0c442…> to <2 B, sha256 44136fa3…>`,
+			},
+		},
+		{
+			file{"a", []byte("ab"), nil},
+			file{"a", []byte("ac"), nil},
+			nil,
+			[]string{
+				`.Data[1] changed from 98 to 99`,
+			},
+		},
+		{
+			map[string][]byte{"a": []byte("abc"), "b": []byte("x")},
+			map[string][]byte{"a": []byte("abc"), "c": []byte("x")},
+			[]Option{WithByteSummaries(), WithUnchanged()},
+			[]string{
+				`["a"] unchanged <3 B, sha256 ba7816bf…>`,
+				`["b"] deleted <1 B, sha256 2d711642…>`,
+				`["c"] added <1 B, sha256 2d711642…>`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestByteSize(t *testing.T) {
+
+	cases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for i, c := range cases {
+		if got := byteSize(c.n); got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"byteSize(%d)\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.n, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+maxCachedTemplates limits the number of distinct Formats
+whose parsed templates are cached, so that programs building
+Formats dynamically don't grow the cache without bound.
+*/
+const maxCachedTemplates = 256
+
+var templateCache = struct {
+	sync.RWMutex
+	sets map[string]templateSet
+}{
+	sets: make(map[string]templateSet),
+}
+
+/*
+cachedTemplates returns the parsed templates of format, which
+has been filled in, parsing them only if the same templates
+haven't been parsed before. Templates may be executed
+concurrently so the cached sets are shared by every diff.
+Formats with Funcs aren't cached, as functions can't be told
+apart by their names.
+*/
+func cachedTemplates(format Format, ds delims, html bool) (templateSet, error) {
+
+	if format.Funcs != nil {
+		return parseTemplateSet(format, ds, html)
+	}
+
+	key := templateKey(format, ds, html)
+
+	templateCache.RLock()
+	t, ok := templateCache.sets[key]
+	templateCache.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	t, err := parseTemplateSet(format, ds, html)
+	if err != nil {
+		return nil, err
+	}
+
+	templateCache.Lock()
+	if len(templateCache.sets) < maxCachedTemplates {
+		templateCache.sets[key] = t
+	}
+	templateCache.Unlock()
+
+	return t, nil
+}
+
+func parseTemplateSet(format Format, ds delims, html bool) (templateSet, error) {
+	if html {
+		return parseHTMLTemplates(format, ds)
+	}
+	t, err := parseTemplates(format, ds)
+	if err != nil {
+		return nil, err
+	}
+	return textTemplates{t}, nil
+}
+
+// templateKey identifies the templates of format, written
+// with the delimiters ds, for use as a key in templateCache.
+func templateKey(format Format, ds delims, html bool) string {
+
+	var sb strings.Builder
+	if html {
+		sb.WriteString("html")
+	}
+	for _, nt := range namedTemplates(format) {
+		d := ds[nt.name]
+		for _, s := range []string{nt.text, d[0], d[1]} {
+			sb.WriteString(strconv.Itoa(len(s)))
+			sb.WriteByte(':')
+			sb.WriteString(s)
+		}
+	}
+
+	return sb.String()
+}
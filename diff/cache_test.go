@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+func TestCachedTemplates(t *testing.T) {
+
+	o := newOptions(nil)
+
+	t1, err1 := parseFormat(Format{}, o)
+	t2, err2 := parseFormat(Format{}, o)
+	if err1 != nil || err2 != nil || t1.templateSet != t2.templateSet {
+		t.Errorf("parseFormat(Format{}) parsed the default templates twice")
+	}
+
+	html := newOptions([]Option{WithHTMLEscaping()})
+	t3, err := parseFormat(Format{}, html)
+	if err != nil || t3.templateSet == t1.templateSet {
+		t.Errorf("parseFormat(Format{}, WithHTMLEscaping()) shared text templates")
+	}
+
+	// Delimiters change how the same text is parsed.
+	f := Format{Change: "[[.Name]]"}
+	t4, _ := parseFormat(f, o)
+	f.LeftDelim, f.RightDelim = "[[", "]]"
+	t5, _ := parseFormat(f, o)
+	if t4.templateSet == t5.templateSet {
+		t.Errorf("parseFormat(%v) shared templates with other delimiters", f)
+	}
+
+	funcs := Format{Change: "{{f}}", Funcs: template.FuncMap{"f": func() string { return "" }}}
+	t6, _ := parseFormat(funcs, o)
+	t7, _ := parseFormat(funcs, o)
+	if t6.templateSet == t7.templateSet {
+		t.Errorf("parseFormat(%v) cached templates with Funcs", funcs)
+	}
+}
+
+func TestCachedTemplatesConcurrent(t *testing.T) {
+
+	before := config{true, "0.0.0", 30}
+	after := config{false, "0.0.1", 15}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			format := Format{Change: fmt.Sprintf("%d {{.Name}}", i%4)}
+			got, err := ObjectsF(format, before, after)
+			if err != nil || len(got) != 3 || !strings.HasPrefix(got[0], fmt.Sprint(i%4)) {
+				errs <- fmt.Errorf("ObjectsF(%v) returned %q, %v", format, got, err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,198 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+Changes holds the differences found by Compare, each along
+with the text rendered for it. The zero value holds no
+differences.
+*/
+type Changes struct {
+	diffs []Diff
+	text  []string
+
+	// limit is the reason the diff was truncated, if it
+	// was. See Limits.Truncate.
+	limit string
+}
+
+/*
+Compare works the same as Objects but returns the differences
+as Changes, which keeps each rendered string together with the
+Diff it describes. Use Changes.Strings to get the same result
+as Objects.
+*/
+func Compare(before, after interface{}, opts ...Option) (Changes, error) {
+	return compare(Format{}, before, after, opts)
+}
+
+/*
+CompareF works the same as Compare with an additional
+parameter allowing for custom formatting, as for ObjectsF.
+*/
+func CompareF(format Format, before, after interface{}, opts ...Option) (Changes, error) {
+	return compare(format, before, after, opts)
+}
+
+func compare(format Format, before, after interface{}, opts []Option) (c Changes, err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return Changes{}, err
+	}
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+
+	d := differ{opts: o, templates: t, keep: true}
+	err = d.run(&v1, &v2)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	c = Changes{diffs: d.diffs, text: d.changes}
+	if d.opts.limits.Truncate {
+		c.limit = d.limit
+	}
+	if len(d.errs) > 0 {
+		return c, errors.Join(d.errs...)
+	}
+
+	return c, nil
+}
+
+// Len returns the number of differences in c.
+func (c Changes) Len() int {
+	return len(c.text)
+}
+
+/*
+Truncated reports whether the diff stopped early on exceeding
+a limit set with WithLimits and Limits.Truncate, in which case
+c holds only the differences found before it stopped.
+*/
+func (c Changes) Truncated() bool {
+	return c.limit != ""
+}
+
+/*
+Strings returns the rendered differences in c, in the same
+form as returned by Objects. If c is truncated they are
+followed by the same marker as Objects returns, which isn't
+counted by Len and has no Diff or path.
+*/
+func (c Changes) Strings() []string {
+	if len(c.text) == 0 && !c.Truncated() {
+		return nil
+	}
+	s := append([]string(nil), c.text...)
+	if c.Truncated() {
+		s = append(s, truncationMarker(c.limit))
+	}
+	return s
+}
+
+/*
+Diffs returns the differences in c as they would be returned
+by the function Diffs.
+*/
+func (c Changes) Diffs() []Diff {
+	if len(c.diffs) == 0 {
+		return nil
+	}
+	return append([]Diff(nil), c.diffs...)
+}
+
+/*
+Paths returns the name of each difference in c, such as
+".Mapping["key"][3]", in the same order as Strings. A name
+appears more than once if several differences were found at
+the same path, such as members added to and removed from a
+set.
+*/
+func (c Changes) Paths() []string {
+	if len(c.diffs) == 0 {
+		return nil
+	}
+	paths := make([]string, len(c.diffs))
+	for i, d := range c.diffs {
+		paths[i] = d.Name
+	}
+	return paths
+}
+
+/*
+Filter returns the differences in c for which keep returns
+true, in their original order. The result is truncated if c
+is. The receiver is not modified.
+*/
+func (c Changes) Filter(keep func(Diff) bool) Changes {
+
+	f := Changes{limit: c.limit}
+	for i, d := range c.diffs {
+		if keep(d) {
+			f.diffs = append(f.diffs, d)
+			f.text = append(f.text, c.text[i])
+		}
+	}
+
+	return f
+}
+
+/*
+String returns the rendered differences in c separated by
+newlines, as returned by Strings.
+*/
+func (c Changes) String() string {
+	return strings.Join(c.Strings(), "\n")
+}
+
+/*
+MarshalJSON encodes c as an array with an object for each
+difference, holding its kind, path, and rendered text along
+with its before and after values, encoded as they are by
+WriteNDJSON:
+
+	[{"kind":"change","path":".Name","before":"Ann","after":"Anne","text":".Name changed from \"Ann\" to \"Anne\""}]
+
+Values absent from one side, such as the before value of an
+addition, are omitted. Only differences are encoded, so the
+array is the same whether or not c is truncated.
+*/
+func (c Changes) MarshalJSON() ([]byte, error) {
+
+	type change struct {
+		Kind   string          `json:"kind"`
+		Path   string          `json:"path"`
+		Before json.RawMessage `json:"before,omitempty"`
+		After  json.RawMessage `json:"after,omitempty"`
+		Text   string          `json:"text"`
+	}
+
+	changes := make([]change, len(c.diffs))
+	for i, d := range c.diffs {
+		before, after := jsonValues(d)
+		changes[i] = change{
+			Kind:   d.Kind.String(),
+			Path:   d.Name,
+			Before: before,
+			After:  after,
+			Text:   c.text[i],
+		}
+	}
+
+	return json.Marshal(changes)
+}
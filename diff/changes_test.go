@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+	}{
+		{config{true, "0.0.0", 30}, config{false, "0.0.1", 30}},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"b": 3, "c": 4}},
+		{[]string{"x"}, []string{"x"}},
+	}
+
+	for i, c := range cases {
+		want, _ := Objects(c.before, c.after)
+		got, err := Compare(c.before, c.after)
+		if !equal(got.Strings(), want) || got.Len() != len(want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Compare(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got.Strings(), err, want)
+		}
+	}
+}
+
+func TestChanges(t *testing.T) {
+
+	before := config{true, "0.0.0", 30}
+	after := config{false, "0.0.1", 30}
+
+	c, err := Compare(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := c.Paths()
+	if !equal(paths, []string{".Debug", ".Version"}) {
+		t.Errorf("Changes.Paths()\n"+
+			"    return %q\n"+
+			"    wanted %q", paths, []string{".Debug", ".Version"})
+	}
+
+	f := c.Filter(func(d Diff) bool { return d.Name == ".Version" })
+	want := []string{`.Version changed from "0.0.0" to "0.0.1"`}
+	if !equal(f.Strings(), want) || len(f.Diffs()) != 1 {
+		t.Errorf("Changes.Filter(...)\n"+
+			"    return %q\n"+
+			"    wanted %q", f.Strings(), want)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Filter modified its receiver: %q", c.Strings())
+	}
+
+	s := c.String()
+	if s != strings.Join(c.Strings(), "\n") {
+		t.Errorf("Changes.String()\n"+
+			"    return %q\n"+
+			"    wanted lines %q", s, c.Strings())
+	}
+
+	b, err := json.Marshal(f)
+	wantJSON := `[{"kind":"change","path":".Version","before":"0.0.0",` +
+		`"after":"0.0.1","text":".Version changed from \"0.0.0\" to \"0.0.1\""}]`
+	if string(b) != wantJSON || err != nil {
+		t.Errorf("json.Marshal(Changes)\n"+
+			"    return %s, %v\n"+
+			"    wanted %s, nil", b, err, wantJSON)
+	}
+
+	var empty Changes
+	b, err = json.Marshal(empty)
+	if string(b) != "[]" || err != nil || empty.Strings() != nil || empty.String() != "" {
+		t.Errorf("json.Marshal(Changes{})\n"+
+			"    return %s, %v\n"+
+			"    wanted [], nil", b, err)
+	}
+}
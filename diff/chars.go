@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+charDiff returns the annotation for Diff.Chars of a change
+from v1 to v2, or an empty string if they aren't both strings
+of at most the length set with WithCharDiff.
+*/
+func (d *differ) charDiff(v1, v2 reflect.Value) string {
+
+	s1, ok1 := stringValue(v1)
+	s2, ok2 := stringValue(v2)
+	if !ok1 || !ok2 {
+		return ""
+	}
+
+	n := d.opts.charDiff
+	if utf8.RuneCountInString(s1) > n || utf8.RuneCountInString(s2) > n {
+		return ""
+	}
+
+	return annotateChars([]rune(s1), []rune(s2))
+}
+
+/*
+annotateChars renders the characters of r1 and r2 with those
+only in r1 enclosed in "[-" and "-]" and those only in r2 in
+"{+" and "+}", as in "ab[-c-]{+d+}1". The characters in common
+are those of a longest common subsequence of r1 and r2.
+*/
+func annotateChars(r1, r2 []rune) string {
+
+	// lcs[i][j] is the length of the longest common
+	// subsequence of r1[i:] and r2[j:].
+	lcs := make([][]int, len(r1)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(r2)+1)
+	}
+	for i := len(r1) - 1; i >= 0; i-- {
+		for j := len(r2) - 1; j >= 0; j-- {
+			if r1[i] == r2[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = maxInt(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	var del, ins []rune
+
+	flush := func() {
+		if len(del) > 0 {
+			b.WriteString("[-" + string(del) + "-]")
+		}
+		if len(ins) > 0 {
+			b.WriteString("{+" + string(ins) + "+}")
+		}
+		del, ins = del[:0], ins[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(r1) || j < len(r2) {
+		switch {
+		case i < len(r1) && j < len(r2) && r1[i] == r2[j]:
+			flush()
+			b.WriteRune(r1[i])
+			i++
+			j++
+		case j == len(r2) || i < len(r1) && lcs[i+1][j] >= lcs[i][j+1]:
+			del = append(del, r1[i])
+			i++
+		default:
+			ins = append(ins, r2[j])
+			j++
+		}
+	}
+	flush()
+
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAnnotateChars(t *testing.T) {
+
+	cases := []struct {
+		s1   string
+		s2   string
+		want string
+	}{
+		{"3f2a9c", "3f2b9c", "3f2[-a-]{+b+}9c"},
+		{"abc", "abc", "abc"},
+		{"", "ab", "{+ab+}"},
+		{"ab", "", "[-ab-]"},
+		{"kitten", "sitting", "[-k-]{+s+}itt[-e-]{+i+}n{+g+}"},
+		{"añb", "aüb", "a[-ñ-]{+ü+}b"},
+		{"abc", "xyz", "[-abc-]{+xyz+}"},
+	}
+
+	for i, c := range cases {
+		got := annotateChars([]rune(c.s1), []rune(c.s2))
+		if got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"annotateChars(%q, %q)\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.s1, c.s2, got, c.want)
+		}
+	}
+}
+
+func TestWithCharDiff(t *testing.T) {
+
+	type commit struct {
+		Hash    string
+		Message string
+		Author  string `diff:"redact"`
+		Count   int
+	}
+
+	before := commit{"3f2a9c", "fix the parser", "ann", 1}
+	after := commit{"3f2b9c", "fix the lexer too", "bob", 2}
+
+	cases := []struct {
+		maxLen int
+		want   []string
+	}{
+		{
+			8,
+			[]string{
+				`.Hash changed from "3f2a9c" to "3f2b9c" (3f2[-a-]{+b+}9c)`,
+				`.Message changed from "fix the parser" to "fix the lexer too"`,
+				`.Author changed from [REDACTED] to [REDACTED]`,
+				`.Count changed from 1 to 2`,
+			},
+		},
+		{
+			0,
+			[]string{
+				`.Hash changed from "3f2a9c" to "3f2b9c"`,
+				`.Message changed from "fix the parser" to "fix the lexer too"`,
+				`.Author changed from [REDACTED] to [REDACTED]`,
+				`.Count changed from 1 to 2`,
+			},
+		},
+	}
+
+	format := Format{Change: DefaultCharChange}
+
+	for i, c := range cases {
+		got, err := ObjectsF(format, before, after, WithCharDiff(c.maxLen))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v, WithCharDiff(%d))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				format, before, after, c.maxLen, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+)
+
+/*
+Style is a set of ANSI SGR parameters, such as "1;31" for
+bold red, used to color terminal output. The empty Style
+leaves text as it is. Styles may be combined with With.
+*/
+type Style string
+
+const (
+	Bold      Style = "1"
+	Faint     Style = "2"
+	Italic    Style = "3"
+	Underline Style = "4"
+
+	Black   Style = "30"
+	Red     Style = "31"
+	Green   Style = "32"
+	Yellow  Style = "33"
+	Blue    Style = "34"
+	Magenta Style = "35"
+	Cyan    Style = "36"
+	White   Style = "37"
+)
+
+/*
+Color256 returns the Style for color n of the 256 color
+palette supported by most terminals.
+*/
+func Color256(n uint8) Style {
+	return Style("38;5;" + strconv.Itoa(int(n)))
+}
+
+/*
+TrueColor returns the Style for the 24-bit color with the
+given red, green, and blue components, for terminals that
+support it.
+*/
+func TrueColor(r, g, b uint8) Style {
+	return Style(fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+}
+
+/*
+With returns a Style applying both s and t, such as
+Bold.With(Red).
+*/
+func (s Style) With(t Style) Style {
+	switch {
+	case s == "":
+		return t
+	case t == "":
+		return s
+	}
+	return s + ";" + t
+}
+
+// wrap returns text in s, restoring outer afterwards.
+func (s Style) wrap(text string, outer Style) string {
+	if s == "" {
+		return text
+	}
+	text = "\x1b[" + string(s) + "m" + text + "\x1b[0m"
+	if outer != "" {
+		text += "\x1b[" + string(outer) + "m"
+	}
+	return text
+}
+
+/*
+Theme sets the Styles with which WithColor colors the text
+rendered for a difference. Each difference is colored by the
+Style for its Kind, and within it the Name, Before, and After
+of the Diff, wherever a template prints them, are colored by
+the Style of that section in place of the Style of the kind.
+A section or kind whose Style is empty is left uncolored.
+*/
+type Theme struct {
+	Change Style
+	Add    Style
+	Delete Style
+	Move   Style
+	Same   Style
+
+	Name   Style
+	Before Style
+	After  Style
+}
+
+/*
+DefaultTheme colors changes yellow, additions green,
+deletions red, moves cyan, and unchanged values faint, with
+names in bold. It uses only the 8 basic colors so that it is
+legible with both dark and light terminal backgrounds.
+*/
+var DefaultTheme = Theme{
+	Change: Yellow,
+	Add:    Green,
+	Delete: Red,
+	Move:   Cyan,
+	Same:   Faint,
+	Name:   Bold,
+}
+
+// kind returns the Style of t for differences of kind k.
+func (t Theme) kind(k Kind) Style {
+	switch k {
+	case Change:
+		return t.Change
+	case Add:
+		return t.Add
+	case Delete:
+		return t.Delete
+	case Move:
+		return t.Move
+	case Same:
+		return t.Same
+	}
+	return ""
+}
+
+/*
+colored returns s with its Name, Before, and After wrapped
+in the Styles of their sections, for rendering with WithColor.
+Before and After become strings and so are printed as they
+would have been, but template functions given them no
+longer see their original types. Absent values are left
+empty so that templates testing for them still work.
+*/
+func (t Theme) colored(s Diff) Diff {
+
+	outer := t.kind(s.Kind)
+
+	s.Name = t.Name.wrap(s.Name, outer)
+	if t.Before != "" && s.Before != "" {
+		s.Before = verbatim(t.Before.wrap(fmt.Sprint(s.Before), outer))
+	}
+	if t.After != "" && s.After != "" {
+		s.After = verbatim(t.After.wrap(fmt.Sprint(s.After), outer))
+	}
+
+	return s
+}
+
+/*
+renderColored renders s as render does, colored by the Theme
+set with WithColor.
+*/
+func (d *differ) renderColored(ts templateSet, name string, s Diff) error {
+
+	t := *d.opts.theme
+	if err := d.render(ts, name, t.colored(s)); err != nil {
+		return err
+	}
+
+	i := len(d.changes) - 1
+	d.changes[i] = t.kind(s.Kind).wrap(d.changes[i], "")
+
+	return nil
+}
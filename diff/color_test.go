@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStyle(t *testing.T) {
+
+	cases := []struct {
+		got  Style
+		want Style
+	}{
+		{Bold.With(Red), "1;31"},
+		{Style("").With(Green), "32"},
+		{Blue.With(""), "34"},
+		{Color256(208), "38;5;208"},
+		{TrueColor(255, 128, 0), "38;2;255;128;0"},
+		{Underline.With(TrueColor(1, 2, 3)), "4;38;2;1;2;3"},
+	}
+
+	for i, c := range cases {
+		if c.got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf("Style was %q, wanted %q", c.got, c.want)
+		}
+	}
+}
+
+func TestWithColor(t *testing.T) {
+
+	type config struct {
+		Name string
+		Port int
+		Tags []string
+	}
+
+	before := config{"a", 80, nil}
+	after := config{"b", 80, []string{"x"}}
+
+	cases := []struct {
+		theme Theme
+		want  []string
+	}{
+		{
+			DefaultTheme,
+			[]string{
+				"\x1b[33m\x1b[1m.Name\x1b[0m\x1b[33m changed from \"a\" to \"b\"\x1b[0m",
+				"\x1b[32m\x1b[1m.Tags[0]\x1b[0m\x1b[32m added \"x\"\x1b[0m",
+			},
+		},
+		{
+			Theme{
+				Before: Red,
+				After:  Color256(34).With(Bold),
+			},
+			[]string{
+				".Name changed from \x1b[31m\"a\"\x1b[0m to \x1b[38;5;34;1m\"b\"\x1b[0m",
+				".Tags[0] added \x1b[38;5;34;1m\"x\"\x1b[0m",
+			},
+		},
+		{
+			Theme{},
+			[]string{
+				`.Name changed from "a" to "b"`,
+				`.Tags[0] added "x"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, WithColor(c.theme))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithColor(%v))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, c.theme, got, err, c.want)
+		}
+	}
+
+	diffs, err := Diffs(before, after, WithColor(DefaultTheme))
+	if err != nil || len(diffs) != 2 || diffs[0].Name != ".Name" {
+		t.Errorf(
+			"Diffs(%v, %v, WithColor(DefaultTheme))\n"+
+				"    return %v, %v\n"+
+				"    wanted uncolored differences",
+			before, after, diffs, err)
+	}
+}
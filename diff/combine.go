@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/*
+Combine merges the results of successive calls to Diffs on
+the same object, such as from A to B and then B to C, into
+what a single diff from the first state to the last would
+have reported. For each location the earliest Before and the
+latest After are kept, along with their types and raw
+values, so a value changed twice becomes one change and one
+added and then deleted disappears, as does one that was
+changed and then changed back. Whether a change sets or
+clears a value is worked out anew from the two.
+
+The combined differences are in the order their locations
+first appear. Members of a set, and flags, are combined one
+by one, so that a member added and then removed disappears
+while others added at the same path are kept. Differences of
+kind Move can't be combined meaningfully with others and are
+carried over as they are.
+*/
+func Combine(diffs ...[]Diff) []Diff {
+
+	type span struct {
+		first Diff
+		last  Diff
+		same  bool // Whether every difference was of kind Same.
+		move  bool
+	}
+
+	var spans []*span
+	byPath := map[string]*span{}
+
+	for _, ds := range diffs {
+		for _, d := range ds {
+
+			if d.Kind == Move {
+				spans = append(spans, &span{first: d, last: d, move: true})
+				continue
+			}
+
+			key := d.Path.String()
+			if key == "" {
+				key = d.Name
+			}
+			// The members of a set or flags share its path,
+			// so each is followed on its own.
+			if d.member {
+				member := d.BeforeValue
+				if d.Kind == Add {
+					member = d.AfterValue
+				}
+				key += "\x00" + fmt.Sprintf("%T %#v", member, member)
+			}
+
+			s, ok := byPath[key]
+			if !ok {
+				s = &span{first: d, same: true}
+				byPath[key] = s
+				spans = append(spans, s)
+			}
+			s.last = d
+			s.same = s.same && d.Kind == Same
+		}
+	}
+
+	var combined []Diff
+	for _, s := range spans {
+
+		d := s.last
+		if s.move {
+			combined = append(combined, d)
+			continue
+		}
+
+		d.Before = s.first.Before
+		d.BeforeType = s.first.BeforeType
+		d.BeforeValue = s.first.BeforeValue
+		existed := s.first.Kind != Add
+		exists := d.Kind != Delete
+
+		switch {
+		case existed && exists && reflect.DeepEqual(d.Before, d.After):
+			if !s.same {
+				continue
+			}
+			d.Kind = Same
+		case existed && exists:
+			d.Kind = Change
+		case exists:
+			d.Kind = Add
+		case existed:
+			d.Kind = Delete
+		default:
+			continue
+		}
+
+		d.set, d.cleared = false, false
+		if d.Kind == Change {
+			z1, z2 := beforeZero(s.first), afterZero(s.last)
+			d.set = z1 && !z2
+			d.cleared = !z1 && z2
+		}
+
+		combined = append(combined, d)
+	}
+
+	return combined
+}
+
+/*
+beforeZero reports whether the value before d was the zero
+value of its type. A change records that itself, which holds
+even when the value is redacted.
+*/
+func beforeZero(d Diff) bool {
+	if d.Kind == Change {
+		return d.set
+	}
+	return rawZero(d.BeforeValue)
+}
+
+// afterZero is the equivalent of beforeZero for the value
+// after d.
+func afterZero(d Diff) bool {
+	if d.Kind == Change {
+		return d.cleared
+	}
+	return rawZero(d.AfterValue)
+}
+
+func rawZero(i interface{}) bool {
+	v := reflect.ValueOf(i)
+	return !v.IsValid() || v.IsZero()
+}
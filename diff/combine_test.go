@@ -0,0 +1,240 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCombine(t *testing.T) {
+
+	type state struct {
+		Name string
+		Tags []string
+		Meta map[string]int
+	}
+
+	cases := []struct {
+		states []state
+		want   []string
+	}{
+		// A change followed by another.
+		{
+			[]state{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			[]string{`.Name change "a" "c"`},
+		},
+
+		// Changed and then changed back.
+		{
+			[]state{{Name: "a"}, {Name: "b"}, {Name: "a"}},
+			nil,
+		},
+
+		// Added and then deleted.
+		{
+			[]state{{}, {Tags: []string{"x"}}, {}},
+			nil,
+		},
+
+		// Added and then changed.
+		{
+			[]state{{}, {Meta: map[string]int{"k": 1}}, {Meta: map[string]int{"k": 2}}},
+			[]string{`.Meta["k"] add  2`},
+		},
+
+		// Changed and then deleted.
+		{
+			[]state{{Tags: []string{"x"}}, {Tags: []string{"y"}}, {}},
+			[]string{`.Tags[0] delete "x" `},
+		},
+
+		// Unrelated changes are kept in order.
+		{
+			[]state{{Name: "a"}, {Name: "b"}, {Name: "b", Tags: []string{"x"}}, {Name: "c", Tags: []string{"x"}}},
+			[]string{
+				`.Name change "a" "c"`,
+				`.Tags[0] add  "x"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		var all [][]Diff
+		for j := 1; j < len(c.states); j++ {
+			diffs, err := Diffs(c.states[j-1], c.states[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, diffs)
+		}
+
+		var got []string
+		for _, d := range Combine(all...) {
+			got = append(got, fmt.Sprintf("%s %s %v %v", d.Name, d.Kind, d.Before, d.After))
+		}
+
+		if !equal(got, c.want) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Combine(%v)\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.states, got, c.want)
+		}
+	}
+}
+
+func TestCombineMembers(t *testing.T) {
+
+	type user struct {
+		Roles map[string]struct{}
+		Perms uint8
+	}
+	flags := WithFlags(map[uint8]string{1: "Read", 2: "Write", 4: "Admin"})
+
+	set := func(members ...string) map[string]struct{} {
+		m := map[string]struct{}{}
+		for _, s := range members {
+			m[s] = struct{}{}
+		}
+		return m
+	}
+
+	cases := []struct {
+		states []user
+		want   []string
+	}{
+		// Members added one after another.
+		{
+			[]user{{}, {Roles: set("ops")}, {Roles: set("ops", "dev")}},
+			[]string{
+				`.Roles add  "ops"`,
+				`.Roles add  "dev"`,
+			},
+		},
+
+		// Members added at once.
+		{
+			[]user{{}, {Roles: set("ops", "dev")}},
+			[]string{
+				`.Roles add  "dev"`,
+				`.Roles add  "ops"`,
+			},
+		},
+
+		// A member added and then removed beside one kept.
+		{
+			[]user{{Roles: set("a")}, {Roles: set("a", "b", "c")}, {Roles: set("c")}},
+			[]string{
+				`.Roles add  "c"`,
+				`.Roles delete "a" `,
+			},
+		},
+
+		// Flags set one after another.
+		{
+			[]user{{}, {Perms: 1}, {Perms: 3}, {Perms: 2}},
+			[]string{
+				`.Perms add  Write`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		var all [][]Diff
+		for j := 1; j < len(c.states); j++ {
+			diffs, err := Diffs(c.states[j-1], c.states[j], flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, diffs)
+		}
+
+		var got []string
+		for _, d := range Combine(all...) {
+			got = append(got, fmt.Sprintf("%s %s %v %v", d.Name, d.Kind, d.Before, d.After))
+		}
+
+		if !equal(got, c.want) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Combine(%v)\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.states, got, c.want)
+		}
+	}
+}
+
+func TestCombineValues(t *testing.T) {
+
+	type state struct {
+		N int
+		S string
+	}
+
+	cases := []struct {
+		states  []state
+		want    []string
+		set     bool
+		cleared bool
+	}{
+		{
+			[]state{{N: 1}, {N: 2}, {N: 3}},
+			[]string{`.N change 1 int 3 int`},
+			false,
+			false,
+		},
+		{
+			[]state{{}, {S: "x"}, {S: "y"}},
+			[]string{`.S change  string y string`},
+			true,
+			false,
+		},
+		{
+			[]state{{S: "x"}, {S: "y"}, {}},
+			[]string{`.S change x string  string`},
+			false,
+			true,
+		},
+		{
+			[]state{{N: 1}, {}, {N: 2}},
+			[]string{`.N change 1 int 2 int`},
+			false,
+			false,
+		},
+	}
+
+	for i, c := range cases {
+
+		var all [][]Diff
+		for j := 1; j < len(c.states); j++ {
+			diffs, err := Diffs(c.states[j-1], c.states[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, diffs)
+		}
+
+		combined := Combine(all...)
+
+		var got []string
+		for _, d := range combined {
+			got = append(got, fmt.Sprintf("%s %s %v %s %v %s",
+				d.Name, d.Kind, d.BeforeValue, d.BeforeType, d.AfterValue, d.AfterType))
+		}
+
+		if !equal(got, c.want) ||
+			len(combined) != 1 ||
+			combined[0].IsSet() != c.set ||
+			combined[0].IsCleared() != c.cleared {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Combine(%v)\n"+
+					"    return %q\n"+
+					"    wanted %q, set %v, cleared %v",
+				c.states, got, c.want, c.set, c.cleared)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package diff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+/*
+WriteCSV diffs before and after as Diffs does and writes the
+differences to w as CSV, one row per difference after a
+header row:
+
+	path,kind,before,after
+	.Name,change,"""Ann""","""Anne"""
+	.Tags[1],add,,"""b"""
+
+Before and after are rendered as they'd be printed by a
+template and are empty if the value didn't exist. Nothing is
+written if the arguments can't be diffed.
+*/
+func WriteCSV(w io.Writer, before, after interface{}, opts ...Option) error {
+
+	diffs, err := Diffs(before, after, opts...)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"path", "kind", "before", "after"}); err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		row := []string{d.Name, d.Kind.String(), fmt.Sprint(d.Before), fmt.Sprint(d.After)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
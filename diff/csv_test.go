@@ -0,0 +1,44 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+
+	type user struct {
+		Name string
+		Bio  string
+		Tags []string
+	}
+
+	before := user{"Ann", "a, b", []string{"a"}}
+	after := user{"Anne", "line\nbreak", []string{"a", "b"}}
+
+	want := "path,kind,before,after\n" +
+		".Name,change,\"\"\"Ann\"\"\",\"\"\"Anne\"\"\"\n" +
+		".Bio,change,\"\"\"a, b\"\"\",\"\"\"line\\nbreak\"\"\"\n" +
+		".Tags[1],add,,\"\"\"b\"\"\"\n"
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, before, after)
+	if got := buf.String(); got != want || err != nil {
+		t.Errorf(
+			"WriteCSV(w, %v, %v)\n"+
+				"    wrote %q, returned %v\n"+
+				"    wanted %q, nil",
+			before, after, got, err, want)
+	}
+
+	buf.Reset()
+	err = WriteCSV(&buf, 1, 2)
+	if buf.Len() != 0 || !errors.Is(err, ErrNotObject) {
+		t.Errorf(
+			"WriteCSV(w, 1, 2)\n"+
+				"    wrote %q, returned %v\n"+
+				"    wanted nothing, ErrNotObject",
+			buf.String(), err)
+	}
+}
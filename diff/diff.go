@@ -20,15 +20,25 @@ Package diff provides formatted diffs for objects.
 	changes, _ = diff.ObjectsF(format, c1, c2)
 	fmt.Println(changes[0]) // "0 --> 30 (.Timeout)"
 
+The package requires Go 1.20 or later. Attrs and LogValuer
+also require Go 1.21, and All and Stream Go 1.23.
+
 */
 package diff
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -39,28 +49,184 @@ const (
 	DefaultChange = "{{.Name}} changed from {{.Before}} to {{.After}}"
 	DefaultAdd    = "{{.Name}} added {{.After}}"
 	DefaultDelete = "{{.Name}} deleted {{.Before}}"
+	DefaultMove   = "{{.Name}} moved to [{{.To}}]"
+	DefaultSame   = "{{.Name}} unchanged {{.After}}"
+	DefaultSet    = "{{.Name}} set to {{.After}}"
+	DefaultClear  = "{{.Name}} cleared {{.Before}}"
+
+	// DefaultCharChange includes Diff.Chars when present.
+	// See WithCharDiff.
+	DefaultCharChange = "{{.Name}} changed from {{.Before}} to {{.After}}{{with .Chars}} ({{.}}){{end}}"
 )
 
 /*
 Format contains strings that will be passed to the
 standard library's text/template package along with
 a Diff.
+
+Set and Clear are optional. If given, they are used in
+place of Change for changes from the zero value of a type
+and to the zero value respectively, so that a field filled
+in for the first time reads as ".Email set to "x@y"" rather
+than a change from "". If empty, Change is used for these as
+well. DefaultSet and DefaultClear may be used for them.
+
+Funcs, if non-nil, is added to the templates' function
+map before they are parsed so that they may call helpers
+such as strings.ToUpper. It may be left nil. The templates
+may always call humanBytes, humanDuration, and humanNumber,
+as in "{{humanBytes .After}}", to make sizes, durations, and
+large numbers easier to read, unless Funcs replaces them.
+
+LeftDelim and RightDelim, if non-empty, replace "{{" and "}}"
+as the action delimiters of the templates, as they would with
+template.Delims. This is useful when the templates are stored
+somewhere that is itself processed by text/template. They
+apply only to the templates given in this Format; any that
+are left empty are substituted with defaults written with
+their own delimiters.
+
+Verb, if non-empty, is the fmt verb used to render values
+other than strings before they're passed to the templates,
+such as "%+v" to name the fields of structs, "%#v" for Go
+syntax, or "%x". Strings are still quoted. The default is to
+leave values as they are so the templates print them with
+"%v".
 */
 type Format struct {
 	Change string
 	Add    string
 	Delete string
+	Move   string
+	Same   string
+	Set    string
+	Clear  string
+	Funcs  template.FuncMap
+
+	LeftDelim  string
+	RightDelim string
+
+	Verb string
 }
 
 /*
-Exposed only for documentation purposes. These are
-the fields that will be available to the templates
-in Format.
+Diff describes a single difference. These are the fields
+that will be available to the templates in Format. Name
+is Path rendered as a string in the style chosen with
+WithPathStyle.
+
+Kind says whether the difference is a change, addition,
+deletion, move, or unchanged value. Templates should use it
+rather than testing Before or After, which may legitimately
+be empty. In a template it renders as its name, so
+
+	{{if eq .Kind.String "add"}}...{{end}}
+
+tests for an addition.
 */
 type Diff struct {
+	Kind   Kind
 	Name   string
+	Path   Path
 	Before interface{}
 	After  interface{}
+
+	// From and To are the original and new indices of
+	// an element that moved within a slice or array. They
+	// are only set for differences of kind Move, in which
+	// case Path is the element's original location and
+	// Before and After are both its value.
+	From int
+	To   int
+
+	// Chars marks the characters that differ between the
+	// strings Before and After of a change when using
+	// WithCharDiff, as in "ab[-c-]{+d+}1". It is empty
+	// otherwise.
+	Chars string
+
+	// Distance is the Levenshtein distance between the
+	// strings Before and After of a change when using
+	// WithEditDistance. It is 0 otherwise.
+	Distance int
+
+	// BeforeType and AfterType are the types of Before and
+	// After as printed by %T, such as "int" or "[]string".
+	// For a value held in an interface they are its dynamic
+	// type. They are empty if the value didn't exist or was a
+	// nil interface.
+	BeforeType string
+	AfterType  string
+
+	// BeforeValue and AfterValue are Before and After as
+	// they were found, before any formatting, so strings
+	// aren't quoted and numbers may be formatted as the
+	// template sees fit. They are nil if the
+	// value didn't exist or is an SQL NULL, and hold the same
+	// placeholder as Before and After if the value is
+	// redacted.
+	BeforeValue interface{}
+	AfterValue  interface{}
+
+	set     bool
+	cleared bool
+
+	// member is true for the addition or removal of a
+	// member of a set or a flag, which share the path of
+	// the set or flags with the other members.
+	member bool
+}
+
+/*
+IsSet reports whether d is a change from the zero value of
+a type, such as "" or 0, to another value.
+*/
+func (d Diff) IsSet() bool {
+	return d.set
+}
+
+/*
+IsCleared reports whether d is a change from a value to the
+zero value of its type.
+*/
+func (d Diff) IsCleared() bool {
+	return d.cleared
+}
+
+/*
+Kind identifies whether a difference is a change to an
+existing value, the addition of a new one, or the deletion
+of an old one.
+*/
+type Kind int
+
+const (
+	Change Kind = iota + 1
+	Add
+	Delete
+	Move // Only reported when using WithMoves.
+	Same // Only reported when using WithUnchanged.
+)
+
+/*
+String returns the name of the kind, which is also the
+name of its template: "change", "add", "delete", "move", or
+"same".
+*/
+func (k Kind) String() string {
+	switch k {
+	case Change:
+		return "change"
+	case Add:
+		return "add"
+	case Delete:
+		return "delete"
+	case Move:
+		return "move"
+	case Same:
+		return "same"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
 }
 
 /*
@@ -75,13 +241,57 @@ map, slice, or array) and they must be of the same kind. Anonymous
 data structures are permitted but named types must have matching
 names. Failure to ensure these things will cause Objects to return
 an error.
+
+Any opts supplied are applied to the diff. See Option.
+
+Differences are always returned in the same order for the
+same arguments. Objects are traversed depth first: struct
+fields in declaration order, slice and array elements in
+index order, and map entries in order of their keys, which
+are sorted numerically, lexically, or field by field as
+appropriate. Everything beneath a field, element, or entry
+precedes the next one. With WithMoves an element's move is
+reported immediately before whatever replaced it.
+
+Struct fields tagged with `diff:"redact"` are still diffed,
+but their values, and those of anything they contain, are
+rendered as "[REDACTED]". See WithRedactPlaceholder. This
+also holds for structs rendered whole, such as those held in
+an interface whose dynamic type changes or those of a type
+given to WithLeafTypes. Their redacted fields are shown
+holding the placeholder, or the whole struct is replaced by
+it if a redacted field can't hold a string.
+
+Structs generated by protoc-gen-go are diffed by their
+message fields alone, skipping the bookkeeping fields that
+would otherwise show spurious changes, and pointers to nested
+messages are followed. See WithProtoFieldNumbers.
+
+The Null types of database/sql, such as sql.NullString, are
+compared as single values which are rendered as NULL when
+they aren't Valid. Other values implementing driver.Valuer,
+including through a pointer receiver, are compared as the
+values their Value method produces, with nil rendered as NULL
+and []byte holding valid UTF-8 rendered as a string. An error
+returned by Value is returned as a *PathError.
+
+Maps whose values are empty structs, such as
+map[string]struct{}, are treated as sets. A member added to
+or removed from one is reported at the path of the set with
+the member as its value, as in `.Roles added "ops"`.
+*/
+func Objects(before, after interface{}, opts ...Option) (changes []string, err error) {
+	return objects(Format{}, before, after, opts)
+}
+
+/*
+ObjectsT works the same as Objects but requires before and
+after to be of the same type at compile time rather than
+checking that they are when called. T must still be a data
+structure as described for Objects.
 */
-func Objects(before, after interface{}) (changes []string, err error) {
-	return objects(Format{
-		Change: DefaultChange,
-		Add:    DefaultAdd,
-		Delete: DefaultDelete,
-	}, before, after)
+func ObjectsT[T any](before, after T, opts ...Option) (changes []string, err error) {
+	return objects(Format{}, before, after, opts)
 }
 
 /*
@@ -89,71 +299,474 @@ ObjectsF works the same as Objects with an additional
 parameter allowing for custom formatting.
 
 Empty strings in format will be substituted with their
-respective defaults.
+respective defaults, or those of the locale chosen with
+WithLocale.
 
 If a template string in format attempts to render something
 other than a field in the Diff type an error will be returned.
 */
-func ObjectsF(format Format, before, after interface{}) (changes []string, err error) {
-	if format.Change == "" {
-		format.Change = DefaultChange
+func ObjectsF(format Format, before, after interface{}, opts ...Option) (changes []string, err error) {
+	return objects(format, before, after, opts)
+}
+
+/*
+MustObjects works the same as Objects but panics if an error
+occurs rather than returning it. It's intended for tests and
+comparisons of values known to be valid, such as at package
+initialisation.
+*/
+func MustObjects(before, after interface{}, opts ...Option) []string {
+	return must(objects(Format{}, before, after, opts))
+}
+
+/*
+MustObjectsF works the same as ObjectsF but panics if an
+error occurs rather than returning it. See MustObjects.
+*/
+func MustObjectsF(format Format, before, after interface{}, opts ...Option) []string {
+	return must(objects(format, before, after, opts))
+}
+
+func must(changes []string, err error) []string {
+	if err != nil {
+		panic(err)
+	}
+	return changes
+}
+
+/*
+ObjectsCtx works the same as Objects but checks ctx as it
+traverses before and after, abandoning the diff and
+returning ctx.Err() if ctx is cancelled or its deadline
+passes.
+*/
+func ObjectsCtx(ctx context.Context, before, after interface{}, opts ...Option) (changes []string, err error) {
+	opts = append([]Option{withContext(ctx)}, opts...)
+	return objects(Format{}, before, after, opts)
+}
+
+/*
+Equal reports whether before and after are the same. It
+stops at the first difference found and does no rendering,
+making it cheaper than checking the length of the slice
+returned by Objects.
+
+The same restrictions on before and after apply as for
+Objects and violating them will return an error.
+*/
+func Equal(before, after interface{}, opts ...Option) (equal bool, err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return false, err
+	}
+
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+
+	d := differ{opts: newOptions(opts), stopEarly: true}
+	err = d.run(&v1, &v2)
+	if err == errStop {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+HasChanges reports whether there are any differences between
+before and after. It is the opposite of Equal, stopping at
+the first difference found without rendering anything, and
+is false whenever an error is returned.
+*/
+func HasChanges(before, after interface{}, opts ...Option) (bool, error) {
+	equal, err := Equal(before, after, opts...)
+	if err != nil {
+		return false, err
+	}
+	return !equal, nil
+}
+
+/*
+ChangedPaths returns the names of each struct field, map
+entry, or slice/array element that was changed, added, or
+deleted between before and after. The names are the same
+as those made available to templates as Diff.Name, such as
+".Mapping["key"][3]". No templates are rendered.
+
+The same restrictions on before and after apply as for
+Objects and violating them will return an error.
+*/
+func ChangedPaths(before, after interface{}, opts ...Option) (paths []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return nil, err
+	}
+
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+
+	d := differ{opts: newOptions(opts), pathsOnly: true}
+	err = d.run(&v1, &v2)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.changes, nil
+}
+
+/*
+Diffs works the same as Objects but returns each difference
+as a Diff instead of rendering it with a template. Before and
+After are formatted as they would be for a template.
+*/
+func Diffs(before, after interface{}, opts ...Option) (diffs []Diff, err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return nil, err
 	}
-	if format.Add == "" {
-		format.Add = DefaultAdd
+
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+
+	d := differ{opts: newOptions(opts), collect: true}
+	err = d.run(&v1, &v2)
+	if err != nil {
+		return nil, err
 	}
-	if format.Delete == "" {
-		format.Delete = DefaultDelete
+
+	return d.diffs, nil
+}
+
+/*
+FromZero diffs the zero value of v's type against v, giving
+a description of every field, element, or entry that has been
+set, such as when logging the creation of an object. Fields
+left as their zero values aren't reported. The argument v must
+be a data structure as described for Objects.
+*/
+func FromZero(v interface{}, opts ...Option) (changes []string, err error) {
+
+	t := reflect.TypeOf(v)
+	if err := isObj(t, "v"); err != nil {
+		return nil, err
 	}
-	return objects(format, before, after)
+
+	return Objects(reflect.Zero(t).Interface(), v, opts...)
 }
 
-func objects(format Format, before, after interface{}) (changes []string, err error) {
+func validate(before, after interface{}) error {
 
 	t1 := reflect.TypeOf(before)
 	t2 := reflect.TypeOf(after)
 
 	if err := isObj(t1, "before"); err != nil {
-		return nil, err
+		return err
 	}
 	if err := isObj(t2, "after"); err != nil {
-		return nil, err
+		return err
 	}
 	if err := sameKind(t1, t2); err != nil {
-		return nil, err
+		return err
 	}
 	if err := sameNamedType(t1, t2); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func objects(format Format, before, after interface{}, opts []Option) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
 		return nil, err
 	}
 
+	return objectsWith(o, t, before, after)
+}
+
+// objectsWith diffs before and after, which have been
+// validated, with options and templates already prepared.
+func objectsWith(o options, t *templates, before, after interface{}) ([]string, error) {
+
 	v1 := reflect.ValueOf(before)
 	v2 := reflect.ValueOf(after)
 
-	t, err := template.New("change").Parse(format.Change)
+	d := differ{opts: o, templates: t}
+	err := d.run(&v1, &v2)
 	if err != nil {
 		return nil, err
 	}
-	t, err = t.New("add").Parse(format.Add)
-	if err != nil {
-		return nil, err
+	if len(d.errs) > 0 {
+		return d.changes, errors.Join(d.errs...)
 	}
-	t, err = t.New("delete").Parse(format.Delete)
+
+	return d.changes, nil
+}
+
+// templates holds the parsed templates of a Format along
+// with those of each format set with WithPathFormat.
+type templates struct {
+	templateSet
+	paths []templateSet
+
+	// verb and verbs are the Verb of the Format and of each
+	// path format, in the same order as paths.
+	verb  string
+	verbs []string
+}
+
+// templateSet is the parsed templates of a Format, named
+// after the Kind each renders.
+type templateSet interface {
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+	has(name string) bool
+}
+
+type textTemplates struct {
+	*template.Template
+}
+
+func (t textTemplates) has(name string) bool {
+	return t.Lookup(name) != nil
+}
+
+/*
+parseFormat fills in the empty templates of format according
+to o and parses them, naming each after the Kind it renders.
+The formats set with WithPathFormat are filled in from format
+and parsed likewise.
+*/
+func parseFormat(format Format, o options) (*templates, error) {
+
+	format, ds, err := fillFormat(format, o.locale)
 	if err != nil {
 		return nil, err
 	}
 
-	d := differ{templates: t}
-	err = d.diff(&v1, &v2)
+	parse := func(format Format, ds delims) (templateSet, error) {
+		return cachedTemplates(format, ds, o.html)
+	}
+
+	if err := checkVerb(format.Verb); err != nil {
+		return nil, err
+	}
+	t, err := parse(format, ds)
 	if err != nil {
 		return nil, err
 	}
 
-	return d.changes, nil
+	ts := &templates{templateSet: t, verb: format.Verb}
+	for _, pf := range o.pathFormats {
+		f := overlayFormat(pf.format, format)
+		if err := checkVerb(f.Verb); err != nil {
+			return nil, fmt.Errorf("format for %s: %v", pf.pattern, err)
+		}
+		t, err := parse(f, overlayDelims(pf.format, ds))
+		if err != nil {
+			return nil, fmt.Errorf("format for %s: %v", pf.pattern, err)
+		}
+		ts.paths = append(ts.paths, t)
+		ts.verbs = append(ts.verbs, f.Verb)
+	}
+
+	return ts, nil
+}
+
+/*
+checkVerb returns an error if verb is neither empty nor a
+single fmt verb, such as "%v" or "%08.3f".
+*/
+func checkVerb(verb string) error {
+
+	if verb == "" {
+		return nil
+	}
+
+	last := verb[len(verb)-1]
+	isLetter := 'a' <= last && last <= 'z' || 'A' <= last && last <= 'Z'
+	if len(verb) < 2 || verb[0] != '%' || !isLetter || strings.Count(verb, "%") != 1 {
+		return fmt.Errorf("invalid verb %q: must be a single fmt verb such as %%v", verb)
+	}
+
+	return nil
+}
+
+// delims holds the left and right delimiters of each
+// template of a Format, keyed by the name it's parsed
+// under. Templates absent from it use "{{" and "}}".
+type delims map[string][2]string
+
+// namedTemplate is a template of a Format along with the
+// name it's parsed under.
+type namedTemplate struct {
+	name string
+	text string
+}
+
+func namedTemplates(format Format) []namedTemplate {
+	return []namedTemplate{
+		{"change", format.Change},
+		{"add", format.Add},
+		{"delete", format.Delete},
+		{"move", format.Move},
+		{"same", format.Same},
+		{"set", format.Set},
+		{"clear", format.Clear},
+	}
+}
+
+/*
+delimsOf returns the delimiters of each template given in
+format, which are those set in format itself.
+*/
+func delimsOf(format Format) delims {
+	ds := delims{}
+	for _, nt := range namedTemplates(format) {
+		if nt.text != "" {
+			ds[nt.name] = [2]string{format.LeftDelim, format.RightDelim}
+		}
+	}
+	return ds
+}
+
+func parseTemplates(format Format, ds delims) (*template.Template, error) {
+
+	t := template.New("change").Funcs(builtinFuncs).Funcs(format.Funcs)
+
+	for _, nt := range namedTemplates(format) {
+
+		// Set and Clear are optional and fall back to
+		// Change when their templates are absent.
+		if nt.text == "" && (nt.name == "set" || nt.name == "clear") {
+			continue
+		}
+
+		tt := t
+		if nt.name != t.Name() {
+			tt = t.New(nt.name)
+		}
+		d := ds[nt.name]
+		if _, err := tt.Delims(d[0], d[1]).Parse(nt.text); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
 }
 
 type differ struct {
 	changes   []string
-	path      []string
-	templates *template.Template
+	diffs     []Diff
+	path      Path
+	names     []string
+	buf       bytes.Buffer
+	templates *templates
+	opts      options
+
+	// When stopEarly is true the differ returns errStop
+	// upon encountering the first difference rather
+	// than rendering it.
+	stopEarly bool
+
+	// When pathsOnly is true the differ records the path
+	// of each difference in changes instead of rendering
+	// a template.
+	pathsOnly bool
+
+	// When collect is true the differ records each
+	// difference in diffs instead of rendering a template.
+	collect bool
+
+	// When keep is true the differ records each difference
+	// in diffs as well as rendering it, so that diffs and
+	// changes correspond. See Compare.
+	keep bool
+
+	// When yield is non-nil the differ passes each
+	// difference to it instead of rendering a template,
+	// stopping if it returns false. yielding is true
+	// while it runs so that its panics aren't recovered.
+	yield    func(Diff) bool
+	yielding bool
+
+	// When reporter is non-nil the differ passes each
+	// difference to it instead of rendering a template,
+	// along with each step of its traversal. See Report.
+	reporter Reporter
+
+	// errs holds the errors encountered when using
+	// WithAggregateErrors.
+	errs []error
+
+	// redacting is true while traversing a struct field
+	// tagged with `diff:"redact"`.
+	redacting bool
+
+	// protoField is true when the values next given to diff
+	// are fields of a protobuf message. See protoElems.
+	protoField bool
+
+	// transforming holds the types whose transforms are
+	// being applied by WithTransform.
+	transforming map[reflect.Type]bool
+
+	// events is the number of events recorded with
+	// WithSpanEvents.
+	events int
+
+	// When loose is true structs of different types are
+	// diffed by matching their fields by name. See
+	// ObjectsLoose.
+	loose bool
+
+	// hashes holds the hashes used by WithHashPruning for
+	// the values at each depth of the path. See sameHash.
+	hashes []hashPair
+
+	// generated, when non-nil, diffs the fields of a pair of
+	// structs in place of reflection. See Recorder.Struct.
+	generated *generatedStruct
+
+	// nodes and bytes count the values visited and the
+	// bytes rendered so far, for WithLimits. limit is the
+	// reason for the limit exceeded, if any.
+	nodes int
+	bytes int
+	limit string
+}
+
+// errStop is used to halt traversal. It never
+// escapes the package.
+var errStop = errors.New("diff: stop")
+
+func (d *differ) pushPath(s Segment) {
+	d.truncateNames()
+	d.path = append(d.path, s)
+	if d.opts.hashPruning {
+		d.descendHashes()
+	}
+	if d.reporter != nil {
+		d.yielding = true
+		d.reporter.Enter(s)
+		d.yielding = false
+	}
 }
 
 func (d *differ) popPath() {
@@ -161,94 +774,609 @@ func (d *differ) popPath() {
 		return
 	}
 	d.path = d.path[0 : len(d.path)-1]
+	d.truncateNames()
+	if d.reporter != nil {
+		d.yielding = true
+		d.reporter.Exit()
+		d.yielding = false
+	}
+}
+
+/*
+name returns the current path rendered in the style chosen
+with WithPathStyle. In the default style the names of the
+path's prefixes are kept between calls, so that naming the
+many differences within a struct, slice, or map renders only
+the segments beneath it.
+*/
+func (d *differ) name() string {
+
+	if d.opts.pathStyle != PathDefault || d.opts.promoteFields {
+		return d.path.Format(d.opts.pathStyle)
+	}
+	if len(d.path) == 0 {
+		return ""
+	}
+
+	for n := len(d.names); n < len(d.path); n++ {
+		prefix := ""
+		if n > 0 {
+			prefix = d.names[n-1]
+		}
+		d.names = append(d.names, prefix+d.path[n].String())
+	}
+
+	return d.names[len(d.path)-1]
+}
+
+/*
+selected reports whether the current path is selected by
+WithFields, either by lying beneath one of its paths or by
+leading to one.
+*/
+func (d *differ) selected() bool {
+
+	if len(d.path) == 0 || d.path[len(d.path)-1].promoted {
+		return true
+	}
+
+	for _, p := range d.opts.fields {
+		if p.match(d.path) || p.leadsTo(d.path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// truncateNames discards the names kept by name for
+// prefixes that are no longer part of the path.
+func (d *differ) truncateNames() {
+	if len(d.names) > len(d.path) {
+		d.names = d.names[:len(d.path)]
+	}
+}
+
+/*
+rewind returns the differ to the root of the objects being
+diffed, as a diff stopped by errStop may leave it within
+them, so that it may be used for another pair of objects.
+*/
+func (d *differ) rewind() {
+	d.path = d.path[:0]
+	d.names = d.names[:0]
+	d.hashes = nil
+	d.redacting = false
+	d.protoField = false
+}
+
+// run diffs v1 and v2, recovering from any panic.
+func (d *differ) run(v1, v2 *reflect.Value) error {
+
+	if d.opts.jsonRoundTrip {
+		var err error
+		if v1, v2, err = roundTrip(v1, v2); err != nil {
+			return err
+		}
+	}
+
+	return d.truncated(d.safely(func() error {
+		return d.diff(v1, v2)
+	}))
+}
+
+/*
+safely calls fn, converting a panic into an error wrapping
+ErrPanic in a *PathError that names where it happened. The
+differ is restored to the state it was in beforehand so it
+may continue to be used.
+*/
+func (d *differ) safely(fn func() error) (err error) {
+
+	depth := len(d.path)
+	redacting := d.redacting
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if d.yielding {
+			panic(r)
+		}
+		err = &PathError{
+			Path: d.name(),
+			Err:  fmt.Errorf("%w: %v", ErrPanic, r),
+		}
+		d.path = d.path[:depth]
+		d.truncateNames()
+		d.redacting = redacting
+	}()
+
+	return fn()
+}
+
+/*
+We use pointers to reflect.Value to distinguish between
+fields/keys/indices that are zero value vs non-existent
+due to an asyemmtry in the two data structures.
+
+A nil reflect.Value pointer means "this field/key/index
+doesn't exist in this data structure." This distinction
+is required by diffAtom below.
+*/
+func (d *differ) diff(v1, v2 *reflect.Value) (err error) {
+
+	protoField := d.protoField
+	d.protoField = false
+
+	if ctx := d.opts.ctx; ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := d.visit(); err != nil {
+		return err
+	}
+
+	if d.opts.fields != nil && !d.selected() {
+		return nil
+	}
+
+	v1, v2 = elems(v1, v2)
+
+	if fn, t, ok := d.transformFor(v1, v2); ok {
+		d.transforming[t] = true
+		defer delete(d.transforming, t)
+		v1, v2 = elems(call(fn, v1), call(fn, v2))
+		d.forgetHashes()
+	}
+
+	v1, v2 = protoElems(v1, v2, protoField)
+
+	// Redacted values within JSON are known by their masks.
+	if d.opts.jsonRoundTrip && !d.redacting && (masked(v1) || masked(v2)) {
+		d.redacting = true
+		defer func() { d.redacting = false }()
+	}
+
+	if d.opts.nilAsEmpty && isEmpty(v1) && isEmpty(v2) {
+		return nil
+	}
+
+	// Unchanged values must be visited to be reported.
+	if !d.opts.unchanged {
+		if identical(v1, v2) {
+			return nil
+		}
+		if d.opts.hashPruning && d.sameHash(v1, v2) {
+			return nil
+		}
+	}
+
+	var typ reflect.Type
+	if v1 == nil {
+		typ = v2.Type()
+	} else {
+		typ = v1.Type()
+	}
+
+	if d.opts.marshalers && isMarshaler(typ) {
+		return d.diffAtom(v1, v2)
+	}
+
+	if d.opts.leafTypes[typ] {
+		return d.diffLeaf(v1, v2)
+	}
+
+	if isValuer(typ) {
+		return d.diffValuer(v1, v2)
+	}
+
+	if d.opts.opaque != OpaqueIdentity && isOpaque(typ.Kind()) {
+		return d.diffOpaque(v1, v2)
+	}
+
+	if d.opts.timeLocation != nil && typ == timeType {
+		return d.diffTime(v1, v2)
+	}
+
+	if names, ok := d.opts.flags[typ]; ok && (v1 == nil || v2 == nil || v1.Type() == v2.Type()) {
+		return d.diffFlags(v1, v2, names)
+	}
+
+	if d.opts.byteSummaries && isBytes(typ) {
+		return d.diffBytes(v1, v2)
+	}
+
+	switch typ.Kind().String() {
+	case "struct":
+		if index, ok := sqlNullValue(typ); ok {
+			return d.diffSQLNull(v1, v2, index)
+		}
+		if d.loose && v1 != nil && v2 != nil && v1.Type() != v2.Type() {
+			return d.diffStructLoose(v1, v2)
+		}
+		err = d.diffStruct(v1, v2)
+	case "map":
+		err = d.diffMap(v1, v2)
+	case "array", "slice":
+		err = d.diffSequence(v1, v2)
+	default:
+		err = d.diffAtom(v1, v2)
+	}
+
+	return err
 }
 
 /*
-We use pointers to reflect.Value to distinguish between
-fields/keys/indices that are zero value vs non-existent
-due to an asyemmtry in the two data structures.
-
-A nil reflect.Value pointer means "this field/key/index
-doesn't exist in this data structure." This distinction
-is required by diffAtom below.
+isEmpty reports whether v is a nil interface, or a slice or
+map with no elements, nil or otherwise, which may be held in
+an interface.
+*/
+func isEmpty(v *reflect.Value) bool {
+	if v == nil {
+		return false
+	}
+	e := *v
+	if e.Kind() == reflect.Interface {
+		if e.IsNil() {
+			return true
+		}
+		e = e.Elem()
+	}
+	switch e.Kind() {
+	case reflect.Slice, reflect.Map:
+		return e.Len() == 0
+	}
+	return false
+}
+
+/*
+transformFor returns the transform registered with
+WithTransform for the type of v1 and v2, along with that
+type. Transforms are not applied to values within their
+own results, as that could recurse forever.
+*/
+func (d *differ) transformFor(v1, v2 *reflect.Value) (reflect.Value, reflect.Type, bool) {
+
+	if d.opts.transforms == nil {
+		return reflect.Value{}, nil, false
+	}
+
+	var t reflect.Type
+	switch {
+	case v1 == nil:
+		t = v2.Type()
+	case v2 == nil || v1.Type() == v2.Type():
+		t = v1.Type()
+	default:
+		return reflect.Value{}, nil, false
+	}
+
+	fn, ok := d.opts.transforms[t]
+	if !ok || d.transforming[t] {
+		return reflect.Value{}, nil, false
+	}
+	if d.transforming == nil {
+		d.transforming = map[reflect.Type]bool{}
+	}
+
+	return fn, t, true
+}
+
+func call(fn reflect.Value, v *reflect.Value) *reflect.Value {
+	if v == nil {
+		return nil
+	}
+	r := fn.Call([]reflect.Value{*v})[0]
+	return &r
+}
+
+/*
+elems looks through interfaces so that the values they
+hold are diffed rather than the interfaces themselves.
+This is only done when both interfaces hold values of the
+same type, or one of them doesn't exist. Otherwise they're
+left to be compared as a whole.
+*/
+func elems(v1, v2 *reflect.Value) (*reflect.Value, *reflect.Value) {
+
+	isIface := func(v *reflect.Value) bool {
+		return v != nil && v.Kind() == reflect.Interface && !v.IsNil()
+	}
+
+	switch {
+	case v1 == nil && isIface(v2):
+		e2 := v2.Elem()
+		return nil, &e2
+	case v2 == nil && isIface(v1):
+		e1 := v1.Elem()
+		return &e1, nil
+	case isIface(v1) && isIface(v2) && v1.Elem().Type() == v2.Elem().Type():
+		e1 := v1.Elem()
+		e2 := v2.Elem()
+		return &e1, &e2
+	}
+
+	return v1, v2
+}
+
+/*
+identical reports whether v1 and v2 can cheaply be shown to
+be the same, allowing the traversal of data structures with
+no differences to be skipped. It returns false if that can't
+be determined.
+*/
+func identical(v1, v2 *reflect.Value) bool {
+
+	if v1 == nil || v2 == nil || v1.Type() != v2.Type() {
+		return false
+	}
+
+	switch v1.Kind() {
+	case reflect.Struct, reflect.Array:
+		if safelyComparable(v1.Type()) {
+			return v1.Interface() == v2.Interface()
+		}
+	case reflect.Slice:
+		return v1.Len() == v2.Len() && v1.Pointer() == v2.Pointer()
+	case reflect.Map:
+		return v1.Pointer() == v2.Pointer()
+	}
+
+	return false
+}
+
+var comparableTypes sync.Map
+
+/*
+safelyComparable reports whether values of type t can be
+compared with == without risk of panicking. Types that are
+comparable but contain interfaces are excluded since the
+interfaces may hold values that aren't.
 */
-func (d *differ) diff(v1, v2 *reflect.Value) (err error) {
+func safelyComparable(t reflect.Type) bool {
 
-	var kind string
-	if v1 == nil {
-		kind = v2.Kind().String()
-	} else {
-		kind = v1.Kind().String()
+	if ok, cached := comparableTypes.Load(t); cached {
+		return ok.(bool)
 	}
 
-	switch kind {
-	case "struct":
-		err = d.diffStruct(v1, v2)
-	case "map":
-		err = d.diffMap(v1, v2)
-	case "array", "slice":
-		err = d.diffSequence(v1, v2)
-	default:
-		err = d.diffAtom(v1, v2)
+	ok := t.Comparable()
+	if ok {
+		switch t.Kind() {
+		case reflect.Interface:
+			ok = false
+		case reflect.Array:
+			ok = safelyComparable(t.Elem())
+		case reflect.Struct:
+			for i := 0; i < t.NumField() && ok; i++ {
+				ok = safelyComparable(t.Field(i).Type)
+			}
+		}
 	}
 
-	return err
+	comparableTypes.Store(t, ok)
+	return ok
 }
 
 func (d *differ) diffStruct(v1, v2 *reflect.Value) error {
 
-	// Make the structs addressable. This makes it
-	// possible to get unexported fields later.
-	var val1 reflect.Value
-	var val2 reflect.Value
-	if v1 != nil {
-		val1 = reflect.New(v1.Type()).Elem()
-		val1.Set(*v1)
+	var fields []fieldInfo
+	if v1 == nil {
+		fields = fieldsOf(v2.Type())
+	} else {
+		fields = fieldsOf(v1.Type())
 	}
-	if v2 != nil {
-		val2 = reflect.New(v2.Type()).Elem()
-		val2.Set(*v2)
+	if d.opts.sortFields {
+		fields = sortedFields(fields)
 	}
 
-	var fields int
+	gen := d.generatedFor(v1, v2)
+
+	var typ reflect.Type
 	if v1 == nil {
-		fields = v2.NumField()
+		typ = v2.Type()
 	} else {
-		fields = v1.NumField()
+		typ = v1.Type()
 	}
+	proto := isProtoMessage(typ)
+
+	val1 := addressable(v1, fields)
+	val2 := addressable(v2, fields)
 
-	for i := 0; i < fields; i++ {
+	for _, fi := range fields {
 
-		var name string
 		var f1 *reflect.Value
 		var f2 *reflect.Value
 
 		switch {
+		case gen != nil && fi.name != "_":
 		case v1 == nil:
-			name = v2.Type().Field(i).Name
 			f1 = nil
-			f2 = field(val2.Field(i))
+			f2 = structField(val2, fi)
 		case v2 == nil:
-			name = v1.Type().Field(i).Name
-			f1 = field(val1.Field(i))
+			f1 = structField(val1, fi)
 			f2 = nil
 		default:
-			name = v1.Type().Field(i).Name
-			f1 = field(val1.Field(i))
-			f2 = field(val2.Field(i))
+			f1 = structField(val1, fi)
+			f2 = structField(val2, fi)
+		}
+
+		// Everything beneath a redacted field is redacted.
+		redacting := d.redacting
+		if fi.redact {
+			d.redacting = true
 		}
 
-		d.path = append(d.path, "."+name)
-		err := d.diff(f1, f2)
+		name := fi.name
+		if d.opts.protoNumbers && fi.number > 0 {
+			name = strconv.Itoa(fi.number)
+		}
+
+		seg := fieldSegment(name)
+		seg.promoted = d.opts.promoteFields && fi.promotes
+		d.pushPath(seg)
+		var err error
+		if gen != nil && fi.name != "_" {
+			err = gen(fi.index)
+		} else {
+			d.protoField = proto
+			err = d.diff(f1, f2)
+		}
 		if err != nil {
 			return err
 		}
 		d.popPath()
+		d.redacting = redacting
 	}
 
 	return nil
 }
 
+// fieldInfo is what the differ needs to know about a struct field.
+type fieldInfo struct {
+	name     string
+	index    int
+	redact   bool
+	number   int // Only set for protobuf messages.
+	exported bool
+
+	// promotes is true for an embedded struct whose fields
+	// are all promoted, not being shadowed by other fields.
+	promotes bool
+}
+
+var fieldCache sync.Map
+
+/*
+fieldsOf returns the fields of struct type t in declaration
+order. They're cached per type as looking them up is costly
+relative to diffing small structs. The bookkeeping fields of
+protobuf messages are left out.
+*/
+func fieldsOf(t reflect.Type) []fieldInfo {
+
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]fieldInfo)
+	}
+
+	proto := isProtoMessage(t)
+
+	fields := make([]fieldInfo, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fi := fieldInfo{
+			name:     sf.Name,
+			index:    i,
+			redact:   hasTagOption(sf, "redact"),
+			promotes: promotes(t, sf),
+			exported: sf.IsExported(),
+		}
+		if proto {
+			if isProtoInternal(sf) {
+				continue
+			}
+			fi.number = protoNumber(sf)
+		}
+		fields = append(fields, fi)
+	}
+
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+/*
+promotes reports whether sf is an embedded struct field of t
+all of whose fields, including those promoted from structs
+embedded within it, are promoted to t. Fields that are
+shadowed by a field of t, or that conflict with a field of
+another embedded struct, aren't promoted.
+*/
+func promotes(t reflect.Type, sf reflect.StructField) bool {
+
+	if !sf.Anonymous || sf.Type.Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, f := range reflect.VisibleFields(sf.Type) {
+		pf, ok := t.FieldByName(f.Name)
+		if !ok || !indexEqual(pf.Index, append([]int{sf.Index[0]}, f.Index...)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func indexEqual(a, b []int) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+hasTagOption reports whether the comma separated list in
+the "diff" struct tag of f contains option.
+*/
+func hasTagOption(f reflect.StructField, option string) bool {
+	tag, ok := f.Tag.Lookup("diff")
+	if !ok {
+		return false
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+addressable returns the struct v, copying it if it isn't
+addressable but has unexported fields, which can only be
+read through an addressable struct. Structs whose fields are
+all exported are used as they are, avoiding the copy.
+*/
+func addressable(v *reflect.Value, fields []fieldInfo) reflect.Value {
+
+	if v == nil {
+		return reflect.Value{}
+	}
+	if v.CanAddr() {
+		return *v
+	}
+
+	for _, fi := range fields {
+		if !fi.exported {
+			c := reflect.New(v.Type()).Elem()
+			c.Set(*v)
+			return c
+		}
+	}
+
+	return *v
+}
+
+// structField returns the field fi of the struct v, which
+// must be addressable if the field is unexported.
+func structField(v reflect.Value, fi fieldInfo) *reflect.Value {
+	f := v.Field(fi.index)
+	if fi.exported {
+		return &f
+	}
+	return field(f)
+}
+
 // We do this to get at unexported struct fields.
 func field(f reflect.Value) *reflect.Value {
 	f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
@@ -257,6 +1385,10 @@ func field(f reflect.Value) *reflect.Value {
 
 func (d *differ) diffSequence(v1, v2 *reflect.Value) error {
 
+	if d.opts.moves && v1 != nil && v2 != nil {
+		return d.diffSequenceMoves(v1, v2)
+	}
+
 	longest := 0
 	v1Len := 0
 	v2Len := 0
@@ -292,7 +1424,7 @@ func (d *differ) diffSequence(v1, v2 *reflect.Value) error {
 			elem2 = &e2
 		}
 
-		d.path = append(d.path, fmt.Sprintf("[%d]", i))
+		d.pushPath(indexSegment(i))
 		err := d.diff(elem1, elem2)
 		if err != nil {
 			return err
@@ -305,31 +1437,32 @@ func (d *differ) diffSequence(v1, v2 *reflect.Value) error {
 
 func (d *differ) diffMap(v1, v2 *reflect.Value) error {
 
-	m := alignMapKeys(v1, v2)
+	if (v1 != nil && isSet(v1.Type())) || (v2 != nil && isSet(v2.Type())) {
+		return d.diffSet(v1, v2)
+	}
 
-	for k, ok := range m {
+	for _, k := range alignMapKeys(v1, v2) {
 
 		var elem1 *reflect.Value
 		var elem2 *reflect.Value
 
 		switch {
-		case !ok.before:
+		case !k.before:
 			elem1 = nil
-			e2 := v2.MapIndex(reflect.ValueOf(k))
+			e2 := v2.MapIndex(k.key)
 			elem2 = &e2
-		case !ok.after:
-			e1 := v1.MapIndex(reflect.ValueOf(k))
+		case !k.after:
+			e1 := v1.MapIndex(k.key)
 			elem1 = &e1
 			elem2 = nil
 		default:
-			e1 := v1.MapIndex(reflect.ValueOf(k))
-			e2 := v2.MapIndex(reflect.ValueOf(k))
+			e1 := v1.MapIndex(k.key)
+			e2 := v2.MapIndex(k.key)
 			elem1 = &e1
 			elem2 = &e2
 		}
 
-		key := formatInterface(k)
-		d.path = append(d.path, fmt.Sprintf("[%v]", key))
+		d.pushPath(keySegment(k.key.Interface()))
 		err := d.diff(elem1, elem2)
 		if err != nil {
 			return err
@@ -340,89 +1473,501 @@ func (d *differ) diffMap(v1, v2 *reflect.Value) error {
 	return nil
 }
 
-type val struct {
+type mapKey struct {
+	key    reflect.Value
 	before bool
 	after  bool
 }
 
-func alignMapKeys(m1, m2 *reflect.Value) map[interface{}]val {
-
-	k1 := m1.MapKeys()
-	k2 := m2.MapKeys()
+/*
+alignMapKeys returns the keys of both maps, sorted as
+described by compareValues, noting which maps have them.
+*/
+func alignMapKeys(m1, m2 *reflect.Value) []mapKey {
 
-	longest := len(k1)
-	if len(k2) > longest {
-		longest = len(k2)
+	// Either map may not exist if it's nested
+	// within a map or slice.
+	var k1, k2 []reflect.Value
+	if m1 != nil {
+		k1 = m1.MapKeys()
+	}
+	if m2 != nil {
+		k2 = m2.MapKeys()
 	}
 
-	m := make(map[interface{}]val, longest)
+	before := make(map[interface{}]bool, len(k1))
+	after := make(map[interface{}]bool, len(k2))
+	keys := make([]reflect.Value, 0, len(k1)+len(k2))
 
 	for _, k := range k1 {
-		m[k.Interface()] = val{before: true}
+		before[k.Interface()] = true
+		keys = append(keys, k)
 	}
 	for _, k := range k2 {
-		v := m[k.Interface()]
-		v.after = true
-		m[k.Interface()] = v
+		after[k.Interface()] = true
+		if !before[k.Interface()] {
+			keys = append(keys, k)
+		}
+	}
+
+	sortValues(keys)
+
+	aligned := make([]mapKey, len(keys))
+	for i, k := range keys {
+		aligned[i] = mapKey{
+			key:    k,
+			before: before[k.Interface()],
+			after:  after[k.Interface()],
+		}
 	}
 
-	return m
+	return aligned
 }
 
 func (d *differ) diffAtom(v1, v2 *reflect.Value) error {
 
-	s := struct {
-		Name   string
-		Before interface{}
-		After  interface{}
-	}{
-		Name: strings.Join(d.path, ""),
-	}
-
-	var tmplName string
+	var kind Kind
 
 	switch {
 	case v1 == nil:
-		tmplName = "add"
-		s.After = formatInterface(v2.Interface())
-		s.Before = ""
+		kind = Add
 	case v2 == nil:
-		tmplName = "delete"
-		s.Before = formatInterface(v1.Interface())
-		s.After = ""
-	case v1.Interface() != v2.Interface():
-		tmplName = "change"
-		s.Before = formatInterface(v1.Interface())
-		s.After = formatInterface(v2.Interface())
+		kind = Delete
+	case !d.atomsEqual(*v1, *v2):
+		kind = Change
+	case d.opts.unchanged:
+		kind = Same
 	default:
 		return nil
 	}
 
-	return d.render(tmplName, s)
+	return d.report(Diff{}, kind, v1, v2)
+}
+
+/*
+report handles a difference of kind at the current path
+according to the differ's mode. Fields of s other than
+Name, Path, Before, After, Chars, and Distance are passed
+through as is.
+*/
+func (d *differ) report(s Diff, kind Kind, v1, v2 *reflect.Value) error {
+
+	if !d.opts.wantKind(kind) {
+		return nil
+	}
+
+	// Unchanged values aren't differences.
+	if kind == Same && (d.stopEarly || d.pathsOnly) {
+		return nil
+	}
+
+	if m := currentMetrics(); m != nil && kind != Same {
+		m.DifferenceFound(kind)
+	}
+
+	if d.stopEarly {
+		return errStop
+	}
+
+	if d.pathsOnly {
+		d.changes = append(d.changes, d.name())
+		return nil
+	}
+
+	// The path is copied as d.path is reused.
+	s.Name = d.name()
+	s.Path = append(Path(nil), d.path...)
+	s.Kind = kind
+	s.set, s.cleared = false, false
+	if kind == Change {
+		z1, z2 := isZero(*v1), isZero(*v2)
+		s.set = z1 && !z2
+		s.cleared = !z1 && z2
+	}
+	s.Before, s.BeforeType, s.BeforeValue = "", "", nil
+	s.After, s.AfterType, s.AfterValue = "", "", nil
+	verb := d.verbFor(s.Path)
+	e1, e2 := v1, v2
+	if kind == Change && d.opts.stringContext > 0 && !d.redacting {
+		x1, x2 := d.excerpts(*v1, *v2)
+		e1, e2 = &x1, &x2
+	}
+	if v1 != nil {
+		s.Before = d.formatValue(s.Name, verb, *e1)
+		s.BeforeType = typeName(*v1)
+		s.BeforeValue = d.rawValue(*v1)
+	}
+	if v2 != nil {
+		s.After = d.formatValue(s.Name, verb, *e2)
+		s.AfterType = typeName(*v2)
+		s.AfterValue = d.rawValue(*v2)
+	}
+	s.Chars = ""
+	if kind == Change && d.opts.charDiff > 0 && !d.redacting {
+		s.Chars = d.charDiff(*v1, *v2)
+	}
+	s.Distance = 0
+	if kind == Change && d.opts.editDistance && !d.redacting {
+		s.Distance, _ = distance(*v1, *v2)
+	}
+
+	if d.opts.span != nil {
+		d.addEvent(s)
+	}
+
+	if d.collect {
+		d.diffs = append(d.diffs, s)
+		return nil
+	}
+
+	if d.reporter != nil {
+		d.yielding = true
+		d.reporter.Report(kind, s)
+		d.yielding = false
+		return nil
+	}
+
+	if d.yield != nil {
+		d.yielding = true
+		more := d.yield(s)
+		d.yielding = false
+		if !more {
+			return errStop
+		}
+		return nil
+	}
+
+	t, name := d.templateFor(s)
+	var err error
+	if d.opts.theme != nil {
+		err = d.renderColored(t, name, s)
+	} else {
+		err = d.render(t, name, s)
+	}
+	if err != nil && d.opts.aggregateErrors {
+		d.errs = append(d.errs, &PathError{Path: s.Name, Err: err})
+		return nil
+	}
+	if err == nil {
+		err = d.rendered()
+	}
+	if err == nil && d.keep {
+		d.diffs = append(d.diffs, s)
+	}
+
+	return err
+}
+
+/*
+templateFor returns the name of the template s is rendered
+with, along with the set it belongs to. The template is that of
+its kind unless it was set or cleared and the Format has a
+template for that. The Format is the first of those set with
+WithPathFormat whose pattern matches the path of s, if any.
+*/
+func (d *differ) templateFor(s Diff) (templateSet, string) {
+
+	t := d.templates.templateSet
+	for i, pf := range d.opts.pathFormats {
+		if pf.pattern.match(s.Path) {
+			t = d.templates.paths[i]
+			break
+		}
+	}
+
+	name := s.Kind.String()
+	switch {
+	case s.set:
+		name = "set"
+	case s.cleared:
+		name = "clear"
+	}
+	if !t.has(name) {
+		return t, s.Kind.String()
+	}
+	return t, name
+}
+
+// verbFor returns the Verb of the Format used to render the
+// difference at path, or "" if there are no templates.
+func (d *differ) verbFor(path Path) string {
+
+	if d.templates == nil {
+		return ""
+	}
+	for i, pf := range d.opts.pathFormats {
+		if pf.pattern.match(path) {
+			return d.templates.verbs[i]
+		}
+	}
+
+	return d.templates.verb
 }
 
-func (d *differ) render(tmplName string, data interface{}) error {
-	var buf bytes.Buffer
-	err := d.templates.Lookup(tmplName).Execute(&buf, data)
+// isZero reports whether v, or the value held in it if it
+// is an interface, is the zero value of its type.
+func isZero(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.IsZero()
+}
+
+// render executes the template name of t with data. The
+// differ's buffer is reused for each change rendered.
+func (d *differ) render(t templateSet, name string, data interface{}) error {
+	d.buf.Reset()
+	err := t.ExecuteTemplate(&d.buf, name, data)
 	if err != nil {
 		return err
 	}
-	d.changes = append(d.changes, buf.String())
+	d.changes = append(d.changes, d.buf.String())
 	return nil
 }
 
+func (d *differ) atomsEqual(v1, v2 reflect.Value) bool {
+	if d.opts.nanEqual && isNaN(v1) && isNaN(v2) {
+		return true
+	}
+	if d.opts.tolerance > 0 {
+		if equal, ok := withinTolerance(v1, v2, d.opts.tolerance); ok {
+			return equal
+		}
+	}
+	if d.opts.normalizeSpace {
+		s1, ok1 := stringValue(v1)
+		s2, ok2 := stringValue(v2)
+		if ok1 && ok2 && v1.Type() == v2.Type() {
+			return normalizeSpace(s1) == normalizeSpace(s2)
+		}
+	}
+	if d.opts.minDistance > 0 && v1.Type() == v2.Type() {
+		if n, ok := distance(v1, v2); ok {
+			return n < d.opts.minDistance
+		}
+	}
+	i1 := v1.Interface()
+	i2 := v2.Interface()
+
+	// Comparing values such as funcs, or structs holding
+	// slices in interfaces, with == panics, so they're
+	// compared deeply instead. Funcs are equal only if both
+	// are nil.
+	if !comparable(i1) || !comparable(i2) {
+		return reflect.DeepEqual(i1, i2)
+	}
+
+	return i1 == i2
+}
+
+func comparable(i interface{}) bool {
+	t := reflect.TypeOf(i)
+	return t == nil || safelyComparable(t)
+}
+
+// stringValue returns the string held by v, looking
+// through an interface if needed.
+func stringValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// normalizeSpace trims s and collapses each run of
+// whitespace within it to a single space.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// formatValue prepares v, found at the path named name, for
+// use as Diff.Before or Diff.After. Redacted fields within v
+// are replaced as described by redactedCopy.
+func (d *differ) formatValue(name, verb string, v reflect.Value) interface{} {
+	if d.redacting {
+		return d.opts.redactPlaceholder()
+	}
+	v, ok := redactedCopy(v, d.opts.redactPlaceholder())
+	if !ok {
+		return d.opts.redactPlaceholder()
+	}
+	i := v.Interface()
+	if d.opts.jsonRoundTrip {
+		i = unmasked(i, d.opts.redactPlaceholder())
+	}
+	if _, ok := i.(sqlNull); ok {
+		return "NULL"
+	}
+	switch {
+	case d.opts.formatter != nil:
+		i = verbatim(d.opts.formatter(name, i))
+	case d.opts.marshalers || d.opts.stringer:
+		if s, ok := d.textOf(i); ok {
+			i = verbatim(s)
+		}
+	}
+	if _, ok := i.(string); !ok && i != nil && verb != "" {
+		if _, ok := i.(verbatim); !ok {
+			i = verbatim(fmt.Sprintf(verb, i))
+		}
+	}
+	switch {
+	case d.opts.quoted:
+		i = quotable(i)
+	case d.opts.unquoted:
+		if s, ok := i.(string); ok {
+			i = verbatim(s)
+		}
+	}
+	if n := d.opts.maxValueLength; n > 0 {
+		if s, ok := truncate(i, n); ok {
+			return s
+		}
+	}
+	if s, ok := i.(verbatim); ok {
+		return string(s)
+	}
+	return formatInterface(i)
+}
+
+// rawValue returns v for Diff.BeforeValue and AfterValue.
+func (d *differ) rawValue(v reflect.Value) interface{} {
+	if d.redacting {
+		return d.opts.redactPlaceholder()
+	}
+	v, ok := redactedCopy(v, d.opts.redactPlaceholder())
+	if !ok {
+		return d.opts.redactPlaceholder()
+	}
+	i := v.Interface()
+	if d.opts.jsonRoundTrip {
+		i = unmasked(i, d.opts.redactPlaceholder())
+	}
+	if _, ok := i.(sqlNull); ok {
+		return nil
+	}
+	return i
+}
+
+// textOf renders i with the methods enabled by
+// WithMarshalers and WithStringer, in that order.
+func (d *differ) textOf(i interface{}) (string, bool) {
+	if d.opts.marshalers {
+		if s, ok := marshalOf(i); ok {
+			return s, true
+		}
+	}
+	if d.opts.stringer {
+		return stringOf(i)
+	}
+	return "", false
+}
+
+/*
+quotable returns i as a string for WithQuotedValues, so that
+it is quoted as strings are. A nil value is left as it is.
+*/
+func quotable(i interface{}) interface{} {
+	switch v := i.(type) {
+	case nil, string:
+		return v
+	case verbatim:
+		return string(v)
+	}
+	return fmt.Sprint(i)
+}
+
+// verbatim is a value that has already been rendered
+// and must not be quoted as strings are.
+type verbatim string
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+/*
+stringOf returns the result of calling String on i if it
+implements fmt.Stringer, or if a pointer to it does. Nil
+pointers are skipped as the method is likely to dereference
+them.
+*/
+func stringOf(i interface{}) (string, bool) {
+	s, ok := implementer(i, stringerType)
+	if !ok {
+		return "", false
+	}
+	return s.(fmt.Stringer).String(), true
+}
+
+/*
+truncate shortens the rendered form of i to n characters if
+it is longer, noting its original length. Strings are cut
+before being quoted so the result remains a valid quoted
+string.
+*/
+func truncate(i interface{}, n int) (string, bool) {
+
+	s, isStr := i.(string)
+	if !isStr {
+		s = fmt.Sprint(i)
+	}
+
+	length := utf8.RuneCountInString(s)
+	if length <= n {
+		return "", false
+	}
+
+	runes := 0
+	for pos := range s {
+		if runes == n {
+			s = s[:pos]
+			break
+		}
+		runes++
+	}
+	if isStr {
+		s = fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprintf("%s… (%d chars)", s, length), true
+}
+
+// typeName returns the type of v as printed by %T, looking
+// through interfaces to the value they hold.
+func typeName(v reflect.Value) string {
+	for v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	return v.Type().String()
+}
+
 func formatInterface(i interface{}) interface{} {
 	if s, ok := i.(string); ok {
 		return fmt.Sprintf("%q", s)
 	}
+	// Templates refuse to print funcs.
+	if t := reflect.TypeOf(i); t != nil && t.Kind() == reflect.Func {
+		return fmt.Sprint(i)
+	}
 	return i
 }
 
 func sameNamedType(t1, t2 reflect.Type) error {
 	if t1.Name() != t2.Name() {
-		return fmt.Errorf(
-			`objects must be same type - "before" was %s, "after" was %s`,
-			t1.Name(), t2.Name())
+		return &ObjectError{
+			Err:        ErrTypeMismatch,
+			BeforeKind: t1.Kind(),
+			AfterKind:  t2.Kind(),
+			BeforeType: t1.Name(),
+			AfterType:  t2.Name(),
+			msg: fmt.Sprintf(
+				`objects must be same type - "before" was %s, "after" was %s`,
+				t1.Name(), t2.Name()),
+		}
 	}
 	return nil
 }
@@ -431,9 +1976,16 @@ func sameKind(t1, t2 reflect.Type) error {
 	kind1 := t1.Kind().String()
 	kind2 := t2.Kind().String()
 	if kind1 != kind2 {
-		return fmt.Errorf(
-			`objects must be same kind - "before" was %s, "after" was %s`,
-			kind1, kind2)
+		return &ObjectError{
+			Err:        ErrKindMismatch,
+			BeforeKind: t1.Kind(),
+			AfterKind:  t2.Kind(),
+			BeforeType: t1.Name(),
+			AfterType:  t2.Name(),
+			msg: fmt.Sprintf(
+				`objects must be same kind - "before" was %s, "after" was %s`,
+				kind1, kind2),
+		}
 	}
 	return nil
 }
@@ -443,23 +1995,42 @@ var objectKinds = []string{"struct", "array", "slice", "map"}
 func isObj(t reflect.Type, which string) error {
 
 	if t == nil {
-		return fmt.Errorf(
+		return notObject(t, which, fmt.Sprintf(
 			`argument %q was nil, wanted non-nil %s`,
 			which,
-			quotedList(objectKinds, "or"))
+			quotedList(objectKinds, "or")))
 	}
 
 	if kind := t.Kind().String(); !in(objectKinds, kind) {
-		return fmt.Errorf(
+		return notObject(t, which, fmt.Sprintf(
 			`argument %q was of kind %q, wanted kind %s`,
 			which,
 			kind,
-			quotedList(objectKinds, "or"))
+			quotedList(objectKinds, "or")))
 	}
 
 	return nil
 }
 
+func notObject(t reflect.Type, which, msg string) error {
+
+	e := &ObjectError{Err: ErrNotObject, Arg: which, msg: msg}
+
+	var kind reflect.Kind
+	var name string
+	if t != nil {
+		kind = t.Kind()
+		name = t.Name()
+	}
+	if which == "before" {
+		e.BeforeKind, e.BeforeType = kind, name
+	} else {
+		e.AfterKind, e.AfterType = kind, name
+	}
+
+	return e
+}
+
 func in(ss []string, s string) bool {
 	for _, item := range ss {
 		if item == s {
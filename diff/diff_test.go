@@ -1,8 +1,12 @@
 package diff
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"text/template"
 )
 
 type config struct {
@@ -48,8 +52,8 @@ func TestObjects(t *testing.T) {
 				"hi": "there",
 			},
 			[]string{
-				`["yo"] deleted "hello"`,
 				`["hi"] added "there"`,
+				`["yo"] deleted "hello"`,
 			},
 			false,
 		},
@@ -152,6 +156,20 @@ func TestObjects(t *testing.T) {
 			},
 			false,
 		},
+
+		// Non-comparable leaf values.
+		{
+			[]func(){nil, nil},
+			[]func(){nil},
+			[]string{`[1] deleted <nil>`},
+			false,
+		},
+		{
+			[]interface{}{[]int{1}, "a"},
+			[]interface{}{[]int{1}, []int{2}},
+			[]string{`[1] changed from "a" to [2]`},
+			false,
+		},
 	}
 
 	for i, c := range cases {
@@ -173,6 +191,27 @@ func TestObjects(t *testing.T) {
 	}
 }
 
+func TestObjectsT(t *testing.T) {
+
+	before := config{true, "0.0.0", 30}
+	after := config{true, "0.0.1", 30}
+	want := []string{`.Version changed from "0.0.0" to "0.0.1"`}
+
+	got, err := ObjectsT(before, after)
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ObjectsT(%v, %v)\n"+
+				"    return %v, %v\n"+
+				"    wanted %v, nil",
+			before, after, got, err, want)
+	}
+
+	// Types that aren't data structures are still rejected.
+	if _, err := ObjectsT(1, 2); err == nil {
+		t.Errorf("ObjectsT(1, 2) returned nil error")
+	}
+}
+
 func TestObjectsF(t *testing.T) {
 
 	cases := []struct {
@@ -225,6 +264,91 @@ func TestObjectsF(t *testing.T) {
 			},
 			false,
 		},
+
+		// Set and cleared values.
+		{
+			config{false, "", 30},
+			config{true, "0.0.1", 0},
+			Format{
+				Set:   DefaultSet,
+				Clear: DefaultClear,
+			},
+			[]string{
+				`.Debug set to true`,
+				`.Version set to "0.0.1"`,
+				`.Timeout cleared 30`,
+			},
+			false,
+		},
+		{
+			config{true, "0.0.0", 30},
+			config{false, "0.0.1", 0},
+			Format{
+				Set: DefaultSet,
+			},
+			[]string{
+				`.Debug changed from true to false`,
+				`.Version changed from "0.0.0" to "0.0.1"`,
+				`.Timeout changed from 30 to 0`,
+			},
+			false,
+		},
+
+		// Custom template functions.
+		{
+			config{true, "abc", 30},
+			config{true, "def", 30},
+			Format{
+				Change: `{{upper .Name}}: {{upper .After}}`,
+				Funcs: template.FuncMap{
+					"upper": func(v interface{}) string {
+						return strings.ToUpper(fmt.Sprint(v))
+					},
+				},
+			},
+			[]string{`.VERSION: "DEF"`},
+			false,
+		},
+
+		// Custom delimiters, with defaults for empty templates.
+		{
+			map[string]int{"a": 1, "b": 2},
+			map[string]int{"a": 3, "c": 4},
+			Format{
+				Change:     `[[.Name]]: [[.Before]] -> [[.After]] {{literal}}`,
+				Set:        `[[.Name]] set`,
+				LeftDelim:  "[[",
+				RightDelim: "]]",
+			},
+			[]string{
+				`["a"]: 1 -> 3 {{literal}}`,
+				`["b"] deleted 2`,
+				`["c"] added 4`,
+			},
+			false,
+		},
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.1", 30},
+			Format{
+				Change:     `{{.Name}}`,
+				LeftDelim:  "[[",
+				RightDelim: "]]",
+			},
+			[]string{`{{.Name}}`},
+			false,
+		},
+
+		// Undefined template function.
+		{
+			config{true, "abc", 30},
+			config{true, "def", 30},
+			Format{
+				Change: `{{upper .Name}}`,
+			},
+			nil,
+			true,
+		},
 	}
 
 	for i, c := range cases {
@@ -246,6 +370,662 @@ func TestObjectsF(t *testing.T) {
 	}
 }
 
+func TestDiffIsSet(t *testing.T) {
+
+	before := map[string]interface{}{"a": "", "b": 1, "c": 1, "d": nil}
+	after := map[string]interface{}{"a": "x", "b": 0, "c": 2, "d": 0}
+
+	diffs, err := Diffs(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]bool{
+		`["a"]`: {true, false},
+		`["b"]`: {false, true},
+		`["c"]`: {false, false},
+		`["d"]`: {false, false},
+	}
+
+	for _, d := range diffs {
+		got := [2]bool{d.IsSet(), d.IsCleared()}
+		if got != want[d.Name] {
+			t.Errorf(
+				"%s IsSet(), IsCleared()\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, %v",
+				d.Name, got[0], got[1], want[d.Name][0], want[d.Name][1])
+		}
+	}
+}
+
+func TestDiffKind(t *testing.T) {
+
+	before := map[string]string{"a": "", "b": "x"}
+	after := map[string]string{"b": "", "c": ""}
+
+	format := Format{
+		Change: "{{.Kind}} {{.Name}}",
+		Add:    "{{.Kind}} {{.Name}}",
+		Delete: "{{.Kind}} {{.Name}}",
+	}
+	want := []string{
+		`delete ["a"]`,
+		`change ["b"]`,
+		`add ["c"]`,
+	}
+
+	got, err := ObjectsF(format, before, after)
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ObjectsF(%v, %v, %v)\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			format, before, after, got, err, want)
+	}
+
+	diffs, err := Diffs(before, after)
+	kinds := []Kind{Delete, Change, Add}
+	if len(diffs) != len(kinds) || err != nil {
+		t.Fatalf(
+			"Diffs(%v, %v)\n"+
+				"    return %v, %v\n"+
+				"    wanted %v",
+			before, after, diffs, err, kinds)
+	}
+	for i, d := range diffs {
+		if d.Kind != kinds[i] {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Diffs(%v, %v)\n"+
+					"    Kind of difference was %v\n"+
+					"    wanted %v",
+				before, after, d.Kind, kinds)
+		}
+	}
+}
+
+func TestDiffTypes(t *testing.T) {
+
+	type settings struct {
+		Timeout interface{}
+		Retries []int
+	}
+
+	before := settings{30, nil}
+	after := settings{"30s", []int{1}}
+
+	format := Format{
+		Change: "({{.Name}} {{.BeforeType}}) became ({{.Name}} {{.AfterType}})",
+		Add:    "({{.Name}} {{.AfterType}}) added",
+	}
+	want := []string{
+		`(.Timeout int) became (.Timeout string)`,
+		`(.Retries[0] int) added`,
+	}
+
+	got, err := ObjectsF(format, before, after)
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ObjectsF(%v, %v, %v)\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			format, before, after, got, err, want)
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+
+	type account struct {
+		Owner    string
+		Balance  float64
+		Password string `diff:"redact"`
+	}
+
+	before := account{"Ann", 10, "a"}
+	after := account{"Anne", 12.5, "b"}
+
+	format := Format{
+		Change: `{{.Name}}: {{printf "%v -> %v" .BeforeValue .AfterValue}}`,
+	}
+	want := []string{
+		`.Owner: Ann -> Anne`,
+		`.Balance: 10 -> 12.5`,
+		`.Password: [REDACTED] -> [REDACTED]`,
+	}
+
+	got, err := ObjectsF(format, before, after)
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ObjectsF(%v, %v, %v)\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			format, before, after, got, err, want)
+	}
+}
+
+func TestFormatVerb(t *testing.T) {
+
+	type point struct {
+		X, Y int
+	}
+	type shape struct {
+		Origin interface{}
+		Mask   uint8
+		Label  string
+	}
+
+	before := shape{point{1, 2}, 0x0f, "a"}
+	after := shape{point{1, 3}, 0xf0, "b"}
+
+	cases := []struct {
+		format Format
+		opts   []Option
+		want   []string
+	}{
+		{
+			Format{},
+			nil,
+			[]string{
+				`.Origin.Y changed from 2 to 3`,
+				`.Mask changed from 15 to 240`,
+				`.Label changed from "a" to "b"`,
+			},
+		},
+		{
+			Format{Verb: "%x"},
+			nil,
+			[]string{
+				`.Origin.Y changed from 2 to 3`,
+				`.Mask changed from f to f0`,
+				`.Label changed from "a" to "b"`,
+			},
+		},
+		{
+			Format{Verb: "%+v"},
+			[]Option{WithLeafTypes(point{})},
+			[]string{
+				`.Origin changed from {X:1 Y:2} to {X:1 Y:3}`,
+				`.Mask changed from 15 to 240`,
+				`.Label changed from "a" to "b"`,
+			},
+		},
+		{
+			Format{},
+			[]Option{WithPathFormat(".Mask", Format{Verb: "%#02x"})},
+			[]string{
+				`.Origin.Y changed from 2 to 3`,
+				`.Mask changed from 0x0f to 0xf0`,
+				`.Label changed from "a" to "b"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(c.format, before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.format, before, after, got, err, c.want)
+		}
+	}
+
+	for _, verb := range []string{"v", "%", "%v%v", "%v "} {
+		if _, err := ObjectsF(Format{Verb: verb}, before, after); err == nil {
+			t.Errorf("ObjectsF(Format{Verb: %q}, ...) returned nil error", verb)
+		}
+	}
+}
+
+func TestMustObjects(t *testing.T) {
+
+	before := config{true, "0.0.0", 30}
+	after := config{true, "0.0.1", 30}
+
+	want := []string{`.Version changed from "0.0.0" to "0.0.1"`}
+	if got := MustObjects(before, after); !equal(got, want) {
+		t.Errorf(
+			"MustObjects(%v, %v)\n"+
+				"    return %q\n"+
+				"    wanted %q",
+			before, after, got, want)
+	}
+
+	format := Format{Change: "{{.Name}}"}
+	want = []string{`.Version`}
+	if got := MustObjectsF(format, before, after); !equal(got, want) {
+		t.Errorf(
+			"MustObjectsF(%v, %v, %v)\n"+
+				"    return %q\n"+
+				"    wanted %q",
+			format, before, after, got, want)
+	}
+
+	for i, fn := range []func(){
+		func() { MustObjects(1, 2) },
+		func() { MustObjectsF(Format{Change: "{{.Apple}}"}, before, after) },
+	} {
+		func() {
+			defer func() {
+				if _, ok := recover().(error); !ok {
+					fmt.Printf("Case #%d:\n", i+1)
+					t.Errorf("MustObjects didn't panic with an error")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestObjectsCtx(t *testing.T) {
+
+	before := config{true, "0.0.0", 30}
+	after := config{false, "0.0.0", 30}
+	want := []string{`.Debug changed from true to false`}
+
+	got, err := ObjectsCtx(context.Background(), before, after)
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ObjectsCtx(ctx, %v, %v)\n"+
+				"    return %v, %v\n"+
+				"    wanted %v, nil",
+			before, after, got, err, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err = ObjectsCtx(ctx, before, after)
+	if got != nil || err != context.Canceled {
+		t.Errorf(
+			"ObjectsCtx(cancelled, %v, %v)\n"+
+				"    return %v, %v\n"+
+				"    wanted %v, %v",
+			before, after, got, err, nil, context.Canceled)
+	}
+}
+
+func TestEqual(t *testing.T) {
+
+	cases := []struct {
+		before  interface{}
+		after   interface{}
+		want    bool
+		wantErr bool
+	}{
+		// Nil object.
+		{
+			[]string{"hi", "there"},
+			nil,
+			false,
+			true,
+		},
+
+		// Structs of different types.
+		{
+			config{},
+			notConfig{},
+			false,
+			true,
+		},
+
+		// Filled structs of same type.
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.0", 30},
+			true,
+			false,
+		},
+
+		// Filled structs of same type, different values.
+		{
+			config{true, "0.0.0", 30},
+			config{false, "0.0.1", 15},
+			false,
+			false,
+		},
+
+		// Nested maps where one is nil.
+		{
+			nestedTest{},
+			nestedTest{
+				Mapping: map[string][]string{
+					"yo": []string{"hi"},
+				},
+			},
+			false,
+			false,
+		},
+	}
+
+	for i, c := range cases {
+
+		errStr := "nil"
+		if c.wantErr {
+			errStr = "error"
+		}
+
+		got, err := Equal(c.before, c.after)
+		if got != c.want || err == nil && c.wantErr || err != nil && !c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Equal(%v, %v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, %v",
+				c.before, c.after, got, err, c.want, errStr)
+		}
+	}
+}
+
+func TestHasChanges(t *testing.T) {
+
+	cases := []struct {
+		before  interface{}
+		after   interface{}
+		opts    []Option
+		want    bool
+		wantErr bool
+	}{
+		{config{}, notConfig{}, nil, false, true},
+		{config{true, "0.0.0", 30}, config{true, "0.0.0", 30}, nil, false, false},
+		{config{true, "0.0.0", 30}, config{false, "0.0.0", 30}, nil, true, false},
+		{
+			config{true, "0.0.0", 30},
+			config{false, "0.0.0", 30},
+			[]Option{WithKinds(Add, Delete)},
+			false,
+			false,
+		},
+	}
+
+	for i, c := range cases {
+
+		errStr := "nil"
+		if c.wantErr {
+			errStr = "error"
+		}
+
+		got, err := HasChanges(c.before, c.after, c.opts...)
+		if got != c.want || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"HasChanges(%v, %v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, %v",
+				c.before, c.after, got, err, c.want, errStr)
+		}
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+
+	cases := []struct {
+		before  interface{}
+		after   interface{}
+		want    []string
+		wantErr bool
+	}{
+		// Nil object.
+		{
+			[]string{"hi", "there"},
+			nil,
+			nil,
+			true,
+		},
+
+		// Filled structs of same type.
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.0", 30},
+			nil,
+			false,
+		},
+
+		// Filled structs of same type, different values.
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.1", 15},
+			[]string{".Version", ".Timeout"},
+			false,
+		},
+
+		// Nested arrays where one is nil.
+		{
+			nestedTest{
+				Mapping: map[string][]string{
+					"yo": []string{"hi", "there"},
+				},
+			},
+			nestedTest{
+				Mapping: map[string][]string{},
+			},
+			[]string{
+				`.Mapping["yo"][0]`,
+				`.Mapping["yo"][1]`,
+			},
+			false,
+		},
+	}
+
+	for i, c := range cases {
+
+		errStr := "nil"
+		if c.wantErr {
+			errStr = "error"
+		}
+
+		got, err := ChangedPaths(c.before, c.after)
+		if !equal(got, c.want) || err == nil && c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ChangedPaths(%v, %v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, %v",
+				c.before, c.after, got, err, c.want, errStr)
+		}
+	}
+}
+
+func TestFromZero(t *testing.T) {
+
+	cases := []struct {
+		v       interface{}
+		want    []string
+		wantErr bool
+	}{
+		{
+			config{true, "", 30},
+			[]string{
+				`.Debug changed from false to true`,
+				`.Timeout changed from 0 to 30`,
+			},
+			false,
+		},
+		{
+			nestedTest{
+				Mapping: map[string][]string{
+					"yo": []string{"hi"},
+				},
+			},
+			[]string{`.Mapping["yo"][0] added "hi"`},
+			false,
+		},
+		{
+			[]int{4, 5},
+			[]string{
+				`[0] added 4`,
+				`[1] added 5`,
+			},
+			false,
+		},
+		{
+			config{},
+			nil,
+			false,
+		},
+		{
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for i, c := range cases {
+
+		errStr := "nil"
+		if c.wantErr {
+			errStr = "error"
+		}
+
+		got, err := FromZero(c.v)
+		if !equal(got, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"FromZero(%v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, %v",
+				c.v, got, err, c.want, errStr)
+		}
+	}
+}
+
+func TestIdentical(t *testing.T) {
+
+	type inner struct {
+		Any interface{}
+	}
+
+	shared := []int{1, 2, 3}
+	m := map[string]int{"a": 1}
+
+	cases := []struct {
+		a    interface{}
+		b    interface{}
+		want bool
+	}{
+		{config{true, "a", 1}, config{true, "a", 1}, true},
+		{config{true, "a", 1}, config{true, "a", 2}, false},
+		{[2]int{1, 2}, [2]int{1, 2}, true},
+		{shared, shared, true},
+		{shared, shared[:2], false},
+		{shared, []int{1, 2, 3}, false},
+		{m, m, true},
+		{map[string]int{"a": 1}, map[string]int{"a": 1}, false},
+
+		// Interfaces might hold non-comparable values.
+		{inner{[]int{1}}, inner{[]int{1}}, false},
+	}
+
+	for i, c := range cases {
+		v1 := reflect.ValueOf(c.a)
+		v2 := reflect.ValueOf(c.b)
+		if got := identical(&v1, &v2); got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"identical(%v, %v)\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestFieldsOf(t *testing.T) {
+
+	type tagged struct {
+		Name     string
+		password string `diff:"redact"`
+		Other    int    `diff:"x, redact"`
+	}
+
+	want := []fieldInfo{
+		{"Name", 0, false, 0, true, false},
+		{"password", 1, true, 0, false, false},
+		{"Other", 2, true, 0, true, false},
+	}
+
+	typ := reflect.TypeOf(tagged{})
+
+	// The second call is served from the cache.
+	for i := 0; i < 2; i++ {
+		if got := fieldsOf(typ); !reflect.DeepEqual(got, want) {
+			t.Errorf(
+				"fieldsOf(%v)\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				typ, got, want)
+		}
+	}
+}
+
+func TestDifferName(t *testing.T) {
+
+	d := differ{}
+
+	steps := []struct {
+		push *Segment
+		want string
+	}{
+		{&Segment{Kind: FieldSegment, Name: "A"}, ".A"},
+		{&Segment{Kind: KeySegment, Key: "k"}, `.A["k"]`},
+		{nil, ".A"},
+		{&Segment{Kind: IndexSegment, Index: 3}, ".A[3]"},
+		{&Segment{Kind: FieldSegment, Name: "B"}, ".A[3].B"},
+		{nil, ".A[3]"},
+		{nil, ".A"},
+		{nil, ""},
+		{&Segment{Kind: FieldSegment, Name: "C"}, ".C"},
+	}
+
+	for i, s := range steps {
+		if s.push != nil {
+			d.pushPath(*s.push)
+		} else {
+			d.popPath()
+		}
+		if got := d.name(); got != s.want || got != d.path.String() {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"differ.name()\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				got, s.want)
+		}
+	}
+}
+
+func TestAddressable(t *testing.T) {
+
+	type secret struct {
+		Name     string
+		password string
+	}
+
+	cases := []struct {
+		v    interface{}
+		want bool
+	}{
+		{config{}, false},
+		{secret{}, true},
+	}
+
+	for i, c := range cases {
+		v := reflect.ValueOf(c.v)
+		got := addressable(&v, fieldsOf(v.Type())).CanAddr()
+		if got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"addressable(%#v).CanAddr()\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.v, got, c.want)
+		}
+	}
+}
+
 func equal(s1, s2 []string) bool {
 
 	if len(s1) != len(s2) {
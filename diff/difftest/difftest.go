@@ -0,0 +1,41 @@
+/*
+Package difftest provides helpers for using package diff
+in tests.
+
+	func TestLoad(t *testing.T) {
+		got := Load("testdata/config.toml")
+		difftest.Equal(t, want, got)
+	}
+*/
+package difftest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jakebowkett/go-diff/diff"
+)
+
+/*
+Equal diffs want against got with diff.Objects and opts, and
+marks t as failed with every difference found if they aren't
+equal. It also fails t if want and got can't be diffed, such as
+when they are of different types. Equal reports whether the
+objects were equal so that callers may stop early.
+*/
+func Equal(t testing.TB, want, got interface{}, opts ...diff.Option) bool {
+
+	t.Helper()
+
+	changes, err := diff.Objects(want, got, opts...)
+	if err != nil {
+		t.Errorf("difftest: %v", err)
+		return false
+	}
+	if len(changes) == 0 {
+		return true
+	}
+
+	t.Errorf("objects differ from want to got:\n\t%s", strings.Join(changes, "\n\t"))
+	return false
+}
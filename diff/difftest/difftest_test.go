@@ -0,0 +1,79 @@
+package difftest
+
+import (
+	"fmt"
+	"testing"
+)
+
+type config struct {
+	Debug   bool
+	Version string
+}
+
+type notConfig struct {
+	Debug   bool
+	Version string
+}
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	msgs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+func TestEqual(t *testing.T) {
+
+	cases := []struct {
+		want    interface{}
+		got     interface{}
+		equal   bool
+		message string
+	}{
+		{
+			config{true, "1"},
+			config{true, "1"},
+			true,
+			"",
+		},
+		{
+			config{true, "1"},
+			config{false, "2"},
+			false,
+			"objects differ from want to got:\n" +
+				"\t.Debug changed from true to false\n" +
+				"\t.Version changed from \"1\" to \"2\"",
+		},
+		{
+			config{},
+			notConfig{},
+			false,
+			`difftest: objects must be same type - "before" was config, "after" was notConfig`,
+		},
+	}
+
+	for i, c := range cases {
+
+		r := &recorder{}
+		equal := Equal(r, c.want, c.got)
+
+		var message string
+		if len(r.msgs) > 0 {
+			message = r.msgs[0]
+		}
+
+		if equal != c.equal || message != c.message || len(r.msgs) > 1 {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Equal(t, %v, %v)\n"+
+					"    return %v, failed with %q\n"+
+					"    wanted %v, failed with %q",
+				c.want, c.got, equal, r.msgs, c.equal, c.message)
+		}
+	}
+}
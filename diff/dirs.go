@@ -0,0 +1,180 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+/*
+Dirs walks the directory trees rooted at dir1 and dir2 and
+returns the files added, deleted, and changed between them
+in the same form as Objects. Files are compared by the
+SHA-256 hash of their contents and named by their path
+relative to the root, using slashes, as a map key would be:
+
+	["manifests/app.yaml"] changed from <1.2 KiB, sha256 ab12cd34…> to <1.3 KiB, sha256 9f00e1d2…>
+
+Files are reported in lexical order of their paths. Only
+regular files are compared, so empty directories, and
+symbolic links, which aren't followed, are ignored.
+
+An error is returned if either tree cannot be read.
+*/
+func Dirs(dir1, dir2 string, opts ...Option) (changes []string, err error) {
+	return DirsF(Format{}, dir1, dir2, opts...)
+}
+
+/*
+DirsF works the same as Dirs with an additional parameter
+allowing for custom formatting, as with ObjectsF.
+*/
+func DirsF(format Format, dir1, dir2 string, opts ...Option) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	files1, err := hashTree(dir1)
+	if err != nil {
+		return nil, err
+	}
+	files2, err := hashTree(dir2)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	d := differ{opts: o, templates: t}
+	err = d.truncated(d.safely(func() error {
+		return d.diffFiles(files1, files2)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if len(d.errs) > 0 {
+		return d.changes, errors.Join(d.errs...)
+	}
+
+	return d.changes, nil
+}
+
+type fileHash struct {
+	size int64
+	sum  [sha256.Size]byte
+}
+
+// hashTree returns the hash of each regular file beneath
+// root, keyed by its slash separated path relative to root.
+func hashTree(root string) (map[string]fileHash, error) {
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	files := map[string]fileHash{}
+
+	err = filepath.WalkDir(root, func(path string, e fs.DirEntry, err error) error {
+		if err != nil || !e.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		h, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = h
+		return nil
+	})
+
+	return files, err
+}
+
+func hashFile(path string) (fileHash, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fileHash{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return fileHash{}, err
+	}
+
+	fh := fileHash{size: n}
+	h.Sum(fh.sum[:0])
+	return fh, nil
+}
+
+func (d *differ) diffFiles(files1, files2 map[string]fileHash) error {
+
+	var names []string
+	for name := range files1 {
+		names = append(names, name)
+	}
+	for name := range files2 {
+		if _, ok := files1[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		h1, ok1 := files1[name]
+		h2, ok2 := files2[name]
+
+		var kind Kind
+		switch {
+		case !ok1:
+			kind = Add
+		case !ok2:
+			kind = Delete
+		case h1 != h2:
+			kind = Change
+		case d.opts.unchanged:
+			kind = Same
+		default:
+			continue
+		}
+
+		var v1, v2 *reflect.Value
+		if ok1 {
+			v := reflect.ValueOf(summary(h1.size, h1.sum))
+			v1 = &v
+		}
+		if ok2 {
+			v := reflect.ValueOf(summary(h2.size, h2.sum))
+			v2 = &v
+		}
+
+		d.pushPath(keySegment(name))
+		err := d.report(Diff{}, kind, v1, v2)
+		if err != nil {
+			return err
+		}
+		d.popPath()
+	}
+
+	return nil
+}
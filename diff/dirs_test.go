@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+
+	root := t.TempDir()
+
+	tree := func(name string, files map[string]string) string {
+		dir := filepath.Join(root, name)
+		for path, content := range files {
+			path = filepath.Join(dir, filepath.FromSlash(path))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	cases := []struct {
+		before map[string]string
+		after  map[string]string
+		opts   []Option
+		want   []string
+	}{
+		{
+			map[string]string{
+				"a.yaml":     "a",
+				"sub/b.yaml": "b",
+				"sub/c.yaml": "c",
+			},
+			map[string]string{
+				"a.yaml":     "a",
+				"sub/b.yaml": "bb",
+				"sub/d.yaml": "c",
+			},
+			nil,
+			[]string{
+				`["sub/b.yaml"] changed from <1 B, sha256 3e23e816…> to <2 B, sha256 3b64db95…>`,
+				`["sub/c.yaml"] deleted <1 B, sha256 2e7d2c03…>`,
+				`["sub/d.yaml"] added <1 B, sha256 2e7d2c03…>`,
+			},
+		},
+		{
+			map[string]string{"a": "a"},
+			map[string]string{"a": "a"},
+			[]Option{WithUnchanged()},
+			[]string{
+				`["a"] unchanged <1 B, sha256 ca978112…>`,
+			},
+		},
+		{
+			nil,
+			nil,
+			nil,
+			nil,
+		},
+	}
+
+	for i, c := range cases {
+
+		dir1 := tree(fmt.Sprintf("%d-before", i), c.before)
+		dir2 := tree(fmt.Sprintf("%d-after", i), c.after)
+
+		got, err := Dirs(dir1, dir2, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Dirs(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+
+	file := filepath.Join(root, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{file, filepath.Join(root, "missing")} {
+		if _, err := Dirs(dir, root); err == nil {
+			t.Errorf("Dirs(%q, %q) returned nil error", dir, root)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package diff
+
+import (
+	"reflect"
+)
+
+/*
+distance returns the Levenshtein distance between v1 and v2
+if they are both strings, counted in characters.
+*/
+func distance(v1, v2 reflect.Value) (int, bool) {
+
+	s1, ok1 := stringValue(v1)
+	s2, ok2 := stringValue(v2)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+
+	return levenshtein([]rune(s1), []rune(s2)), true
+}
+
+func levenshtein(r1, r2 []rune) int {
+
+	// prev and curr are consecutive rows of the usual
+	// table, holding the distances between a prefix of
+	// r1 and each prefix of r2.
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(r2)]
+}
+
+/*
+minInt and maxInt stand in for the min and max builtins,
+which need Go 1.21.
+*/
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+
+	cases := []struct {
+		s1   string
+		s2   string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"naïve", "naive", 1},
+		{"same", "same", 0},
+	}
+
+	for i, c := range cases {
+		got := levenshtein([]rune(c.s1), []rune(c.s2))
+		if got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"levenshtein(%q, %q)\n"+
+					"    return %d\n"+
+					"    wanted %d",
+				c.s1, c.s2, got, c.want)
+		}
+	}
+}
+
+func TestWithEditDistance(t *testing.T) {
+
+	type article struct {
+		Title string
+		Body  string
+		Views int
+		Notes []interface{}
+	}
+
+	before := article{"Teh title", "A short body.", 1, []interface{}{"colour"}}
+	after := article{"The title", "A long body!", 2, []interface{}{"color"}}
+
+	format := Format{Change: "{{.Name}} ({{.Distance}})"}
+
+	cases := []struct {
+		min  int
+		want []string
+	}{
+		{
+			0,
+			[]string{
+				`.Title (2)`,
+				`.Body (5)`,
+				`.Views (0)`,
+				`.Notes[0] (1)`,
+			},
+		},
+		{
+			3,
+			[]string{
+				`.Body (5)`,
+				`.Views (0)`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(format, before, after, WithEditDistance(c.min))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v, WithEditDistance(%d))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				format, before, after, c.min, got, err, c.want)
+		}
+	}
+
+	v1 := []string{before.Title}
+	v2 := []string{after.Title}
+	equal, err := Equal(v1, v2, WithEditDistance(3))
+	if !equal || err != nil {
+		t.Errorf(
+			"Equal(%q, %q, WithEditDistance(3))\n"+
+				"    return %v, %v\n"+
+				"    wanted true, nil",
+			v1, v2, equal, err)
+	}
+}
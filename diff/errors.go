@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"errors"
+	"reflect"
+)
+
+/*
+These are the causes of an ObjectError. Use errors.Is to
+test for them.
+*/
+var (
+	// One of the arguments was nil or not a struct,
+	// map, slice, or array.
+	ErrNotObject = errors.New("argument is not a struct, map, slice, or array")
+
+	// The arguments were of different kinds.
+	ErrKindMismatch = errors.New("arguments are of different kinds")
+
+	// The arguments were named types with different names.
+	ErrTypeMismatch = errors.New("arguments are of different types")
+)
+
+/*
+ErrPanic is wrapped by the error returned when a panic occurs
+during a diff, such as from a String method called because
+of WithStringer. The error is a *PathError naming the value
+that was being diffed.
+*/
+var ErrPanic = errors.New("panic during diff")
+
+/*
+ErrLimit is wrapped by the error returned when a diff is
+abandoned for exceeding a limit set with WithLimits. The
+error is a *PathError naming the value being diffed when
+the limit was reached.
+*/
+var ErrLimit = errors.New("diff limit exceeded")
+
+/*
+ObjectError is returned when the arguments to a diff are
+unsuitable. Err is ErrNotObject, ErrKindMismatch, or
+ErrTypeMismatch.
+
+The kinds and type names of the arguments are included so
+that callers need not inspect them again. For ErrNotObject
+only the argument at fault, named by Arg as "before" or
+"after", is described. A nil argument has kind
+reflect.Invalid.
+*/
+type ObjectError struct {
+	Err        error
+	Arg        string
+	BeforeKind reflect.Kind
+	AfterKind  reflect.Kind
+	BeforeType string
+	AfterType  string
+
+	msg string
+}
+
+func (e *ObjectError) Error() string {
+	return e.msg
+}
+
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+/*
+PathError records an error that occurred while handling the
+difference at Path, which is rendered the same as Diff.Name.
+*/
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+func TestWithAggregateErrors(t *testing.T) {
+
+	errOdd := errors.New("odd")
+
+	format := Format{
+		Change: "{{.Name}} {{check .After}}",
+		Funcs: template.FuncMap{
+			"check": func(v interface{}) (interface{}, error) {
+				if n, ok := v.(int); ok && n%2 == 1 {
+					return nil, errOdd
+				}
+				return v, nil
+			},
+		},
+	}
+
+	before := []int{0, 0, 0, 0}
+	after := []int{1, 2, 3, 4}
+	want := []string{"[1] 2", "[3] 4"}
+
+	got, err := ObjectsF(format, before, after, WithAggregateErrors())
+	if !equal(got, want) {
+		t.Errorf(
+			"ObjectsF(format, %v, %v, WithAggregateErrors())\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, error",
+			before, after, got, err, want)
+	}
+
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("error %v does not wrap %v", err, errOdd)
+	}
+
+	var pe *PathError
+	if !errors.As(err, &pe) || pe.Path != "[0]" {
+		t.Errorf("error %v does not contain a *PathError for [0]", err)
+	}
+
+	got, err = ObjectsF(format, before, after)
+	if got != nil || err == nil {
+		fmt.Printf("Without WithAggregateErrors:\n")
+		t.Errorf(
+			"ObjectsF(format, %v, %v)\n"+
+				"    return %q, %v\n"+
+				"    wanted nil, error",
+			before, after, got, err)
+	}
+}
+
+func TestObjectError(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		want   ObjectError
+	}{
+		{
+			[]string{},
+			nil,
+			ObjectError{
+				Err:        ErrNotObject,
+				Arg:        "after",
+				BeforeKind: reflect.Invalid,
+				AfterKind:  reflect.Invalid,
+			},
+		},
+		{
+			1,
+			[]string{},
+			ObjectError{
+				Err:        ErrNotObject,
+				Arg:        "before",
+				BeforeKind: reflect.Int,
+				BeforeType: "int",
+			},
+		},
+		{
+			config{},
+			[3]int{},
+			ObjectError{
+				Err:        ErrKindMismatch,
+				BeforeKind: reflect.Struct,
+				AfterKind:  reflect.Array,
+				BeforeType: "config",
+			},
+		},
+		{
+			config{},
+			notConfig{},
+			ObjectError{
+				Err:        ErrTypeMismatch,
+				BeforeKind: reflect.Struct,
+				AfterKind:  reflect.Struct,
+				BeforeType: "config",
+				AfterType:  "notConfig",
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		_, err := Objects(c.before, c.after)
+
+		var got *ObjectError
+		if !errors.As(err, &got) || !errors.Is(err, c.want.Err) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf("Objects(%v, %v) returned %v, wanted %v", c.before, c.after, err, c.want.Err)
+			continue
+		}
+
+		got.msg = ""
+		if *got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %+v\n"+
+					"    wanted %+v",
+				c.before, c.after, *got, c.want)
+		}
+	}
+}
+
+type explosive int
+
+func (explosive) String() string {
+	panic("boom")
+}
+
+func TestPanicRecovery(t *testing.T) {
+
+	type payload struct {
+		Items map[string][]explosive
+	}
+
+	before := payload{}
+	after := payload{
+		Items: map[string][]explosive{"a": {1}},
+	}
+
+	got, err := Objects(before, after, WithStringer())
+
+	var pe *PathError
+	if got != nil || !errors.Is(err, ErrPanic) || !errors.As(err, &pe) || pe.Path != `.Items["a"][0]` {
+		t.Errorf(
+			"Objects(%v, %v, WithStringer())\n"+
+				"    return %v, %v\n"+
+				"    wanted nil, panic error at .Items[\"a\"][0]",
+			before, after, got, err)
+	}
+}
@@ -0,0 +1,52 @@
+package diff
+
+import "reflect"
+
+/*
+excerpts returns v1 and v2 cut down to n characters either
+side of the first character in which they differ, where n is
+set with WithStringContext, if they're both strings. Any part
+that is cut off is replaced by "…". Values that aren't
+strings are returned as they are.
+*/
+func (d *differ) excerpts(v1, v2 reflect.Value) (reflect.Value, reflect.Value) {
+
+	s1, ok1 := stringValue(v1)
+	s2, ok2 := stringValue(v2)
+	if !ok1 || !ok2 {
+		return v1, v2
+	}
+
+	r1, r2 := []rune(s1), []rune(s2)
+	at := 0
+	for at < len(r1) && at < len(r2) && r1[at] == r2[at] {
+		at++
+	}
+
+	n := d.opts.stringContext
+	e1 := reflect.ValueOf(excerpt(r1, at, n))
+	e2 := reflect.ValueOf(excerpt(r2, at, n))
+
+	return e1, e2
+}
+
+// excerpt returns the runes of r within n of index at,
+// marking any that are left out with "…".
+func excerpt(r []rune, at, n int) string {
+
+	start := maxInt(at-n, 0)
+	end := minInt(at+n+1, len(r))
+	if start > end {
+		start = end
+	}
+
+	s := string(r[start:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(r) {
+		s += "…"
+	}
+
+	return s
+}
@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWithStringContext(t *testing.T) {
+
+	type page struct {
+		Body  string
+		Title string
+		Size  int
+	}
+
+	long := strings.Repeat("x", 100)
+
+	cases := []struct {
+		before page
+		after  page
+		n      int
+		want   []string
+	}{
+		{
+			page{long + "abcXdef" + long, "a", 1},
+			page{long + "abcYdef" + long, "b", 2},
+			3,
+			[]string{
+				`.Body changed from "…abcXdef…" to "…abcYdef…"`,
+				`.Title changed from "a" to "b"`,
+				`.Size changed from 1 to 2`,
+			},
+		},
+		{
+			page{Body: "abcdef"},
+			page{Body: "abXdef" + long},
+			2,
+			[]string{
+				`.Body changed from "abcde…" to "abXde…"`,
+			},
+		},
+		{
+			page{Body: "héllo wörld"},
+			page{Body: "héllo wörld!"},
+			2,
+			[]string{
+				`.Body changed from "…ld" to "…ld!"`,
+			},
+		},
+		{
+			page{Body: "short"},
+			page{Body: "shirt"},
+			0,
+			[]string{
+				`.Body changed from "short" to "shirt"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, WithStringContext(c.n))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithStringContext(%d))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, c.n, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+FieldMask returns the paths of the fields that differ between
+two protobuf messages of the same type, named as they are in
+the .proto file, for use as the paths of a
+google.protobuf.FieldMask:
+
+	paths, err := diff.FieldMask(before, after)
+	mask := &fieldmaskpb.FieldMask{Paths: paths}
+
+The arguments must be messages generated by protoc-gen-go or
+pointers to them. Changes within a nested message are given
+by their full path, such as "address.city", unless the
+message has been set or cleared, in which case its own path
+is given. Repeated fields, maps, and oneof members are given
+as a whole, as field masks can't refer to their contents.
+Paths are in the order the fields are declared.
+
+Fields are compared as they would be by Equal with opts.
+*/
+func FieldMask(before, after interface{}, opts ...Option) (paths []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	v1 := reflect.Indirect(reflect.ValueOf(before))
+	v2 := reflect.Indirect(reflect.ValueOf(after))
+
+	for _, arg := range []struct {
+		v     reflect.Value
+		which string
+	}{{v1, "before"}, {v2, "after"}} {
+		if !arg.v.IsValid() || !isProtoMessage(arg.v.Type()) {
+			return nil, fmt.Errorf("argument %q is not a protobuf message", arg.which)
+		}
+	}
+	if v1.Type() != v2.Type() {
+		return nil, &ObjectError{
+			Err:        ErrTypeMismatch,
+			BeforeKind: reflect.Struct,
+			AfterKind:  reflect.Struct,
+			BeforeType: v1.Type().Name(),
+			AfterType:  v2.Type().Name(),
+			msg: fmt.Sprintf(
+				`objects must be same type - "before" was %s, "after" was %s`,
+				v1.Type().Name(), v2.Type().Name()),
+		}
+	}
+
+	d := differ{opts: newOptions(opts), stopEarly: true}
+	err = d.fieldMask(v1, v2, "", &paths)
+	return paths, err
+}
+
+/*
+fieldMask appends the paths of the fields that differ
+between messages v1 and v2 to paths, each prefixed with
+prefix.
+*/
+func (d *differ) fieldMask(v1, v2 reflect.Value, prefix string, paths *[]string) error {
+
+	// Copies are made so that unexported fields can be read.
+	c1 := reflect.New(v1.Type()).Elem()
+	c1.Set(v1)
+	c2 := reflect.New(v2.Type()).Elem()
+	c2.Set(v2)
+
+	for _, fi := range fieldsOf(v1.Type()) {
+
+		sf := v1.Type().Field(fi.index)
+		f1 := c1.Field(fi.index)
+		f2 := c2.Field(fi.index)
+
+		if _, ok := sf.Tag.Lookup("protobuf_oneof"); ok {
+			for _, name := range d.oneofChanges(f1, f2) {
+				*paths = append(*paths, prefix+name)
+			}
+			continue
+		}
+
+		name := protoName(sf)
+		if name == "" {
+			continue
+		}
+
+		isMsg := f1.Kind() == reflect.Ptr && isProtoMessage(f1.Type().Elem())
+		if isMsg && !f1.IsNil() && !f2.IsNil() {
+			err := d.fieldMask(f1.Elem(), f2.Elem(), prefix+name+".", paths)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		changed, err := d.differs(f1, f2)
+		if err != nil {
+			return err
+		}
+		if changed {
+			*paths = append(*paths, prefix+name)
+		}
+	}
+
+	return nil
+}
+
+/*
+oneofChanges returns the names of the members of a oneof
+that differ between f1 and f2, the interfaces holding them.
+Each member is held in a wrapper struct with a single field.
+*/
+func (d *differ) oneofChanges(f1, f2 reflect.Value) []string {
+
+	member := func(f reflect.Value) (string, reflect.Value, bool) {
+		if f.IsNil() {
+			return "", reflect.Value{}, false
+		}
+		w := reflect.Indirect(f.Elem())
+		if w.Kind() != reflect.Struct || w.NumField() != 1 {
+			return "", reflect.Value{}, false
+		}
+		return protoName(w.Type().Field(0)), w.Field(0), true
+	}
+
+	name1, m1, ok1 := member(f1)
+	name2, m2, ok2 := member(f2)
+
+	switch {
+	case !ok1 && !ok2:
+		return nil
+	case !ok1:
+		return []string{name2}
+	case !ok2:
+		return []string{name1}
+	case name1 != name2:
+		return []string{name1, name2}
+	}
+
+	if changed, err := d.differs(m1, m2); err != nil || changed {
+		return []string{name1}
+	}
+	return nil
+}
+
+/*
+differs reports whether v1 and v2, fields of a message,
+differ. Pointers, such as those of optional scalars, are
+compared by what they point to.
+*/
+func (d *differ) differs(v1, v2 reflect.Value) (bool, error) {
+	d.rewind()
+	d.protoField = true
+	err := d.run(&v1, &v2)
+	if err == errStop {
+		return true, nil
+	}
+	return false, err
+}
+
+/*
+protoName returns the name in the protobuf struct tag of f,
+such as "user_id" for `protobuf:"varint,1,opt,name=user_id"`,
+or "" if it has none.
+*/
+func protoName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+	return ""
+}
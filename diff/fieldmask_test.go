@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type protoAccount struct {
+	state         struct{ atomic *int }
+	sizeCache     int32
+	unknownFields []byte
+
+	UserId  int64         `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Tags    []string      `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Address *protoAddress `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	// Types that are assignable to Contact:
+	//
+	//	*protoAccount_Email
+	//	*protoAccount_Phone
+	Contact isProtoAccount_Contact `protobuf_oneof:"contact"`
+	Nick    *string                `protobuf:"bytes,6,opt,name=nick,proto3,oneof" json:"nick,omitempty"`
+}
+
+type isProtoAccount_Contact interface {
+	isProtoAccount_Contact()
+}
+
+type protoAccount_Email struct {
+	Email string `protobuf:"bytes,4,opt,name=email,proto3,oneof"`
+}
+
+type protoAccount_Phone struct {
+	Phone string `protobuf:"bytes,5,opt,name=phone,proto3,oneof"`
+}
+
+func (*protoAccount) ProtoReflect()                 {}
+func (*protoAccount_Email) isProtoAccount_Contact() {}
+func (*protoAccount_Phone) isProtoAccount_Contact() {}
+
+func TestFieldMask(t *testing.T) {
+
+	email := func(s string) isProtoAccount_Contact { return &protoAccount_Email{s} }
+	phone := func(s string) isProtoAccount_Contact { return &protoAccount_Phone{s} }
+	nick := func(s string) *string { return &s }
+
+	cases := []struct {
+		before *protoAccount
+		after  *protoAccount
+		want   []string
+	}{
+		{
+			&protoAccount{UserId: 1, sizeCache: 3},
+			&protoAccount{UserId: 1},
+			nil,
+		},
+		{
+			&protoAccount{UserId: 1, Tags: []string{"a"}, Address: &protoAddress{City: "Oslo"}},
+			&protoAccount{UserId: 2, Tags: []string{"a", "b"}, Address: &protoAddress{City: "Bergen"}},
+			[]string{"user_id", "tags", "address.city"},
+		},
+		{
+			&protoAccount{Address: &protoAddress{City: "Oslo"}},
+			&protoAccount{},
+			[]string{"address"},
+		},
+		{
+			&protoAccount{Contact: email("a@b")},
+			&protoAccount{Contact: email("a@c")},
+			[]string{"email"},
+		},
+		{
+			&protoAccount{Contact: email("a@b")},
+			&protoAccount{Contact: email("a@b")},
+			nil,
+		},
+		{
+			&protoAccount{Contact: email("a@b")},
+			&protoAccount{Contact: phone("555")},
+			[]string{"email", "phone"},
+		},
+		{
+			&protoAccount{},
+			&protoAccount{Contact: phone("555")},
+			[]string{"phone"},
+		},
+		{
+			&protoAccount{Nick: nick("x")},
+			&protoAccount{Nick: nick("x")},
+			nil,
+		},
+		{
+			&protoAccount{Nick: nick("x")},
+			&protoAccount{Nick: nick("y")},
+			[]string{"nick"},
+		},
+		{
+			&protoAccount{},
+			&protoAccount{Nick: nick("")},
+			[]string{"nick"},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := FieldMask(c.before, c.after)
+		if !reflect.DeepEqual(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"FieldMask(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestFieldMaskErrors(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+	}{
+		{nil, &protoAccount{}},
+		{config{}, config{}},
+		{&protoAccount{}, &protoUser{}},
+	}
+
+	for i, c := range cases {
+		got, err := FieldMask(c.before, c.after)
+		if err == nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"FieldMask(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted nil, error",
+				c.before, c.after, got, err)
+		}
+	}
+
+	_, err := FieldMask(&protoAccount{}, &protoUser{})
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("FieldMask of different messages returned %v, wanted %v", err, ErrTypeMismatch)
+	}
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+Files reads the files at path1 and path2 and returns the
+difference between them as Objects does. The format of the
+files is chosen by the extension of path1: .json files are
+decoded as JSON, .yaml and .yml files as YAML, and .toml files
+as TOML, as described for YAML and TOML. JSON numbers are
+compared as they were written. Any other file is compared as
+plain text, as a slice of its lines, so that a changed line is
+reported as `[3] changed from "old" to "new"`. WithMoves may
+be used to keep inserted lines from being reported as changes
+to each line that follows.
+
+An error is returned if either file cannot be read or decoded.
+*/
+func Files(path1, path2 string, opts ...Option) (changes []string, err error) {
+	return FilesF(Format{}, path1, path2, opts...)
+}
+
+/*
+FilesF works the same as Files with an additional parameter
+allowing for custom formatting, as with ObjectsF.
+*/
+func FilesF(format Format, path1, path2 string, opts ...Option) (changes []string, err error) {
+
+	before, err := os.ReadFile(path1)
+	if err != nil {
+		return nil, err
+	}
+	after, err := os.ReadFile(path2)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path1)) {
+	case ".json":
+		return jsonF(format, before, after, opts)
+	case ".yaml", ".yml":
+		return YAMLF(format, before, after, opts...)
+	case ".toml":
+		return TOMLF(format, before, after, opts...)
+	}
+
+	return objects(format, lines(before), lines(after), opts)
+}
+
+/*
+jsonF decodes before and after as JSON and diffs them.
+Numbers are kept as json.Number so that large integers
+aren't rounded.
+*/
+func jsonF(format Format, before, after []byte, opts []Option) ([]string, error) {
+
+	decode := func(data []byte) (interface{}, error) {
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		err := d.Decode(&v)
+		return v, err
+	}
+
+	v1, err := decode(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %v", err)
+	}
+	v2, err := decode(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %v", err)
+	}
+
+	return objects(format, v1, v2, opts)
+}
+
+// lines splits text into lines without their line endings.
+// A final line ending doesn't begin another line.
+func lines(text []byte) []string {
+	s := strings.TrimSuffix(string(text), "\n")
+	if s == "" {
+		return []string{}
+	}
+	ls := strings.Split(s, "\n")
+	for i, l := range ls {
+		ls[i] = strings.TrimSuffix(l, "\r")
+	}
+	return ls
+}
@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cases := []struct {
+		name    string
+		before  string
+		after   string
+		opts    []Option
+		want    []string
+		wantErr bool
+	}{
+		{
+			"a.json",
+			`{"port": 80, "id": 12345678901234567890}`,
+			`{"port": 443, "id": 12345678901234567890}`,
+			nil,
+			[]string{`["port"] changed from 80 to 443`},
+			false,
+		},
+		{
+			"a.YML",
+			"server:\n  port: 80\n",
+			"server:\n  port: 443\n",
+			nil,
+			[]string{`["server"]["port"] changed from 80 to 443`},
+			false,
+		},
+		{
+			"a.toml",
+			"[server]\nport = 80\n",
+			"[server]\nport = 443\n",
+			nil,
+			[]string{`["server"]["port"] changed from 80 to 443`},
+			false,
+		},
+		{
+			"a.txt",
+			"one\r\ntwo\r\nthree\r\n",
+			"one\nthree\nfour",
+			[]Option{WithMoves()},
+			[]string{
+				`[1] deleted "two"`,
+				`[2] added "four"`,
+			},
+			false,
+		},
+		{
+			"b.txt",
+			"",
+			"one\n",
+			nil,
+			[]string{`[0] added "one"`},
+			false,
+		},
+		{
+			"b.json",
+			`{"port": 80}`,
+			`{"port": `,
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for i, c := range cases {
+
+		path1 := write("before-"+c.name, c.before)
+		path2 := write("after-"+c.name, c.after)
+
+		got, err := Files(path1, path2, c.opts...)
+		if !equal(got, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Files(%q, %q)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, error %v",
+				c.before, c.after, got, err, c.want, c.wantErr)
+		}
+	}
+
+	if _, err := Files(filepath.Join(dir, "missing.json"), filepath.Join(dir, "b.json")); err == nil {
+		t.Errorf("Files with a missing file returned nil error")
+	}
+}
@@ -0,0 +1,64 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// bits returns the bits of v, an integer, as a uint64.
+func bits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Only the bits of v's own size are kept so
+		// that negative values don't set the rest.
+		return uint64(v.Int()) & (1<<(8*v.Type().Size()) - 1)
+	}
+	return v.Uint()
+}
+
+/*
+diffFlags reports the flags set in v2 but not v1 as added
+and those set in v1 but not v2 as deleted, naming each with
+names. A value that doesn't exist has no flags set.
+*/
+func (d *differ) diffFlags(v1, v2 *reflect.Value, names map[uint64]string) error {
+
+	var b1, b2 uint64
+	if v1 != nil {
+		b1 = bits(*v1)
+	}
+	if v2 != nil {
+		b2 = bits(*v2)
+	}
+
+	for i := 0; i < 64; i++ {
+
+		bit := uint64(1) << i
+		in1 := b1&bit != 0
+		in2 := b2&bit != 0
+		if !in1 && !in2 {
+			continue
+		}
+
+		name, ok := names[bit]
+		if !ok {
+			name = fmt.Sprintf("%#x", bit)
+		}
+		flag := reflect.ValueOf(verbatim(name))
+
+		if err := d.diffMember(in1, in2, &flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+type perm uint8
+
+const (
+	permRead perm = 1 << iota
+	permWrite
+	permExec
+	permAdmin
+)
+
+var permNames = map[perm]string{
+	permRead:  "Read",
+	permWrite: "Write",
+	permExec:  "Exec",
+	permAdmin: "Admin",
+}
+
+func TestWithFlags(t *testing.T) {
+
+	type user struct {
+		Name  string
+		Perms perm
+		Other map[string]perm
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []string
+	}{
+		{
+			user{"a", permAdmin, nil},
+			user{"a", permRead | permWrite, nil},
+			[]Option{WithFlags(permNames)},
+			[]string{
+				`.Perms added Read`,
+				`.Perms added Write`,
+				`.Perms deleted Admin`,
+			},
+		},
+		{
+			user{"a", permAdmin, nil},
+			user{"a", permRead | permWrite, nil},
+			nil,
+			[]string{
+				`.Perms changed from 8 to 3`,
+			},
+		},
+		{
+			user{"a", permRead, nil},
+			user{"a", permRead | 1<<6, map[string]perm{"x": permExec}},
+			[]Option{WithFlags(permNames)},
+			[]string{
+				`.Perms added 0x40`,
+				`.Other["x"] added Exec`,
+			},
+		},
+		{
+			user{"a", permRead | permExec, nil},
+			user{"a", permRead, nil},
+			[]Option{WithFlags(permNames), WithUnchanged()},
+			[]string{
+				`.Name unchanged "a"`,
+				`.Perms unchanged Read`,
+				`.Perms deleted Exec`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithFlagsPanics(t *testing.T) {
+
+	cases := []interface{}{
+		nil,
+		[]string{"a"},
+		map[string]string{"a": "b"},
+		map[perm]int{permRead: 1},
+		map[perm]string{permRead | permWrite: "ReadWrite"},
+		map[int]string{0: "None"},
+	}
+
+	for i, c := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					fmt.Printf("Case #%d:\n", i+1)
+					t.Errorf("WithFlags(%#v) didn't panic", c)
+				}
+			}()
+			WithFlags(c)
+		}()
+	}
+}
@@ -0,0 +1,37 @@
+package diff
+
+/*
+GroupByParent works the same as Diffs but groups the
+differences by the path of the struct, map, slice, or array
+containing them, rendered in the style chosen with
+WithPathStyle. Differences in the fields, elements, or
+entries of before and after themselves are grouped under the
+path of the root, which is "" by default. Within each group
+differences are in the order Diffs returns them.
+
+	groups, _ := diff.GroupByParent(before, after)
+	for parent, diffs := range groups {
+		fmt.Printf("%s: %d changes\n", parent, len(diffs))
+	}
+*/
+func GroupByParent(before, after interface{}, opts ...Option) (map[string][]Diff, error) {
+
+	diffs, err := Diffs(before, after, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	style := newOptions(opts).pathStyle
+
+	groups := make(map[string][]Diff)
+	for _, d := range diffs {
+		parent := d.Path
+		if len(parent) > 0 {
+			parent = parent[:len(parent)-1]
+		}
+		key := parent.Format(style)
+		groups[key] = append(groups[key], d)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,65 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGroupByParent(t *testing.T) {
+
+	type settings struct {
+		Theme string
+		Tags  []string
+	}
+	type user struct {
+		Name     string
+		Settings settings
+	}
+
+	before := user{"a", settings{"dark", []string{"x"}}}
+	after := user{"b", settings{"light", []string{"y", "z"}}}
+
+	cases := []struct {
+		opts []Option
+		want map[string][]string
+	}{
+		{
+			nil,
+			map[string][]string{
+				"":               {".Name"},
+				".Settings":      {".Settings.Theme"},
+				".Settings.Tags": {".Settings.Tags[0]", ".Settings.Tags[1]"},
+			},
+		},
+		{
+			[]Option{WithPathStyle(PathJSONPath)},
+			map[string][]string{
+				"$":               {"$.Name"},
+				"$.Settings":      {"$.Settings.Theme"},
+				"$.Settings.Tags": {"$.Settings.Tags[0]", "$.Settings.Tags[1]"},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		groups, err := GroupByParent(before, after, c.opts...)
+
+		got := map[string][]string{}
+		for parent, diffs := range groups {
+			for _, d := range diffs {
+				got[parent] = append(got[parent], d.Name)
+			}
+		}
+
+		if !reflect.DeepEqual(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"GroupByParent(%v, %v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
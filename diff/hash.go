@@ -0,0 +1,269 @@
+package diff
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+/*
+hashValue returns an FNV-1a hash of a canonical encoding of
+v. Equal values have equal hashes, including maps whose
+entries are iterated in different orders. Pointers, funcs,
+and channels are hashed by address, matching the way they
+are compared as leaves, so the hash never follows them.
+*/
+func hashValue(v reflect.Value) uint64 {
+	sum, _ := hashNodes(v, false)
+	return sum
+}
+
+/*
+hashNode holds the hashes of the structs, maps, slices,
+arrays, and interfaces within a value, as found by hashNodes,
+so that WithHashPruning can look them up as it descends
+rather than hashing each level anew. Leaves have no node.
+*/
+type hashNode struct {
+	sum uint64
+
+	// elem is the node of the value held by an interface.
+	elem *hashNode
+
+	// elems are the nodes of the elements of a slice or
+	// array, and children those of the fields of a struct
+	// or entries of a map, by their segments.
+	elems    []*hashNode
+	children map[Segment]*hashNode
+}
+
+/*
+child returns the node of the field, element, or entry at s
+within the value of n, or nil if there is none.
+*/
+func (n *hashNode) child(s Segment) *hashNode {
+
+	if n == nil {
+		return nil
+	}
+	n = n.value()
+
+	if s.Kind == IndexSegment {
+		if s.Index < len(n.elems) {
+			return n.elems[s.Index]
+		}
+		return nil
+	}
+	s.promoted = false
+
+	return n.children[s]
+}
+
+// value returns the node of the value held by n if n is
+// that of an interface, as the differ looks through them.
+func (n *hashNode) value() *hashNode {
+	for n.elem != nil {
+		n = n.elem
+	}
+	return n
+}
+
+/*
+hashNodes returns the hash of v along with, unless v is a
+leaf, the node holding it and those of everything within v.
+Each value is hashed once, its hash being made from those of
+the values within it, so hashing a whole tree takes time in
+proportion to its size. Fields are identified by number
+rather than name if protoNumbers is true, as they are by
+WithProtoFieldNumbers.
+*/
+func hashNodes(v reflect.Value, protoNumbers bool) (uint64, *hashNode) {
+
+	h := fnv.New64a()
+	writeUint(h, uint64(v.Kind()))
+
+	var n *hashNode
+
+	switch v.Kind() {
+	case reflect.Invalid:
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(h, 1)
+		} else {
+			writeUint(h, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(h, math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeUint(h, math.Float64bits(real(c)))
+		writeUint(h, math.Float64bits(imag(c)))
+	case reflect.String:
+		writeUint(h, uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Func, reflect.Chan:
+		writeUint(h, uint64(v.Pointer()))
+
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint(h, 0)
+			break
+		}
+		e := v.Elem()
+		sum, elem := hashNodes(e, protoNumbers)
+		h.Write([]byte(e.Type().String()))
+		writeUint(h, sum)
+		n = &hashNode{elem: elem}
+
+	case reflect.Struct:
+		fields := fieldsOf(v.Type())
+		val := addressable(&v, fields)
+		n = &hashNode{}
+		for _, fi := range fields {
+			sum, c := hashNodes(*structField(val, fi), protoNumbers)
+			writeUint(h, sum)
+			if c == nil {
+				continue
+			}
+			name := fi.name
+			if protoNumbers && fi.number > 0 {
+				name = strconv.Itoa(fi.number)
+			}
+			if n.children == nil {
+				n.children = map[Segment]*hashNode{}
+			}
+			n.children[fieldSegment(name)] = c
+		}
+
+	case reflect.Array, reflect.Slice:
+		writeUint(h, uint64(v.Len()))
+		n = &hashNode{}
+		for i := 0; i < v.Len(); i++ {
+			sum, c := hashNodes(v.Index(i), protoNumbers)
+			writeUint(h, sum)
+			if c == nil {
+				continue
+			}
+			if n.elems == nil {
+				n.elems = make([]*hashNode, v.Len())
+			}
+			n.elems[i] = c
+		}
+
+	case reflect.Map:
+		// Entries are combined by addition so that
+		// iteration order doesn't matter.
+		var total uint64
+		n = &hashNode{}
+		iter := v.MapRange()
+		for iter.Next() {
+			k, _ := hashNodes(iter.Key(), protoNumbers)
+			sum, c := hashNodes(iter.Value(), protoNumbers)
+			eh := fnv.New64a()
+			writeUint(eh, k)
+			writeUint(eh, sum)
+			total += eh.Sum64()
+			if c == nil {
+				continue
+			}
+			if n.children == nil {
+				n.children = map[Segment]*hashNode{}
+			}
+			n.children[keySegment(iter.Key().Interface())] = c
+		}
+		writeUint(h, uint64(v.Len()))
+		writeUint(h, total)
+	}
+
+	sum := h.Sum64()
+	if n != nil {
+		n.sum = sum
+	}
+
+	return sum, n
+}
+
+func writeUint(h hash.Hash64, n uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	h.Write(b[:])
+}
+
+/*
+hashPair holds the nodes of the values being diffed at one
+depth of the path for WithHashPruning, either of which may
+be nil if it is yet to be hashed.
+*/
+type hashPair [2]*hashNode
+
+/*
+sameHash reports whether v1 and v2 are data structures of
+the same type whose hashes match. The first data structures
+it's given are hashed whole, and the hashes of those within
+them are looked up as the differ descends, keeping track of
+them by the depth of the path, so that each value is only
+hashed once. Values produced by a transform are hashed anew
+as they are reached.
+*/
+func (d *differ) sameHash(v1, v2 *reflect.Value) bool {
+
+	if v1 == nil || v2 == nil || v1.Type() != v2.Type() {
+		return false
+	}
+	switch v1.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array, reflect.Slice:
+	default:
+		return false
+	}
+
+	depth := len(d.path)
+	for len(d.hashes) <= depth {
+		d.hashes = append(d.hashes, hashPair{})
+	}
+	p := &d.hashes[depth]
+	if p[0] == nil {
+		_, p[0] = hashNodes(*v1, d.opts.protoNumbers)
+	}
+	if p[1] == nil {
+		_, p[1] = hashNodes(*v2, d.opts.protoNumbers)
+	}
+
+	return p[0].value().sum == p[1].value().sum
+}
+
+/*
+descendHashes looks up the nodes of the values at the last
+segment of the path within those of the values above them,
+for sameHash.
+*/
+func (d *differ) descendHashes() {
+
+	depth := len(d.path)
+	if len(d.hashes) > depth {
+		d.hashes = d.hashes[:depth]
+	}
+	if len(d.hashes) < depth {
+		return
+	}
+
+	parent := d.hashes[depth-1]
+	s := d.path[depth-1]
+	d.hashes = append(d.hashes, hashPair{parent[0].child(s), parent[1].child(s)})
+}
+
+/*
+forgetHashes discards the nodes of the values at the current
+depth of the path, such as when a transform replaces them.
+*/
+func (d *differ) forgetHashes() {
+	if depth := len(d.path); len(d.hashes) > depth {
+		d.hashes = d.hashes[:depth]
+	}
+}
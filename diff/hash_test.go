@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHashValue(t *testing.T) {
+
+	type unexported struct {
+		n int
+		s []string
+	}
+
+	nan := math.NaN()
+	p := new(int)
+
+	cases := []struct {
+		a    interface{}
+		b    interface{}
+		want bool
+	}{
+		{config{true, "a", 1}, config{true, "a", 1}, true},
+		{config{true, "a", 1}, config{true, "a", 2}, false},
+		{unexported{1, []string{"x"}}, unexported{1, []string{"x"}}, true},
+		{unexported{1, []string{"x"}}, unexported{1, []string{"y"}}, false},
+		{map[string]int{"a": 1, "b": 2, "c": 3}, map[string]int{"c": 3, "b": 2, "a": 1}, true},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "b": 1}, false},
+		{[]interface{}{1}, []interface{}{int64(1)}, false},
+		{[]string{"ab", "c"}, []string{"a", "bc"}, false},
+		{[]float64{nan}, []float64{nan}, true},
+		{[]*int{p}, []*int{p}, true},
+		{[]*int{p}, []*int{new(int)}, false},
+	}
+
+	for i, c := range cases {
+		h1 := hashValue(reflect.ValueOf(c.a))
+		h2 := hashValue(reflect.ValueOf(c.b))
+		if got := h1 == h2; got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"hashValue(%v) == hashValue(%v)\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestWithHashPruning(t *testing.T) {
+
+	before := nestedTest{
+		Mapping: map[string][]string{
+			"a": []string{"1", "2"},
+			"b": []string{"3"},
+		},
+	}
+	after := nestedTest{
+		Mapping: map[string][]string{
+			"a": []string{"1", "2"},
+			"b": []string{"4"},
+		},
+	}
+	want := []string{`.Mapping["b"][0] changed from "3" to "4"`}
+
+	got, err := Objects(before, after, WithHashPruning())
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"Objects(%v, %v, WithHashPruning())\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			before, after, got, err, want)
+	}
+}
+
+/*
+BenchmarkHashPruning compares chains of nested values
+differing only at their ends, so that every level must be
+hashed and none pruned. As each value is hashed once, the
+time taken per level stays the same however deep the chain.
+Equal is used so that rendering the difference, whose name
+grows with the depth, isn't measured.
+*/
+func TestWithHashPruningOptions(t *testing.T) {
+
+	type pair struct {
+		A, B []int
+	}
+	type doc struct {
+		Pairs []pair
+		Any   interface{}
+		Items []string
+	}
+
+	// swap exchanges the fields of a pair, so the values
+	// diffed beneath it aren't those found at their paths.
+	swap := func(p pair) pair {
+		return pair{p.B, p.A}
+	}
+
+	before := doc{
+		Pairs: []pair{{[]int{1}, []int{2}}, {[]int{3}, []int{4}}},
+		Any:   pair{[]int{5}, nil},
+		Items: []string{"a", "b", "c"},
+	}
+	after := doc{
+		Pairs: []pair{{[]int{1}, []int{9}}, {[]int{3}, []int{4}}},
+		Any:   pair{[]int{5}, []int{6}},
+		Items: []string{"c", "b", "a"},
+	}
+
+	cases := [][]Option{
+		nil,
+		{WithTransform(swap)},
+		{WithMoves()},
+		{WithUnchanged()},
+	}
+
+	for i, opts := range cases {
+		want, wantErr := Objects(before, after, opts...)
+		got, err := Objects(before, after, append(opts, WithHashPruning())...)
+		if !equal(got, want) || err != nil || wantErr != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, ..., WithHashPruning())\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, %v",
+				before, after, got, err, want, wantErr)
+		}
+	}
+}
+
+func BenchmarkHashPruning(b *testing.B) {
+
+	type link struct {
+		N    int
+		Tags []string
+		Next []link
+	}
+
+	chain := func(depth, end int) link {
+		l := link{N: end}
+		for i := 1; i < depth; i++ {
+			l = link{N: i, Tags: []string{"a", "b"}, Next: []link{l}}
+		}
+		return l
+	}
+
+	for _, depth := range []int{100, 1000, 10000} {
+		before := chain(depth, 0)
+		after := chain(depth, 1)
+		b.Run(fmt.Sprint(depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Equal(before, after, WithHashPruning()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*depth), "ns/level")
+		})
+	}
+}
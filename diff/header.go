@@ -0,0 +1,190 @@
+package diff
+
+import (
+	"errors"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"time"
+)
+
+/*
+Headers returns the difference between two sets of HTTP or
+MIME headers, such as http.Header or textproto.MIMEHeader,
+in the same form as Objects. Header names are compared case
+insensitively and reported in their canonical form, so
+"content-type" and "Content-Type" are the same header.
+
+The values of a header are compared regardless of their
+order and reported at the path of the header rather than by
+index. A header with a single value that has been replaced
+is reported as changed:
+
+	["Content-Type"] changed from "text/plain" to "application/json"
+	["Accept"] added "text/html"
+
+Otherwise each value removed is reported as deleted and each
+value added as added.
+*/
+func Headers(before, after map[string][]string, opts ...Option) (changes []string, err error) {
+	return HeadersF(Format{}, before, after, opts...)
+}
+
+/*
+HeadersF works the same as Headers with an additional
+parameter allowing for custom formatting, as with ObjectsF.
+*/
+func HeadersF(format Format, before, after map[string][]string, opts ...Option) (changes []string, err error) {
+	canon := textproto.CanonicalMIMEHeaderKey
+	return multiValues(format, groupKeys(before, canon), groupKeys(after, canon), opts)
+}
+
+/*
+Query returns the difference between two sets of URL query
+parameters or form values, such as url.Values, as Headers
+does, except that keys are case sensitive.
+*/
+func Query(before, after map[string][]string, opts ...Option) (changes []string, err error) {
+	return QueryF(Format{}, before, after, opts...)
+}
+
+/*
+QueryF works the same as Query with an additional parameter
+allowing for custom formatting, as with ObjectsF.
+*/
+func QueryF(format Format, before, after map[string][]string, opts ...Option) (changes []string, err error) {
+	same := func(key string) string { return key }
+	return multiValues(format, groupKeys(before, same), groupKeys(after, same), opts)
+}
+
+/*
+groupKeys returns m with its keys replaced by canon(key).
+The values of keys with the same canonical form are joined
+in the order of the original keys.
+*/
+func groupKeys(m map[string][]string, canon func(string) string) map[string][]string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	grouped := make(map[string][]string, len(m))
+	for _, k := range keys {
+		c := canon(k)
+		grouped[c] = append(grouped[c], m[k]...)
+	}
+
+	return grouped
+}
+
+func multiValues(format Format, m1, m2 map[string][]string, opts []Option) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	d := differ{opts: o, templates: t}
+	err = d.truncated(d.safely(func() error {
+		return d.diffMultiValues(m1, m2)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if len(d.errs) > 0 {
+		return d.changes, errors.Join(d.errs...)
+	}
+
+	return d.changes, nil
+}
+
+func (d *differ) diffMultiValues(m1, m2 map[string][]string) error {
+
+	var keys []string
+	for k := range m1 {
+		keys = append(keys, k)
+	}
+	for k := range m2 {
+		if _, ok := m1[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		d.pushPath(keySegment(k))
+		err := d.diffValues(m1[k], m2[k])
+		if err != nil {
+			return err
+		}
+		d.popPath()
+	}
+
+	return nil
+}
+
+/*
+diffValues reports the values in vs1 but not vs2 as deleted
+and those in vs2 but not vs1 as added, counting repeated
+values. A single value replaced by another is a change.
+*/
+func (d *differ) diffValues(vs1, vs2 []string) error {
+
+	count := map[string]int{}
+	for _, v := range vs2 {
+		count[v]++
+	}
+
+	var kept, deleted []string
+	for _, v := range vs1 {
+		if count[v] > 0 {
+			count[v]--
+			kept = append(kept, v)
+		} else {
+			deleted = append(deleted, v)
+		}
+	}
+
+	var added []string
+	for _, v := range vs2 {
+		if count[v] > 0 {
+			count[v]--
+			added = append(added, v)
+		}
+	}
+
+	value := func(s string) *reflect.Value {
+		v := reflect.ValueOf(s)
+		return &v
+	}
+
+	if d.opts.unchanged {
+		for _, v := range kept {
+			if err := d.report(Diff{}, Same, value(v), value(v)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(deleted) == 1 && len(added) == 1 {
+		return d.report(Diff{}, Change, value(deleted[0]), value(added[0]))
+	}
+
+	for _, v := range deleted {
+		if err := d.report(Diff{}, Delete, value(v), nil); err != nil {
+			return err
+		}
+	}
+	for _, v := range added {
+		if err := d.report(Diff{}, Add, nil, value(v)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
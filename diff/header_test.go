@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+
+	cases := []struct {
+		before http.Header
+		after  http.Header
+		opts   []Option
+		want   []string
+	}{
+		{
+			http.Header{
+				"content-type": {"text/plain"},
+				"Accept":       {"text/html", "application/json"},
+				"X-Old":        {"1"},
+			},
+			http.Header{
+				"Content-Type": {"application/json"},
+				"accept":       {"application/json", "text/html", "text/xml"},
+				"X-New":        {"2", "3"},
+			},
+			nil,
+			[]string{
+				`["Accept"] added "text/xml"`,
+				`["Content-Type"] changed from "text/plain" to "application/json"`,
+				`["X-New"] added "2"`,
+				`["X-New"] added "3"`,
+				`["X-Old"] deleted "1"`,
+			},
+		},
+		{
+			http.Header{"X-A": {"1"}, "x-a": {"2"}},
+			http.Header{"X-A": {"2", "1"}},
+			nil,
+			nil,
+		},
+		{
+			http.Header{"Vary": {"a", "a", "b"}},
+			http.Header{"Vary": {"a", "c", "d"}},
+			[]Option{WithUnchanged()},
+			[]string{
+				`["Vary"] unchanged "a"`,
+				`["Vary"] deleted "a"`,
+				`["Vary"] deleted "b"`,
+				`["Vary"] added "c"`,
+				`["Vary"] added "d"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Headers(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Headers(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+
+	before := url.Values{"q": {"go"}, "Page": {"1"}}
+	after := url.Values{"q": {"go"}, "page": {"1"}}
+	want := []string{
+		`["Page"] deleted "1"`,
+		`["page"] added "1"`,
+	}
+
+	got, err := Query(before, after)
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"Query(%v, %v)\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			before, after, got, err, want)
+	}
+}
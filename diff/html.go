@@ -0,0 +1,43 @@
+package diff
+
+import (
+	htmltemplate "html/template"
+)
+
+type htmlTemplates struct {
+	*htmltemplate.Template
+}
+
+func (t htmlTemplates) has(name string) bool {
+	return t.Lookup(name) != nil
+}
+
+/*
+parseHTMLTemplates works the same as parseTemplates but
+parses the templates of format with html/template. See
+WithHTMLEscaping.
+*/
+func parseHTMLTemplates(format Format, ds delims) (templateSet, error) {
+
+	t := htmltemplate.New("change").
+		Funcs(htmltemplate.FuncMap(builtinFuncs)).
+		Funcs(htmltemplate.FuncMap(format.Funcs))
+
+	for _, nt := range namedTemplates(format) {
+
+		if nt.text == "" && (nt.name == "set" || nt.name == "clear") {
+			continue
+		}
+
+		tt := t
+		if nt.name != t.Name() {
+			tt = t.New(nt.name)
+		}
+		d := ds[nt.name]
+		if _, err := tt.Delims(d[0], d[1]).Parse(nt.text); err != nil {
+			return nil, err
+		}
+	}
+
+	return htmlTemplates{t}, nil
+}
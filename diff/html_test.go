@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithHTMLEscaping(t *testing.T) {
+
+	type profile struct {
+		Bio  string
+		Site string
+	}
+
+	before := profile{"hi", "https://example.com"}
+	after := profile{"<script>alert(1)</script>", "javascript:alert(1)"}
+
+	cases := []struct {
+		format Format
+		opts   []Option
+		want   []string
+	}{
+		{
+			Format{Change: `<li>{{.Name}}: {{.After}}</li>`},
+			[]Option{WithHTMLEscaping()},
+			[]string{
+				`<li>.Bio: &#34;&lt;script&gt;alert(1)&lt;/script&gt;&#34;</li>`,
+				`<li>.Site: &#34;javascript:alert(1)&#34;</li>`,
+			},
+		},
+		{
+			Format{Change: `<a href="{{.After}}">{{.Name}}</a>`},
+			[]Option{WithHTMLEscaping(), WithValueFormatter(func(path string, v interface{}) string {
+				return fmt.Sprint(v)
+			})},
+			[]string{
+				`<a href="%3cscript%3ealert%281%29%3c/script%3e">.Bio</a>`,
+				`<a href="#ZgotmplZ">.Site</a>`,
+			},
+		},
+		{
+			Format{},
+			[]Option{WithHTMLEscaping()},
+			[]string{
+				`.Bio changed from &#34;hi&#34; to &#34;&lt;script&gt;alert(1)&lt;/script&gt;&#34;`,
+				`.Site changed from &#34;https://example.com&#34; to &#34;javascript:alert(1)&#34;`,
+			},
+		},
+		{
+			Format{Change: `<li>{{.After}}</li>`},
+			nil,
+			[]string{
+				`<li>"<script>alert(1)</script>"</li>`,
+				`<li>"javascript:alert(1)"</li>`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(c.format, before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.format, before, after, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+/*
+builtinFuncs are the functions available to every template,
+in addition to those given in Format.Funcs, which may replace
+them:
+
+  - humanBytes renders a number of bytes in binary units,
+    such as "1.5 KiB",
+  - humanDuration renders a number of nanoseconds, such as a
+    time.Duration, in the form "1m30.25s", rounded to three
+    decimal places of its largest unit below an hour,
+  - humanNumber renders a number with its thousands separated
+    by commas, such as "1,234,567.5".
+
+Each accepts any integer or floating point value and renders
+anything else with fmt.Sprint, so they may be applied to
+Before and After whatever their types.
+*/
+var builtinFuncs = template.FuncMap{
+	"humanBytes":    humanBytes,
+	"humanDuration": humanDuration,
+	"humanNumber":   humanNumber,
+}
+
+func humanBytes(v interface{}) string {
+	f, ok := toFloat(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if f < 0 {
+		return "-" + byteSize(int64(-f))
+	}
+	return byteSize(int64(f))
+}
+
+func humanDuration(v interface{}) string {
+
+	f, ok := toFloat(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+
+	d := time.Duration(f)
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+
+	switch {
+	case abs >= time.Second:
+		d = d.Round(time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(time.Microsecond)
+	}
+
+	return d.String()
+}
+
+func humanNumber(v interface{}) string {
+
+	var s string
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits())
+	default:
+		return fmt.Sprint(v)
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteString(frac)
+
+	return sb.String()
+}
+
+// toFloat returns v as a float64 if it is an integer or
+// floating point number.
+func toFloat(v interface{}) (float64, bool) {
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+
+	cases := []struct {
+		fn   func(interface{}) string
+		name string
+		in   interface{}
+		want string
+	}{
+		{humanBytes, "humanBytes", 512, "512 B"},
+		{humanBytes, "humanBytes", uint64(1536), "1.5 KiB"},
+		{humanBytes, "humanBytes", int64(-3 << 20), "-3.0 MiB"},
+		{humanBytes, "humanBytes", `"big"`, `"big"`},
+		{humanDuration, "humanDuration", int64(1500), "1.5µs"},
+		{humanDuration, "humanDuration", 2*time.Millisecond + 345678, "2.346ms"},
+		{humanDuration, "humanDuration", 90*time.Second + 250*time.Millisecond + 7, "1m30.25s"},
+		{humanDuration, "humanDuration", -time.Hour, "-1h0m0s"},
+		{humanDuration, "humanDuration", true, "true"},
+		{humanNumber, "humanNumber", 999, "999"},
+		{humanNumber, "humanNumber", 1234567, "1,234,567"},
+		{humanNumber, "humanNumber", int8(-100), "-100"},
+		{humanNumber, "humanNumber", -1234.5, "-1,234.5"},
+		{humanNumber, "humanNumber", uint(1000), "1,000"},
+		{humanNumber, "humanNumber", "1000", "1000"},
+	}
+
+	for i, c := range cases {
+		if got := c.fn(c.in); got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"%s(%v)\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.name, c.in, got, c.want)
+		}
+	}
+}
+
+func TestHumanizeTemplates(t *testing.T) {
+
+	type upload struct {
+		Size    int64
+		Elapsed time.Duration
+		Rows    int
+	}
+
+	before := upload{2048, 1500 * time.Millisecond, 1000}
+	after := upload{5 << 20, 61 * time.Second, 2500000}
+
+	format := Format{
+		Change: `{{.Name}} {{if eq .Name ".Size"}}{{humanBytes .After}}` +
+			`{{else if eq .Name ".Elapsed"}}{{humanDuration .After}}` +
+			`{{else}}{{humanNumber .After}}{{end}}`,
+	}
+	want := []string{
+		`.Size 5.0 MiB`,
+		`.Elapsed 1m1s`,
+		`.Rows 2,500,000`,
+	}
+
+	for _, opts := range [][]Option{nil, {WithHTMLEscaping()}} {
+		got, err := ObjectsF(format, before, after, opts...)
+		if !equal(got, want) || err != nil {
+			t.Errorf(
+				"ObjectsF(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				format, before, after, got, err, want)
+		}
+	}
+}
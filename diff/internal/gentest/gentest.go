@@ -0,0 +1,49 @@
+/*
+Package gentest holds types with diff functions generated by
+cmd/diffgen so that their output can be checked against
+diff.ObjectsF.
+*/
+package gentest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+//go:generate go run ../../../cmd/diffgen -type Config,Limits
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte("L" + strconv.Itoa(int(l))), nil
+}
+
+// cents is compared by the differ as the value it gives a
+// database driver.
+type cents int64
+
+func (c cents) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", c/100, c%100), nil
+}
+
+type Config struct {
+	Name     string
+	Debug    bool
+	Level    level
+	Password string `diff:"redact"`
+	Limits   Limits
+	Tags     []string
+	Timeout  time.Duration
+	Started  time.Time
+	Extra    interface{}
+	Price    cents
+	secret   string
+}
+
+type Limits struct {
+	Conns int
+	Rate  float64
+	Users map[string]int
+}
@@ -0,0 +1,152 @@
+package gentest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jakebowkett/go-diff/diff"
+)
+
+func TestGenerated(t *testing.T) {
+
+	base := Config{
+		Name:     "a",
+		Level:    1,
+		Password: "x",
+		Limits:   Limits{Conns: 1, Users: map[string]int{"bob": 1}},
+		Tags:     []string{"a", "b"},
+		Timeout:  time.Second,
+		Extra:    1,
+		Price:    150,
+		secret:   "s",
+	}
+
+	cases := []struct {
+		before Config
+		after  Config
+		format diff.Format
+		opts   []diff.Option
+	}{
+		{base, base, diff.Format{}, nil},
+		{
+			base,
+			Config{
+				Name:     "b",
+				Debug:    true,
+				Level:    2,
+				Password: "y",
+				Limits:   Limits{Conns: 2, Rate: 0.5, Users: map[string]int{"bob": 2}},
+				Tags:     []string{"a"},
+				Timeout:  time.Minute,
+				Started:  time.Unix(0, 0),
+				Extra:    "one",
+				Price:    1999,
+				secret:   "t",
+			},
+			diff.Format{Change: "{{.Name}}: {{.Before}} -> {{.After}}"},
+			nil,
+		},
+		{
+			Config{Name: "a  b"},
+			Config{Name: " a b", Tags: []string{"c"}},
+			diff.Format{},
+			[]diff.Option{
+				diff.WithNormalizedWhitespace(),
+				diff.WithKinds(diff.Add),
+			},
+		},
+		{
+			Config{Name: "a", Tags: []string{"b"}},
+			Config{Name: "c", Tags: []string{"b"}},
+			diff.Format{},
+			[]diff.Option{diff.WithUnchanged()},
+		},
+	}
+
+	for i, c := range cases {
+
+		want, wantErr := diff.ObjectsF(c.format, c.before, c.after, c.opts...)
+		got, err := DiffConfig(c.format, c.before, c.after, c.opts...)
+
+		if !reflect.DeepEqual(got, want) || err != wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"DiffConfig(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, %v",
+				c.format, c.before, c.after, got, err, want, wantErr)
+		}
+	}
+}
+
+func TestGeneratedOptions(t *testing.T) {
+
+	before := Config{
+		Name:     "a",
+		Level:    1,
+		Password: "x",
+		Limits:   Limits{Conns: 1, Users: map[string]int{"bob": 1}},
+		Tags:     []string{"a", "b"},
+		Timeout:  time.Second,
+		Extra:    1,
+		Price:    150,
+		secret:   "s",
+	}
+	after := Config{
+		Name:     "b",
+		Debug:    true,
+		Level:    3,
+		Password: "y",
+		Limits:   Limits{Conns: 2, Rate: 0.5, Users: map[string]int{"bob": 2}},
+		Tags:     []string{"a"},
+		Timeout:  time.Minute,
+		Extra:    "one",
+		Price:    150,
+		secret:   "t",
+	}
+
+	cases := [][]diff.Option{
+		nil,
+		{diff.WithMarshalers()},
+		{diff.WithLeafTypes(Limits{})},
+		{diff.WithHashPruning()},
+		{diff.WithJSONRoundTrip()},
+		{diff.WithPathStyle(diff.PathJSONPointer)},
+		{diff.WithPathFormat(".Limits.*", diff.Format{Change: "{{.Name}}: {{.After}}"})},
+		{diff.WithRedactPlaceholder("***"), diff.WithQuotedValues()},
+		{diff.WithKinds(diff.Delete), diff.WithUnchanged()},
+		{diff.WithTransform(strings.ToUpper)},
+		{diff.WithTransform(func(l Limits) int { return l.Conns })},
+		{diff.WithTransform(func(c Config) string { return c.Name })},
+		{diff.WithFlags(map[level]string{1: "One", 2: "Two"})},
+		{diff.WithFlags(map[level]string{1: "One", 2: "Two"}), diff.WithMarshalers()},
+		{diff.WithSortedFields()},
+		{diff.WithSortedFields(), diff.WithUnchanged()},
+		{diff.WithFields(".Name", ".Limits.Conns")},
+		{diff.WithFields(".Limits", ".Tags[*]"), diff.WithUnchanged()},
+		{diff.WithFields(".Password", ".Extra")},
+		{diff.WithLimits(diff.Limits{MaxNodes: 4})},
+		{diff.WithLimits(diff.Limits{MaxNodes: 9, Truncate: true})},
+		{diff.WithLimits(diff.Limits{MaxBytes: 80})},
+		{diff.WithLimits(diff.Limits{MaxBytes: 80, Truncate: true})},
+		{diff.WithLimits(diff.Limits{MaxNodes: 12}), diff.WithUnchanged()},
+	}
+
+	for i, opts := range cases {
+
+		want, wantErr := diff.ObjectsF(diff.Format{}, before, after, opts...)
+		got, err := DiffConfig(diff.Format{}, before, after, opts...)
+
+		if !reflect.DeepEqual(got, want) || fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"DiffConfig(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, %v",
+				diff.Format{}, before, after, got, err, want, wantErr)
+		}
+	}
+}
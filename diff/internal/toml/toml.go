@@ -0,0 +1,718 @@
+/*
+Package toml decodes TOML documents into generic Go values
+so that they may be diffed. It supports the whole of TOML
+1.0 apart from the enforcement of some of its rules on
+redefining tables.
+
+Tables decode to map[string]interface{}, arrays to
+[]interface{}, strings to string, integers to int64, floats
+to float64, and booleans to bool. Dates and times of every
+kind decode to Datetime.
+*/
+package toml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Datetime holds an offset date-time, local date-time, local
+date, or local time exactly as it was written in the
+document, so that it is rendered the same way it was
+written.
+*/
+type Datetime string
+
+/*
+Unmarshal parses data as a TOML document and returns its
+root table.
+*/
+func Unmarshal(data []byte) (map[string]interface{}, error) {
+
+	root := map[string]interface{}{}
+	p := parser{
+		src:     string(data),
+		line:    1,
+		root:    root,
+		current: root,
+		tables:  map[string]bool{},
+		arrays:  map[string]bool{},
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+
+	root    map[string]interface{}
+	current map[string]interface{}
+
+	// Paths of tables defined with a header and of
+	// arrays defined with array-of-tables headers.
+	tables map[string]bool
+	arrays map[string]bool
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func (p *parser) advance(n int) {
+	for i := 0; i < n && !p.eof(); i++ {
+		if p.src[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+// skipSpace skips spaces and tabs.
+func (p *parser) skipSpace() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.pos++
+	}
+}
+
+func (p *parser) skipComment() {
+	if p.peek() != '#' {
+		return
+	}
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, newlines, and comments.
+func (p *parser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		switch {
+		case p.hasPrefix("\n"):
+			p.advance(1)
+		case p.hasPrefix("\r\n"):
+			p.advance(2)
+		default:
+			return
+		}
+	}
+}
+
+// endLine consumes the remainder of a line, which may
+// only contain whitespace and a comment.
+func (p *parser) endLine() error {
+	p.skipSpace()
+	p.skipComment()
+	switch {
+	case p.eof():
+		return nil
+	case p.hasPrefix("\n"):
+		p.advance(1)
+		return nil
+	case p.hasPrefix("\r\n"):
+		p.advance(2)
+		return nil
+	}
+	return p.errorf("unexpected %q after value", p.peek())
+}
+
+func (p *parser) parse() error {
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+
+		var err error
+		switch {
+		case p.hasPrefix("[["):
+			err = p.parseArrayTable()
+		case p.hasPrefix("["):
+			err = p.parseTable()
+		default:
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseTable() error {
+
+	p.advance(1)
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return p.errorf("expected ] to close table header")
+	}
+	p.advance(1)
+
+	id := strings.Join(keys, "\x00")
+	if p.tables[id] {
+		return p.errorf("table %q defined more than once", strings.Join(keys, "."))
+	}
+	p.tables[id] = true
+
+	t, err := p.descend(p.root, keys)
+	if err != nil {
+		return err
+	}
+	p.current = t
+
+	return nil
+}
+
+func (p *parser) parseArrayTable() error {
+
+	p.advance(2)
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if !p.hasPrefix("]]") {
+		return p.errorf("expected ]] to close array of tables header")
+	}
+	p.advance(2)
+
+	parent, err := p.descend(p.root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+	id := strings.Join(keys, "\x00")
+	t := map[string]interface{}{}
+
+	switch v := parent[last].(type) {
+	case nil:
+		parent[last] = []interface{}{t}
+		p.arrays[id] = true
+	case []interface{}:
+		if !p.arrays[id] {
+			return p.errorf("cannot append to static array %q", last)
+		}
+		parent[last] = append(v, t)
+	default:
+		return p.errorf("key %q is already defined", last)
+	}
+
+	// Sub-tables of each element of an array of tables
+	// may be defined afresh.
+	for k := range p.tables {
+		if strings.HasPrefix(k, id+"\x00") {
+			delete(p.tables, k)
+		}
+	}
+
+	p.current = t
+
+	return nil
+}
+
+/*
+descend walks keys from t, creating tables as needed, and
+returns the table at the end. An array of tables is
+descended into by way of its last element.
+*/
+func (p *parser) descend(t map[string]interface{}, keys []string) (map[string]interface{}, error) {
+
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			t[k] = next
+			t = next
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, p.errorf("key %q is not a table", k)
+			}
+			last, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("key %q is not a table", k)
+			}
+			t = last
+		default:
+			return nil, p.errorf("key %q is not a table", k)
+		}
+	}
+
+	return t, nil
+}
+
+func (p *parser) parseKeyValue(t map[string]interface{}) error {
+
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != '=' {
+		return p.errorf("expected = after key")
+	}
+	p.advance(1)
+	p.skipSpace()
+
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	t, err = p.descend(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := t[last]; ok {
+		return p.errorf("key %q is already defined", last)
+	}
+	t[last] = v
+
+	return nil
+}
+
+// parseKey parses a possibly dotted key.
+func (p *parser) parseKey() ([]string, error) {
+
+	var keys []string
+
+	for {
+		k, err := p.parseSimpleKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.advance(1)
+		p.skipSpace()
+	}
+}
+
+func (p *parser) parseSimpleKey() (string, error) {
+
+	switch p.peek() {
+	case '"':
+		return p.parseBasicString()
+	case '\'':
+		return p.parseLiteralString()
+	}
+
+	start := p.pos
+	for !p.eof() && isBareKeyChar(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.eof() {
+			return "", p.errorf("expected key, found end of document")
+		}
+		return "", p.errorf("expected key, found %q", p.peek())
+	}
+
+	return p.src[start:p.pos], nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' ||
+		'a' <= c && c <= 'z' ||
+		'A' <= c && c <= 'Z' ||
+		'0' <= c && c <= '9'
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+
+	switch {
+	case p.eof():
+		return nil, p.errorf("expected value, found end of document")
+	case p.hasPrefix(`"""`):
+		return p.parseMultiLineBasicString()
+	case p.hasPrefix(`'''`):
+		return p.parseMultiLineLiteralString()
+	case p.hasPrefix(`"`):
+		return p.parseBasicString()
+	case p.hasPrefix(`'`):
+		return p.parseLiteralString()
+	case p.hasPrefix("["):
+		return p.parseArray()
+	case p.hasPrefix("{"):
+		return p.parseInlineTable()
+	}
+
+	return p.parseScalar()
+}
+
+func (p *parser) parseBasicString() (string, error) {
+
+	p.advance(1)
+	var sb strings.Builder
+
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.advance(1)
+			return sb.String(), nil
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) parseMultiLineBasicString() (string, error) {
+
+	p.advance(3)
+	p.trimNewline()
+	var sb strings.Builder
+
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if p.hasPrefix(`"""`) {
+			// Up to two quotes may directly precede
+			// the closing delimiter.
+			extra := 0
+			for extra < 2 && strings.HasPrefix(p.src[p.pos+3+extra:], `"`) {
+				extra++
+			}
+			sb.WriteString(strings.Repeat(`"`, extra))
+			p.advance(3 + extra)
+			return sb.String(), nil
+		}
+
+		c := p.peek()
+		if c != '\\' {
+			sb.WriteByte(c)
+			p.advance(1)
+			continue
+		}
+
+		// A backslash at the end of a line trims
+		// all whitespace up to the next non-blank.
+		rest := p.src[p.pos+1:]
+		trimmed := strings.TrimLeft(rest, " \t")
+		if strings.HasPrefix(trimmed, "\n") || strings.HasPrefix(trimmed, "\r\n") {
+			p.advance(1)
+			for c := p.peek(); c == ' ' || c == '\t' || c == '\n' || c == '\r'; c = p.peek() {
+				p.advance(1)
+			}
+			continue
+		}
+
+		if err := p.parseEscape(&sb); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+
+	p.advance(1)
+	start := p.pos
+
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.peek() == '\'' {
+			s := p.src[start:p.pos]
+			p.advance(1)
+			return s, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseMultiLineLiteralString() (string, error) {
+
+	p.advance(3)
+	p.trimNewline()
+	start := p.pos
+
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if p.hasPrefix(`'''`) {
+			extra := 0
+			for extra < 2 && strings.HasPrefix(p.src[p.pos+3+extra:], `'`) {
+				extra++
+			}
+			s := p.src[start : p.pos+extra]
+			p.advance(3 + extra)
+			return s, nil
+		}
+		p.advance(1)
+	}
+}
+
+// trimNewline skips a newline directly following the
+// opening delimiter of a multi-line string.
+func (p *parser) trimNewline() {
+	switch {
+	case p.hasPrefix("\n"):
+		p.advance(1)
+	case p.hasPrefix("\r\n"):
+		p.advance(2)
+	}
+}
+
+func (p *parser) parseEscape(sb *strings.Builder) error {
+
+	p.advance(1)
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+
+	c := p.peek()
+	p.advance(1)
+
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte('\x1b')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("short unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape %q", p.src[p.pos:p.pos+n])
+		}
+		sb.WriteRune(rune(code))
+		p.advance(n)
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+
+	return nil
+}
+
+func (p *parser) parseArray() ([]interface{}, error) {
+
+	p.advance(1)
+	arr := []interface{}{}
+
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.advance(1)
+			return arr, nil
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.advance(1)
+		case ']':
+			p.advance(1)
+			return arr, nil
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *parser) parseInlineTable() (map[string]interface{}, error) {
+
+	p.advance(1)
+	t := map[string]interface{}{}
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.advance(1)
+		return t, nil
+	}
+
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.advance(1)
+		case '}':
+			p.advance(1)
+			return t, nil
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// parseScalar parses booleans, numbers, and datetimes.
+func (p *parser) parseScalar() (interface{}, error) {
+
+	start := p.pos
+	for !p.eof() && isScalarChar(p.peek()) {
+		p.pos++
+	}
+
+	// Dates may be separated from times by a space.
+	if isDate(p.src[start:p.pos]) && p.pos+1 < len(p.src) &&
+		p.src[p.pos] == ' ' && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		for !p.eof() && isScalarChar(p.peek()) {
+			p.pos++
+		}
+	}
+
+	tok := p.src[start:p.pos]
+
+	switch tok {
+	case "":
+		return nil, p.errorf("expected value, found %q", p.peek())
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	if isDate(tok) || isTime(tok) {
+		return Datetime(tok), nil
+	}
+
+	return p.parseNumber(tok)
+}
+
+func (p *parser) parseNumber(tok string) (interface{}, error) {
+
+	if strings.Contains(tok, "__") || strings.HasPrefix(tok, "_") || strings.HasSuffix(tok, "_") {
+		return nil, p.errorf("invalid number %q", tok)
+	}
+	num := strings.Replace(tok, "_", "", -1)
+
+	if len(num) > 2 && num[0] == '0' && strings.ContainsRune("xob", rune(num[1])) {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[num[1]]
+		i, err := strconv.ParseInt(num[2:], base, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %q", tok)
+		}
+		return i, nil
+	}
+
+	digits := strings.TrimLeft(num, "+-")
+	if len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		return nil, p.errorf("leading zeros are not allowed in %q", tok)
+	}
+
+	if strings.ContainsAny(num, ".eE") {
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float %q", tok)
+		}
+		return f, nil
+	}
+
+	i, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return nil, p.errorf("invalid value %q", tok)
+	}
+	return i, nil
+}
+
+func isScalarChar(c byte) bool {
+	return isBareKeyChar(c) || c == '+' || c == '.' || c == ':'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isDate reports whether s begins with YYYY-MM-DD.
+func isDate(s string) bool {
+	return len(s) >= 10 &&
+		isDigit(s[0]) && isDigit(s[1]) && isDigit(s[2]) && isDigit(s[3]) &&
+		s[4] == '-' && isDigit(s[5]) && isDigit(s[6]) &&
+		s[7] == '-' && isDigit(s[8]) && isDigit(s[9])
+}
+
+// isTime reports whether s begins with HH:MM.
+func isTime(s string) bool {
+	return len(s) >= 5 &&
+		isDigit(s[0]) && isDigit(s[1]) && s[2] == ':' &&
+		isDigit(s[3]) && isDigit(s[4])
+}
@@ -0,0 +1,176 @@
+package toml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+
+	cases := []struct {
+		doc     string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		// Scalars.
+		{
+			`
+# A comment.
+title = "TOML \"Example\"" # Trailing comment.
+literal = 'C:\Users'
+int = +1_000
+hex = 0xdead_beef
+neg = -17
+float = 6.626e-34
+bool = true
+date = 1979-05-27
+odt = 1979-05-27 07:32:00Z
+time = 07:32:00.999
+`,
+			map[string]interface{}{
+				"title":   `TOML "Example"`,
+				"literal": `C:\Users`,
+				"int":     int64(1000),
+				"hex":     int64(0xdeadbeef),
+				"neg":     int64(-17),
+				"float":   6.626e-34,
+				"bool":    true,
+				"date":    Datetime("1979-05-27"),
+				"odt":     Datetime("1979-05-27 07:32:00Z"),
+				"time":    Datetime("07:32:00.999"),
+			},
+			false,
+		},
+
+		// Multi-line strings.
+		{
+			"a = \"\"\"\none\\\n    two\"\"\"\nb = '''\nraw\\n'''",
+			map[string]interface{}{
+				"a": "onetwo",
+				"b": `raw\n`,
+			},
+			false,
+		},
+
+		// Tables, dotted keys, and inline tables.
+		{
+			`
+[server]
+host = "localhost"
+tls.enabled = true
+
+[server.limits]
+conns = { max = 10, "idle" = 2 }
+`,
+			map[string]interface{}{
+				"server": map[string]interface{}{
+					"host": "localhost",
+					"tls": map[string]interface{}{
+						"enabled": true,
+					},
+					"limits": map[string]interface{}{
+						"conns": map[string]interface{}{
+							"max":  int64(10),
+							"idle": int64(2),
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		// Arrays and arrays of tables.
+		{
+			`
+ports = [
+	80,
+	443, # https
+]
+
+[[fruit]]
+name = "apple"
+
+[fruit.colour]
+main = "red"
+
+[[fruit]]
+name = "banana"
+`,
+			map[string]interface{}{
+				"ports": []interface{}{int64(80), int64(443)},
+				"fruit": []interface{}{
+					map[string]interface{}{
+						"name": "apple",
+						"colour": map[string]interface{}{
+							"main": "red",
+						},
+					},
+					map[string]interface{}{
+						"name": "banana",
+					},
+				},
+			},
+			false,
+		},
+
+		// Duplicate keys.
+		{
+			"a = 1\na = 2",
+			nil,
+			true,
+		},
+
+		// Duplicate tables.
+		{
+			"[a]\n[a]",
+			nil,
+			true,
+		},
+
+		// Leading zeros.
+		{
+			"a = 0755",
+			nil,
+			true,
+		},
+
+		// Missing value.
+		{
+			"a = ",
+			nil,
+			true,
+		},
+
+		// Garbage after value.
+		{
+			`a = "b" c`,
+			nil,
+			true,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Unmarshal([]byte(c.doc))
+		if !reflect.DeepEqual(got, c.want) && !c.wantErr || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Unmarshal(%q)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, error: %v",
+				c.doc, got, err, c.want, c.wantErr)
+		}
+	}
+}
+
+func TestUnmarshalSpecialFloats(t *testing.T) {
+
+	got, err := Unmarshal([]byte("a = inf\nb = -inf\nc = nan"))
+	if err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if got["a"] != math.Inf(1) || got["b"] != math.Inf(-1) || !math.IsNaN(got["c"].(float64)) {
+		t.Errorf("Unmarshal returned %v, wanted +Inf, -Inf, and NaN", got)
+	}
+}
@@ -0,0 +1,836 @@
+/*
+Package yaml decodes YAML documents into generic Go values
+so that they may be diffed. It supports the subset of YAML
+1.2 commonly used for configuration files: block mappings
+and sequences, flow mappings and sequences, plain, quoted,
+literal, and folded scalars, and comments.
+
+Anchors, aliases, tags, complex keys, and streams of more
+than one document are not supported and cause an error
+rather than being decoded incorrectly.
+
+Mappings decode to map[string]interface{}, sequences to
+[]interface{}, and scalars to string, int64, float64, bool,
+or nil following the YAML 1.2 core schema. Keys are always
+decoded to strings as they were written.
+*/
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Unmarshal parses data as a YAML document and returns its
+root node. An empty document decodes to nil.
+*/
+func Unmarshal(data []byte) (interface{}, error) {
+
+	p := parser{}
+	if err := p.split(string(data)); err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if p.eof() {
+		return nil, nil
+	}
+
+	v, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.cur().text)
+	}
+
+	return v, nil
+}
+
+// line is a line of the document with its indentation removed.
+type line struct {
+	num    int
+	indent int
+	text   string
+	raw    string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	num := 0
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	} else if len(p.lines) > 0 {
+		num = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("yaml: line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.lines)
+}
+
+func (p *parser) cur() *line {
+	return &p.lines[p.pos]
+}
+
+/*
+split breaks src into lines, checking for indentation with
+tabs and removing the document markers that may surround a
+single document.
+*/
+func (p *parser) split(src string) error {
+
+	src = strings.TrimPrefix(src, "\ufeff")
+	src = strings.Replace(src, "\r\n", "\n", -1)
+
+	started := false
+	for i, raw := range strings.Split(src, "\n") {
+
+		text := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(text)
+
+		if strings.HasPrefix(text, "\t") && strings.TrimSpace(text) != "" {
+			return fmt.Errorf("yaml: line %d: tabs can't be used for indentation", i+1)
+		}
+
+		if indent == 0 && (text == "---" || strings.HasPrefix(text, "--- ")) {
+			if started {
+				return fmt.Errorf("yaml: line %d: multiple documents are not supported", i+1)
+			}
+			started = true
+			text = strings.TrimSpace(strings.TrimPrefix(text, "---"))
+			if text == "" {
+				continue
+			}
+			raw = text
+		}
+		if indent == 0 && text == "..." {
+			break
+		}
+		if indent == 0 && strings.HasPrefix(text, "%") {
+			return fmt.Errorf("yaml: line %d: directives are not supported", i+1)
+		}
+
+		if !started && strings.TrimSpace(stripComment(text)) != "" {
+			started = true
+		}
+
+		p.lines = append(p.lines, line{
+			num:    i + 1,
+			indent: indent,
+			text:   text,
+			raw:    raw,
+		})
+	}
+
+	return nil
+}
+
+// blank reports whether l holds nothing but whitespace and comments.
+func (l *line) blank() bool {
+	return strings.TrimSpace(stripComment(l.text)) == ""
+}
+
+func (p *parser) skipBlank() {
+	for !p.eof() && p.cur().blank() {
+		p.pos++
+	}
+}
+
+/*
+parseNode parses the block node starting at the current line,
+which must be indented by at least indent spaces.
+*/
+func (p *parser) parseNode(indent int) (interface{}, error) {
+
+	p.skipBlank()
+	if p.eof() || p.cur().indent < indent {
+		return nil, nil
+	}
+
+	l := p.cur()
+	if isSeqEntry(l.text) {
+		return p.parseSeq(l.indent)
+	}
+	if _, _, ok, err := splitKey(l.text); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.parseMap(l.indent)
+	}
+
+	return p.parseValue(l.indent-1, l.text)
+}
+
+func isSeqEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "-\t")
+}
+
+func (p *parser) parseSeq(indent int) (interface{}, error) {
+
+	seq := []interface{}{}
+
+	for {
+		p.skipBlank()
+		if p.eof() || p.cur().indent != indent || !isSeqEntry(p.cur().text) {
+			break
+		}
+
+		l := p.cur()
+		rest := strings.TrimLeft(l.text[1:], " \t")
+		offset := len(l.text) - len(rest)
+
+		var v interface{}
+		var err error
+		if strings.TrimSpace(stripComment(rest)) == "" {
+			p.pos++
+			v, err = p.parseNode(indent + 1)
+		} else {
+			// The entry's content is parsed as if it
+			// started on a line of its own.
+			l.indent += offset
+			l.text = rest
+			v, err = p.parseNode(l.indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		seq = append(seq, v)
+	}
+
+	return seq, nil
+}
+
+func (p *parser) parseMap(indent int) (interface{}, error) {
+
+	m := map[string]interface{}{}
+
+	for {
+		p.skipBlank()
+		if p.eof() || p.cur().indent != indent {
+			break
+		}
+
+		l := p.cur()
+		key, rest, ok, err := splitKey(l.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if !ok {
+			return nil, p.errorf("expected a key, found %q", l.text)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+
+		var v interface{}
+		if strings.TrimSpace(stripComment(rest)) == "" {
+			p.pos++
+			p.skipBlank()
+			switch {
+			case p.eof():
+			case p.cur().indent > indent:
+				v, err = p.parseNode(indent + 1)
+			case p.cur().indent == indent && isSeqEntry(p.cur().text):
+				// Sequences may be at the same
+				// indentation as their key.
+				v, err = p.parseSeq(indent)
+			}
+		} else {
+			v, err = p.parseValue(indent, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = v
+	}
+
+	return m, nil
+}
+
+/*
+splitKey splits text into a mapping key and the rest of the
+line after the colon following it. It reports false if text
+isn't a mapping entry.
+*/
+func splitKey(text string) (key, rest string, ok bool, err error) {
+
+	if strings.HasPrefix(text, "? ") || text == "?" {
+		return "", "", false, fmt.Errorf("complex keys are not supported")
+	}
+
+	switch {
+	case strings.HasPrefix(text, `"`), strings.HasPrefix(text, "'"):
+		s, n, err := parseQuoted(text)
+		if err != nil {
+			return "", "", false, nil
+		}
+		after := strings.TrimLeft(text[n:], " ")
+		if after == ":" || strings.HasPrefix(after, ": ") || strings.HasPrefix(after, ":\t") {
+			return s, after[1:], true, nil
+		}
+		return "", "", false, nil
+	case strings.HasPrefix(text, "["), strings.HasPrefix(text, "{"), strings.HasPrefix(text, "#"):
+		return "", "", false, nil
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '#' && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t'):
+			return "", "", false, nil
+		case text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t'):
+			key := strings.TrimSpace(text[:i])
+			if key == "" {
+				return "", "", false, nil
+			}
+			return key, text[i+1:], true, nil
+		}
+	}
+
+	return "", "", false, nil
+}
+
+/*
+parseValue parses text, the remainder of a line after a key
+or sequence indicator, as a value belonging to a node at
+indent. Flow collections and plain scalars may continue on
+following lines that are indented further, and block scalars
+always do.
+*/
+func (p *parser) parseValue(indent int, text string) (interface{}, error) {
+
+	text = strings.TrimSpace(text)
+	p.pos++
+
+	switch {
+	case text == "":
+		return nil, nil
+	case strings.HasPrefix(text, "&"), strings.HasPrefix(text, "*"):
+		return nil, p.errorf("anchors and aliases are not supported")
+	case strings.HasPrefix(text, "!"):
+		return nil, p.errorf("tags are not supported")
+	case strings.HasPrefix(text, "|"), strings.HasPrefix(text, ">"):
+		return p.parseBlockScalar(indent, stripComment(text))
+	case strings.HasPrefix(text, "["), strings.HasPrefix(text, "{"):
+		return p.parseFlow(indent, text)
+	case strings.HasPrefix(text, `"`), strings.HasPrefix(text, "'"):
+		s, n, err := parseQuoted(text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if rest := strings.TrimSpace(stripComment(text[n:])); rest != "" {
+			return nil, p.errorf("unexpected %q after quoted string", rest)
+		}
+		return s, nil
+	}
+
+	// Plain scalars may be folded over several lines
+	// unless ended by a comment.
+	plain := stripComment(text)
+	parts := []string{plain}
+	for plain == text && !p.eof() && p.cur().indent > indent && !p.cur().blank() {
+		l := p.cur()
+		if _, _, ok, _ := splitKey(l.text); ok || isSeqEntry(l.text) {
+			return nil, p.errorf("unexpected %q following %q", l.text, text)
+		}
+		plain = stripComment(l.text)
+		parts = append(parts, strings.TrimSpace(plain))
+		text = l.text
+		p.pos++
+	}
+
+	return resolve(strings.Join(parts, " ")), nil
+}
+
+/*
+stripComment removes a trailing comment from text. Comments
+start with a # at the start of text or following whitespace
+that isn't within quotes.
+*/
+func stripComment(text string) string {
+
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" \t[{,:", text[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimRight(text[:i], " \t")
+		}
+	}
+
+	return text
+}
+
+/*
+parseQuoted parses the single or double quoted string at the
+start of s, returning its value and the number of bytes it
+occupied.
+*/
+func parseQuoted(s string) (string, int, error) {
+
+	if s[0] == '\'' {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				sb.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), i + 1, nil
+		}
+		return "", 0, fmt.Errorf("unterminated string")
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch c := s[i]; c {
+			case '0':
+				sb.WriteByte(0)
+			case 'a':
+				sb.WriteByte('\a')
+			case 'b':
+				sb.WriteByte('\b')
+			case 't', '\t':
+				sb.WriteByte('\t')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'v':
+				sb.WriteByte('\v')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'e':
+				sb.WriteByte(0x1b)
+			case ' ', '"', '/', '\\':
+				sb.WriteByte(c)
+			case 'N':
+				sb.WriteString("\u0085")
+			case '_':
+				sb.WriteString("\u00a0")
+			case 'L':
+				sb.WriteString("\u2028")
+			case 'P':
+				sb.WriteString("\u2029")
+			case 'x', 'u', 'U':
+				n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+				if i+n >= len(s) {
+					return "", 0, fmt.Errorf("invalid escape")
+				}
+				r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(r)) {
+					return "", 0, fmt.Errorf("invalid escape \\%c%s", c, s[i+1:i+1+n])
+				}
+				sb.WriteRune(rune(r))
+				i += n
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", c)
+			}
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+/*
+parseBlockScalar parses a literal (|) or folded (>) scalar
+whose header is given. Its content is every following line
+indented further than indent, along with any blank lines
+among them.
+*/
+func (p *parser) parseBlockScalar(indent int, header string) (interface{}, error) {
+
+	folded := header[0] == '>'
+	chomp := byte(0)
+	explicit := 0
+
+	for _, c := range []byte(strings.TrimSpace(header[1:])) {
+		switch {
+		case (c == '-' || c == '+') && chomp == 0:
+			chomp = c
+		case c >= '1' && c <= '9' && explicit == 0:
+			explicit = int(c - '0')
+		default:
+			return nil, p.errorf("invalid block scalar header %q", header)
+		}
+	}
+
+	// Find the content's indentation.
+	content := -1
+	if explicit > 0 {
+		content = indent + explicit
+		if indent < 0 {
+			content = explicit
+		}
+	}
+
+	var lines []string
+	for !p.eof() {
+		l := p.cur()
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if content < 0 {
+			content = l.indent
+		}
+		if l.indent < content {
+			break
+		}
+		lines = append(lines, l.raw[content:])
+		p.pos++
+	}
+
+	// Trailing blank lines only matter for chomping.
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var s string
+	if folded {
+		s = fold(lines)
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case len(lines) == 0:
+		if chomp == '+' {
+			s = strings.Repeat("\n", trailing)
+		}
+	case chomp == '-':
+	case chomp == '+':
+		s += strings.Repeat("\n", trailing+1)
+	default:
+		s += "\n"
+	}
+
+	return s, nil
+}
+
+/*
+fold joins lines with spaces, except where they are separated
+by blank lines, each of which becomes a line break, or are
+more indented, in which case they are kept as they are.
+*/
+func fold(lines []string) string {
+
+	var sb strings.Builder
+	for i, l := range lines {
+		if i == 0 {
+			sb.WriteString(l)
+			continue
+		}
+		prev := lines[i-1]
+		switch {
+		case l == "":
+			sb.WriteByte('\n')
+		case prev == "" || strings.HasPrefix(l, " ") || strings.HasPrefix(prev, " "):
+			if prev != "" {
+				sb.WriteByte('\n')
+			}
+			sb.WriteString(l)
+		default:
+			sb.WriteByte(' ')
+			sb.WriteString(l)
+		}
+	}
+
+	return sb.String()
+}
+
+/*
+parseFlow parses a flow collection starting with text. The
+collection may continue on following lines indented further
+than indent.
+*/
+func (p *parser) parseFlow(indent int, text string) (interface{}, error) {
+
+	src := stripComment(text)
+	for {
+		f := flowParser{src: src}
+		v, err := f.parseValue()
+		if err == nil {
+			f.skipSpace()
+			if !f.eof() {
+				return nil, p.errorf("unexpected %q after flow collection", f.src[f.pos:])
+			}
+			return v, nil
+		}
+		if err != errFlowEOF || p.eof() || p.cur().indent <= indent {
+			if err == errFlowEOF {
+				err = fmt.Errorf("unterminated flow collection")
+			}
+			return nil, p.errorf("%v", err)
+		}
+		src += " " + stripComment(p.cur().text)
+		p.pos++
+	}
+}
+
+var errFlowEOF = fmt.Errorf("unexpected end of flow collection")
+
+type flowParser struct {
+	src string
+	pos int
+}
+
+func (f *flowParser) eof() bool {
+	return f.pos >= len(f.src)
+}
+
+func (f *flowParser) skipSpace() {
+	for !f.eof() && (f.src[f.pos] == ' ' || f.src[f.pos] == '\t') {
+		f.pos++
+	}
+}
+
+func (f *flowParser) parseValue() (interface{}, error) {
+
+	f.skipSpace()
+	if f.eof() {
+		return nil, errFlowEOF
+	}
+
+	switch c := f.src[f.pos]; c {
+	case '[':
+		return f.parseSeq()
+	case '{':
+		return f.parseMap()
+	case '"', '\'':
+		s, n, err := parseQuoted(f.src[f.pos:])
+		if err != nil {
+			return nil, errFlowEOF
+		}
+		f.pos += n
+		return s, nil
+	case '&', '*':
+		return nil, fmt.Errorf("anchors and aliases are not supported")
+	case '!':
+		return nil, fmt.Errorf("tags are not supported")
+	}
+
+	return resolve(f.plain()), nil
+}
+
+// plain reads a plain scalar within a flow collection.
+func (f *flowParser) plain() string {
+	start := f.pos
+	for !f.eof() {
+		c := f.src[f.pos]
+		if c == ',' || c == ']' || c == '}' {
+			break
+		}
+		if c == ':' && (f.pos+1 == len(f.src) || strings.IndexByte(" \t,]}", f.src[f.pos+1]) >= 0) {
+			break
+		}
+		f.pos++
+	}
+	return strings.TrimSpace(f.src[start:f.pos])
+}
+
+func (f *flowParser) parseSeq() (interface{}, error) {
+
+	f.pos++
+	seq := []interface{}{}
+
+	for {
+		f.skipSpace()
+		if f.eof() {
+			return nil, errFlowEOF
+		}
+		if f.src[f.pos] == ']' {
+			f.pos++
+			return seq, nil
+		}
+
+		v, err := f.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+
+		f.skipSpace()
+		if f.eof() {
+			return nil, errFlowEOF
+		}
+		switch f.src[f.pos] {
+		case ',':
+			f.pos++
+		case ']':
+		default:
+			return nil, fmt.Errorf("expected , or ] in flow sequence, found %q", f.src[f.pos:])
+		}
+	}
+}
+
+func (f *flowParser) parseMap() (interface{}, error) {
+
+	f.pos++
+	m := map[string]interface{}{}
+
+	for {
+		f.skipSpace()
+		if f.eof() {
+			return nil, errFlowEOF
+		}
+		if f.src[f.pos] == '}' {
+			f.pos++
+			return m, nil
+		}
+
+		var key string
+		switch f.src[f.pos] {
+		case '"', '\'':
+			s, n, err := parseQuoted(f.src[f.pos:])
+			if err != nil {
+				return nil, errFlowEOF
+			}
+			f.pos += n
+			key = s
+		default:
+			key = f.plain()
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+
+		f.skipSpace()
+		if f.eof() {
+			return nil, errFlowEOF
+		}
+
+		var v interface{}
+		if f.src[f.pos] == ':' {
+			f.pos++
+			f.skipSpace()
+			if f.eof() {
+				return nil, errFlowEOF
+			}
+			if c := f.src[f.pos]; c != ',' && c != '}' {
+				var err error
+				v, err = f.parseValue()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		m[key] = v
+
+		f.skipSpace()
+		if f.eof() {
+			return nil, errFlowEOF
+		}
+		switch f.src[f.pos] {
+		case ',':
+			f.pos++
+		case '}':
+		default:
+			return nil, fmt.Errorf("expected , or } in flow mapping, found %q", f.src[f.pos:])
+		}
+	}
+}
+
+/*
+resolve converts a plain scalar to the type given to it by
+the YAML 1.2 core schema.
+*/
+func resolve(s string) interface{} {
+
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		if n, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+			return n
+		}
+	case strings.HasPrefix(s, "0o"):
+		if n, err := strconv.ParseInt(s[2:], 8, 64); err == nil {
+			return n
+		}
+	case isNumber(s):
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+
+	return s
+}
+
+// isNumber reports whether s is a decimal integer or float.
+func isNumber(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" || s == "." {
+		return false
+	}
+	digits := false
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '.' || c == 'e' || c == 'E':
+		case (c == '+' || c == '-') && i > 0 && (s[i-1] == 'e' || s[i-1] == 'E'):
+		default:
+			return false
+		}
+	}
+	return digits
+}
@@ -0,0 +1,179 @@
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+
+	cases := []struct {
+		doc     string
+		want    interface{}
+		wantErr bool
+	}{
+		// Scalars.
+		{
+			`
+# A comment.
+title: YAML "Example" # Trailing comment.
+single: 'it''s'
+double: "tab\there \u00e9"
+int: +1000
+hex: 0xff
+octal: 0o17
+float: 6.626e-34
+version: 1.10
+bool: true
+none: ~
+empty:
+url: http://example.com/#anchor
+`,
+			map[string]interface{}{
+				"title":   `YAML "Example"`,
+				"single":  "it's",
+				"double":  "tab\there é",
+				"int":     int64(1000),
+				"hex":     int64(255),
+				"octal":   int64(15),
+				"float":   6.626e-34,
+				"version": 1.1,
+				"bool":    true,
+				"none":    nil,
+				"empty":   nil,
+				"url":     "http://example.com/#anchor",
+			},
+			false,
+		},
+
+		// Nested mappings and sequences.
+		{
+			`---
+server:
+  host: localhost
+  ports:
+    - 80
+    - 443
+  tags:
+  - a
+  - b
+users:
+  - name: alice
+    roles: [admin, "dev"]
+  - name: bob
+    meta: {age: 30, "team": core}
+  - - nested
+    - seq
+...
+`,
+			map[string]interface{}{
+				"server": map[string]interface{}{
+					"host":  "localhost",
+					"ports": []interface{}{int64(80), int64(443)},
+					"tags":  []interface{}{"a", "b"},
+				},
+				"users": []interface{}{
+					map[string]interface{}{
+						"name":  "alice",
+						"roles": []interface{}{"admin", "dev"},
+					},
+					map[string]interface{}{
+						"name": "bob",
+						"meta": map[string]interface{}{
+							"age":  int64(30),
+							"team": "core",
+						},
+					},
+					[]interface{}{"nested", "seq"},
+				},
+			},
+			false,
+		},
+
+		// Block and multi-line scalars.
+		{
+			`
+literal: |
+  line one
+    indented
+
+  line three
+folded: >-
+  folded
+  text
+
+  para
+plain: multi
+  line
+flow: [a,
+  b]
+`,
+			map[string]interface{}{
+				"literal": "line one\n  indented\n\nline three\n",
+				"folded":  "folded text\npara",
+				"plain":   "multi line",
+				"flow":    []interface{}{"a", "b"},
+			},
+			false,
+		},
+
+		// A top-level sequence.
+		{
+			"- 1\n- two\n",
+			[]interface{}{int64(1), "two"},
+			false,
+		},
+
+		// Empty documents.
+		{
+			"# nothing\n",
+			nil,
+			false,
+		},
+
+		// Duplicate keys.
+		{"a: 1\na: 2", nil, true},
+
+		// Bad indentation.
+		{"a:\n    b: 1\n  c: 2", nil, true},
+
+		// Tabs.
+		{"a:\n\tb: 1", nil, true},
+
+		// Unsupported features.
+		{"a: &x 1\nb: *x", nil, true},
+		{"a: !!str 1", nil, true},
+		{"a: 1\n---\nb: 2", nil, true},
+		{"? a\n: 1", nil, true},
+
+		// Unterminated.
+		{`a: "b`, nil, true},
+		{"a: [1, 2", nil, true},
+	}
+
+	for i, c := range cases {
+		got, err := Unmarshal([]byte(c.doc))
+		if !reflect.DeepEqual(got, c.want) && !c.wantErr || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Unmarshal(%q)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, error: %v",
+				c.doc, got, err, c.want, c.wantErr)
+		}
+	}
+}
+
+func TestUnmarshalSpecialFloats(t *testing.T) {
+
+	got, err := Unmarshal([]byte("a: .inf\nb: -.Inf\nc: .nan"))
+	if err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	m := got.(map[string]interface{})
+	if m["a"] != math.Inf(1) || m["b"] != math.Inf(-1) || !math.IsNaN(m["c"].(float64)) {
+		t.Errorf("Unmarshal returned %v, wanted +Inf, -Inf, and NaN", got)
+	}
+}
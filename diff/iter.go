@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package diff
+
+import (
+	"iter"
+	"reflect"
+	"time"
+)
+
+/*
+All returns an iterator over the differences between before
+and after, found as they would be by Diffs. Differences are
+found lazily as the iterator is ranged over, and breaking out
+of the loop stops the diff without examining the rest of the
+objects. All is only built with Go 1.23 or later, which
+range over functions requires.
+
+If the arguments are invalid or the diff fails, the error is
+yielded last along with a zero Diff.
+
+	for d, err := range diff.All(before, after) {
+		if err != nil {
+			return err
+		}
+		fmt.Println(d.Name)
+	}
+*/
+func All(before, after interface{}, opts ...Option) iter.Seq2[Diff, error] {
+
+	return func(yield func(Diff, error) bool) {
+
+		var err error
+		defer observe(time.Now(), &err)
+
+		if err = validate(before, after); err != nil {
+			yield(Diff{}, err)
+			return
+		}
+
+		v1 := reflect.ValueOf(before)
+		v2 := reflect.ValueOf(after)
+
+		d := differ{
+			opts: newOptions(opts),
+			yield: func(s Diff) bool {
+				return yield(s, nil)
+			},
+		}
+
+		err = d.run(&v1, &v2)
+		if err == errStop {
+			err = nil
+		}
+		if err != nil {
+			yield(Diff{}, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+//go:build go1.23
+
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+
+	before := config{true, "0.0.0", 30}
+	after := config{false, "0.0.1", 15}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		limit  int
+		want   []string
+		err    error
+	}{
+		{before, after, nil, -1, []string{".Debug", ".Version", ".Timeout"}, nil},
+		{before, after, nil, 2, []string{".Debug", ".Version"}, nil},
+		{before, before, nil, -1, nil, nil},
+		{config{}, notConfig{}, nil, -1, nil, ErrTypeMismatch},
+		{before, after, []Option{withContext(cancelled())}, -1, nil, context.Canceled},
+	}
+
+	for i, c := range cases {
+
+		var got []string
+		var err error
+		for d, e := range All(c.before, c.after, c.opts...) {
+			if e != nil {
+				err = e
+				break
+			}
+			got = append(got, d.Name)
+			if len(got) == c.limit {
+				break
+			}
+		}
+
+		if !equal(got, c.want) || !errors.Is(err, c.err) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"All(%v, %v)\n"+
+					"    yield %v, %v\n"+
+					"    wanted %v, %v",
+				c.before, c.after, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestAllPanics(t *testing.T) {
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("panic in loop body recovered as %v, wanted %q", r, "boom")
+		}
+	}()
+
+	for range All(config{Debug: true}, config{}) {
+		panic("boom")
+	}
+}
+
+func TestAllMetrics(t *testing.T) {
+
+	defer SetMetrics(nil)
+
+	m := &testMetrics{}
+	SetMetrics(m)
+
+	for range All(map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "c": 3}) {
+		break
+	}
+
+	if len(m.runs) != 1 || m.runs[0] != nil || !reflect.DeepEqual(m.kinds, []Kind{Change}) {
+		t.Errorf(
+			"Metrics received runs %v, differences %v\n"+
+				"    wanted 1 run without error, differences %v",
+			m.runs, m.kinds, []Kind{Change})
+	}
+}
+
+func cancelled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+roundTrip replaces v1 and v2 with the generic values their
+JSON decodes to, for WithJSONRoundTrip.
+*/
+func roundTrip(v1, v2 *reflect.Value) (*reflect.Value, *reflect.Value, error) {
+
+	j1, err := jsonValue(*v1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("before: %w", err)
+	}
+	j2, err := jsonValue(*v2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("after: %w", err)
+	}
+
+	return &j1, &j2, nil
+}
+
+func jsonValue(v reflect.Value) (reflect.Value, error) {
+
+	v, ok := maskedCopy(v)
+	if !ok {
+		return reflect.Value{}, errors.New(`a field tagged with diff:"redact" can't be redacted from JSON as it can't hold a string`)
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var i interface{}
+	if err := dec.Decode(&i); err != nil {
+		return reflect.Value{}, err
+	}
+
+	// The value is held in an interface so that an object
+	// that marshals to null is a nil interface rather than
+	// an invalid reflect.Value.
+	return reflect.ValueOf(&i).Elem(), nil
+}
+
+// maskPrefix begins the text standing in for a redacted value
+// in JSON. See maskedCopy.
+const maskPrefix = "\x00diff:redacted:"
+
+/*
+maskedCopy returns v with the values of the struct fields
+tagged with `diff:"redact"` within it replaced by an HMAC of
+them, under a key chosen at random for the process, so that
+they don't appear in its JSON but changes to them can still
+be detected. It returns false if a redacted field can't hold
+a string.
+*/
+func maskedCopy(v reflect.Value) (reflect.Value, bool) {
+
+	r := redactor{
+		seen: map[uintptr]reflect.Value{},
+		mask: func(f reflect.Value) string {
+			mac := hmac.New(sha256.New, processKey())
+			fmt.Fprint(mac, f.Interface())
+			return maskPrefix + hex.EncodeToString(mac.Sum(nil))
+		},
+	}
+
+	return r.copy(v)
+}
+
+// masked reports whether v is a string standing in for a
+// redacted value, or an interface holding one.
+func masked(v *reflect.Value) bool {
+	if v == nil {
+		return false
+	}
+	s, ok := stringValue(*v)
+	return ok && strings.HasPrefix(s, maskPrefix)
+}
+
+/*
+unmasked returns i, a value decoded from JSON, with any
+strings standing in for redacted values within it replaced
+by placeholder.
+*/
+func unmasked(i interface{}, placeholder string) interface{} {
+
+	switch x := i.(type) {
+	case string:
+		if strings.HasPrefix(x, maskPrefix) {
+			return placeholder
+		}
+	case []interface{}:
+		c := make([]interface{}, len(x))
+		for j, e := range x {
+			c[j] = unmasked(e, placeholder)
+		}
+		return c
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			c[k] = unmasked(e, placeholder)
+		}
+		return c
+	}
+
+	return i
+}
@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type celsius float64
+
+func (c celsius) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%.0fC"`, float64(c))), nil
+}
+
+type unmarshalable struct {
+	C chan int
+}
+
+func TestWithJSONRoundTrip(t *testing.T) {
+
+	type reading struct {
+		Place   string    `json:"place"`
+		Temp    celsius   `json:"temp"`
+		Note    string    `json:"note,omitempty"`
+		At      time.Time `json:"at"`
+		Count   int64     `json:"count"`
+		Tags    []string  `json:"tags"`
+		Private string    `json:"-"`
+		secret  string
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		want   []string
+	}{
+		{
+			reading{"Oslo", 20.4, "", at, 1, nil, "a", "x"},
+			reading{"Oslo", 19.6, "warm", at.Add(time.Second), 12345678901, []string{}, "b", "y"},
+			[]string{
+				`["at"] changed from "2024-01-02T03:04:05Z" to "2024-01-02T03:04:06Z"`,
+				`["count"] changed from 1 to 12345678901`,
+				`["note"] added "warm"`,
+				`["tags"] changed from <no value> to []`,
+			},
+		},
+		{
+			reading{Temp: 20.4, secret: "x"},
+			reading{Temp: 20.1, secret: "y"},
+			nil,
+		},
+		{
+			[]interface{}{1, "a"},
+			[]interface{}{1.0, "b"},
+			[]string{
+				`[1] changed from "a" to "b"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, WithJSONRoundTrip())
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithJSONRoundTrip())\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithJSONRoundTripRedact(t *testing.T) {
+
+	type login struct {
+		User     string      `json:"user"`
+		Password string      `json:"password" diff:"redact"`
+		Token    interface{} `json:"token,omitempty" diff:"redact"`
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		want   []string
+	}{
+		{
+			login{"ann", "hunter2", nil},
+			login{"ann", "hunter2", nil},
+			nil,
+		},
+		{
+			login{"ann", "hunter2", nil},
+			login{"ann", "hunter3", "tok"},
+			[]string{
+				`["password"] changed from [REDACTED] to [REDACTED]`,
+				`["token"] added [REDACTED]`,
+			},
+		},
+		{
+			[]interface{}{login{"ann", "hunter2", nil}},
+			[]interface{}{"ann"},
+			[]string{
+				`[0] changed from map[password:[REDACTED] user:ann] to "ann"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, WithJSONRoundTrip())
+		if !equal(got, c.want) || err != nil || strings.Contains(fmt.Sprint(got), "hunter") {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithJSONRoundTrip())\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+
+	diffs, err := Diffs(login{"ann", "hunter2", nil}, login{"bob", "hunter3", nil}, WithJSONRoundTrip())
+	if len(diffs) != 2 || err != nil ||
+		strings.Contains(fmt.Sprint(diffs), "hunter") ||
+		strings.Contains(fmt.Sprint(diffs), maskPrefix) {
+		t.Errorf("Diffs(..., WithJSONRoundTrip()) returned %v, %v", diffs, err)
+	}
+
+	type pin struct {
+		PIN int `diff:"redact"`
+	}
+	if _, err := Objects(pin{1}, pin{2}, WithJSONRoundTrip()); err == nil {
+		t.Errorf("Objects of an int tagged redact with WithJSONRoundTrip returned nil error")
+	}
+}
+
+func TestWithJSONRoundTripError(t *testing.T) {
+
+	before := unmarshalable{}
+	after := unmarshalable{make(chan int)}
+
+	_, err := Objects(before, after, WithJSONRoundTrip())
+
+	var typeErr *json.UnsupportedTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf(
+			"Objects(%v, %v, WithJSONRoundTrip())\n"+
+				"    return %v\n"+
+				"    wanted *json.UnsupportedTypeError",
+			before, after, err)
+	}
+}
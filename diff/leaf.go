@@ -0,0 +1,72 @@
+package diff
+
+import "reflect"
+
+/*
+leafEqual reports whether v1 and v2, values of a type set
+with WithLeafTypes, are equal. Types with an Equal method
+taking a value of the same type and returning a bool, such
+as time.Time, are compared with it, including when it has a
+pointer receiver. Other types are compared deeply.
+*/
+func leafEqual(v1, v2 reflect.Value) bool {
+
+	if v1.Type() != v2.Type() {
+		return false
+	}
+
+	t := v1.Type()
+	recv := v1
+	m, ok := equalMethod(t)
+	if !ok {
+		m, ok = equalMethod(reflect.PtrTo(t))
+		if !ok {
+			return reflect.DeepEqual(v1.Interface(), v2.Interface())
+		}
+		recv = reflect.New(t)
+		recv.Elem().Set(v1)
+	}
+
+	return m.Func.Call([]reflect.Value{recv, v2})[0].Bool()
+}
+
+/*
+equalMethod returns the method of t named Equal if it takes
+a single argument of the type t is, or points to, and returns
+a bool.
+*/
+func equalMethod(t reflect.Type) (reflect.Method, bool) {
+
+	m, ok := t.MethodByName("Equal")
+	if !ok {
+		return m, false
+	}
+
+	elem := t
+	if t.Kind() == reflect.Ptr {
+		elem = t.Elem()
+	}
+
+	ft := m.Type
+	if ft.NumIn() != 2 || ft.In(1) != elem || ft.NumOut() != 1 || ft.Out(0).Kind() != reflect.Bool {
+		return m, false
+	}
+
+	return m, true
+}
+
+/*
+diffLeaf compares values of a type set with WithLeafTypes as
+a whole rather than by their fields or elements.
+*/
+func (d *differ) diffLeaf(v1, v2 *reflect.Value) error {
+
+	if v1 != nil && v2 != nil && leafEqual(*v1, *v2) {
+		if d.opts.unchanged {
+			return d.report(Diff{}, Same, v1, v2)
+		}
+		return nil
+	}
+
+	return d.diffAtom(v1, v2)
+}
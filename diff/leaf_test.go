@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// decimal is a value of coef×10^exp, where equal values may
+// have different representations.
+type decimal struct {
+	coef *big.Int
+	exp  int
+}
+
+func newDecimal(coef int64, exp int) decimal {
+	return decimal{big.NewInt(coef), exp}
+}
+
+func (d *decimal) Equal(other decimal) bool {
+	return d.rat().Cmp(other.rat()) == 0
+}
+
+func (d *decimal) rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.coef)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(d.exp))), nil))
+	if d.exp < 0 {
+		return r.Quo(r, scale)
+	}
+	return r.Mul(r, scale)
+}
+
+func (d decimal) String() string {
+	return d.rat().FloatString(2)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func TestWithLeafTypes(t *testing.T) {
+
+	type order struct {
+		Placed time.Time
+		Total  decimal
+		Addr   netip.Addr
+		Lines  []decimal
+	}
+
+	placed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	oslo := time.FixedZone("CET", 3600)
+
+	cases := []struct {
+		before order
+		after  order
+		want   []string
+	}{
+		{
+			order{placed, newDecimal(150, -2), netip.MustParseAddr("10.0.0.1"), []decimal{newDecimal(1, 0)}},
+			order{placed.In(oslo), newDecimal(15, -1), netip.MustParseAddr("10.0.0.1"), []decimal{newDecimal(100, -2)}},
+			nil,
+		},
+		{
+			order{placed, newDecimal(150, -2), netip.MustParseAddr("10.0.0.1"), nil},
+			order{placed.Add(time.Hour), newDecimal(2, 0), netip.MustParseAddr("10.0.0.2"), []decimal{newDecimal(5, 1)}},
+			[]string{
+				`.Placed changed from 2024-05-01 12:00:00 +0000 UTC to 2024-05-01 13:00:00 +0000 UTC`,
+				`.Total changed from 1.50 to 2.00`,
+				`.Addr changed from 10.0.0.1 to 10.0.0.2`,
+				`.Lines[0] added 50.00`,
+			},
+		},
+	}
+
+	opts := WithLeafTypes(time.Time{}, decimal{}, netip.Addr{})
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, opts)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithLeafTypes(...))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithLeafTypesNil(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WithLeafTypes(nil) didn't panic")
+		}
+	}()
+
+	WithLeafTypes(time.Time{}, nil)
+}
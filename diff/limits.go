@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+Limits bounds the work done by a diff, for use with
+WithLimits. A limit of 0 or less is no limit.
+*/
+type Limits struct {
+	// MaxNodes is the most values, including structs,
+	// maps, slices, and arrays as well as what they hold,
+	// that may be visited.
+	MaxNodes int
+
+	// MaxBytes is the most bytes of rendered text that
+	// may be returned. It doesn't apply to functions that
+	// don't render templates, such as Diffs.
+	MaxBytes int
+
+	// Truncate, if true, stops a diff that exceeds a limit
+	// and returns what was found so far without an error.
+	// Functions returning rendered text end it with a
+	// marker such as "… (truncated: more than 1000 nodes
+	// visited)", which doesn't count towards MaxBytes.
+	Truncate bool
+}
+
+// limitError records that a limit was exceeded at the
+// current path, for the reason given, and returns the error.
+func (d *differ) limitError(reason string) error {
+	d.limit = reason
+	return &PathError{Path: d.name(), Err: fmt.Errorf("%w: %s", ErrLimit, reason)}
+}
+
+/*
+visit counts a value being visited, returning an error
+wrapping ErrLimit if it is one more than Limits.MaxNodes.
+*/
+func (d *differ) visit() error {
+	d.nodes++
+	if max := d.opts.limits.MaxNodes; max > 0 && d.nodes > max {
+		return d.limitError(fmt.Sprintf("more than %d nodes visited", max))
+	}
+	return nil
+}
+
+/*
+rendered counts the bytes of the last rendered difference,
+discarding it and returning an error wrapping ErrLimit if it
+takes the total beyond Limits.MaxBytes.
+*/
+func (d *differ) rendered() error {
+
+	last := len(d.changes) - 1
+	d.bytes += len(d.changes[last])
+
+	if max := d.opts.limits.MaxBytes; max > 0 && d.bytes > max {
+		d.changes = d.changes[:last]
+		return d.limitError(fmt.Sprintf("more than %d bytes rendered", max))
+	}
+
+	return nil
+}
+
+/*
+truncated returns nil in place of err if err is for a limit
+being exceeded when using Limits.Truncate, ending the
+rendered differences with a marker. Other errors are
+returned as they are.
+*/
+func (d *differ) truncated(err error) error {
+
+	if d.limit == "" || !d.opts.limits.Truncate || !errors.Is(err, ErrLimit) {
+		return err
+	}
+
+	// Changes records the limit instead of a marker so that
+	// its text stays aligned with its differences.
+	if d.templates != nil && !d.keep {
+		d.changes = append(d.changes, truncationMarker(d.limit))
+	}
+
+	return nil
+}
+
+// truncationMarker returns the text ending the rendered
+// differences of a diff that exceeded a limit for reason.
+func truncationMarker(reason string) string {
+	return "… (truncated: " + reason + ")"
+}
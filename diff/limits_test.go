@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWithLimits(t *testing.T) {
+
+	type row struct {
+		A, B, C int
+	}
+
+	before := []row{{1, 2, 3}, {4, 5, 6}}
+	after := []row{{1, 0, 0}, {0, 5, 0}}
+
+	cases := []struct {
+		limits Limits
+		want   []string
+		err    string
+	}{
+		{
+			Limits{},
+			[]string{
+				`[0].B changed from 2 to 0`,
+				`[0].C changed from 3 to 0`,
+				`[1].A changed from 4 to 0`,
+				`[1].C changed from 6 to 0`,
+			},
+			"",
+		},
+		{
+			Limits{MaxNodes: 5},
+			nil,
+			"[1]: diff limit exceeded: more than 5 nodes visited",
+		},
+		{
+			Limits{MaxNodes: 5, Truncate: true},
+			[]string{
+				`[0].B changed from 2 to 0`,
+				`[0].C changed from 3 to 0`,
+				`… (truncated: more than 5 nodes visited)`,
+			},
+			"",
+		},
+		{
+			Limits{MaxBytes: 60},
+			nil,
+			"[1].A: diff limit exceeded: more than 60 bytes rendered",
+		},
+		{
+			Limits{MaxBytes: 60, Truncate: true},
+			[]string{
+				`[0].B changed from 2 to 0`,
+				`[0].C changed from 3 to 0`,
+				`… (truncated: more than 60 bytes rendered)`,
+			},
+			"",
+		},
+	}
+
+	for i, c := range cases {
+
+		got, err := Objects(before, after, WithLimits(c.limits))
+
+		var pe *PathError
+		switch {
+		case c.err == "" && err != nil,
+			c.err != "" && (err == nil || err.Error() != c.err),
+			c.err != "" && !errors.Is(err, ErrLimit),
+			c.err != "" && !errors.As(err, &pe),
+			!equal(got, c.want):
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithLimits(%+v))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, %s",
+				before, after, c.limits, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestWithLimitsDiffs(t *testing.T) {
+
+	before := map[string]int{"a": 1, "b": 2}
+	after := map[string]int{"a": 2, "b": 3}
+
+	got, err := Diffs(before, after, WithLimits(Limits{MaxNodes: 2, Truncate: true}))
+	if len(got) != 1 || err != nil {
+		t.Errorf(
+			"Diffs(%v, %v, WithLimits(...))\n"+
+				"    return %v, %v\n"+
+				"    wanted 1 Diff, nil",
+			before, after, got, err)
+	}
+}
+
+func TestWithLimitsCompare(t *testing.T) {
+
+	before := []int{1, 2, 3, 4}
+	after := []int{0, 0, 0, 0}
+
+	cases := []struct {
+		limits    Limits
+		paths     []string
+		strings   []string
+		truncated bool
+	}{
+		{
+			Limits{},
+			[]string{"[0]", "[1]", "[2]", "[3]"},
+			[]string{
+				`[0] changed from 1 to 0`,
+				`[1] changed from 2 to 0`,
+				`[2] changed from 3 to 0`,
+				`[3] changed from 4 to 0`,
+			},
+			false,
+		},
+		{
+			Limits{MaxNodes: 3, Truncate: true},
+			[]string{"[0]", "[1]"},
+			[]string{
+				`[0] changed from 1 to 0`,
+				`[1] changed from 2 to 0`,
+				`… (truncated: more than 3 nodes visited)`,
+			},
+			true,
+		},
+		{
+			Limits{MaxBytes: 50, Truncate: true},
+			[]string{"[0]", "[1]"},
+			[]string{
+				`[0] changed from 1 to 0`,
+				`[1] changed from 2 to 0`,
+				`… (truncated: more than 50 bytes rendered)`,
+			},
+			true,
+		},
+	}
+
+	for i, c := range cases {
+
+		got, err := Compare(before, after, WithLimits(c.limits))
+		want, _ := Objects(before, after, WithLimits(c.limits))
+
+		if got.Len() != len(c.paths) ||
+			!equal(got.Paths(), c.paths) ||
+			len(got.Diffs()) != len(c.paths) ||
+			!equal(got.Strings(), c.strings) ||
+			!equal(got.Strings(), want) ||
+			got.Truncated() != c.truncated ||
+			got.Filter(func(Diff) bool { return true }).Truncated() != c.truncated ||
+			err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Compare(%v, %v, WithLimits(%+v))\n"+
+					"    return %q, %q, truncated %v, %v\n"+
+					"    wanted %q, %q, truncated %v, nil",
+				before, after, c.limits, got.Paths(), got.Strings(), got.Truncated(), err,
+				c.paths, c.strings, c.truncated)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var locales = struct {
+	sync.RWMutex
+	formats map[string]Format
+}{
+	formats: map[string]Format{
+		"en": {
+			Change: DefaultChange,
+			Add:    DefaultAdd,
+			Delete: DefaultDelete,
+			Move:   DefaultMove,
+			Same:   DefaultSame,
+		},
+		"de": {
+			Change: "{{.Name}} geändert von {{.Before}} zu {{.After}}",
+			Add:    "{{.Name}} hinzugefügt: {{.After}}",
+			Delete: "{{.Name}} gelöscht: {{.Before}}",
+			Move:   "{{.Name}} verschoben nach [{{.To}}]",
+			Same:   "{{.Name}} unverändert: {{.After}}",
+		},
+	},
+}
+
+/*
+RegisterLocale makes format available to WithLocale under
+the name locale, replacing any Format previously registered
+under that name. Empty strings in format fall back to the
+package defaults, or for Set and Clear to Change. Formats
+for "en" and "de" are registered by default.
+
+Locale names are matched case-insensitively. If no Format is
+registered for a regional locale such as "de-AT" the one for
+its base language, "de", is used instead.
+
+It is safe to call RegisterLocale concurrently with diffs
+that are in progress.
+*/
+func RegisterLocale(locale string, format Format) {
+	locales.Lock()
+	defer locales.Unlock()
+	locales.formats[normalizeLocale(locale)] = format
+}
+
+func lookupLocale(locale string) (Format, error) {
+
+	locale = normalizeLocale(locale)
+
+	locales.RLock()
+	defer locales.RUnlock()
+
+	if f, ok := locales.formats[locale]; ok {
+		return f, nil
+	}
+	if i := strings.Index(locale, "-"); i > 0 {
+		if f, ok := locales.formats[locale[:i]]; ok {
+			return f, nil
+		}
+	}
+
+	return Format{}, fmt.Errorf("no format registered for locale %q", locale)
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "_", "-", -1))
+}
+
+/*
+fillFormat substitutes empty templates in format with those
+of locale, or the package defaults if locale is empty. It
+also returns the delimiters each template is written with.
+*/
+func fillFormat(format Format, locale string) (Format, delims, error) {
+
+	ds := delimsOf(format)
+
+	def := Format{
+		Change: DefaultChange,
+		Add:    DefaultAdd,
+		Delete: DefaultDelete,
+		Move:   DefaultMove,
+		Same:   DefaultSame,
+	}
+
+	if locale != "" {
+		f, err := lookupLocale(locale)
+		if err != nil {
+			return Format{}, nil, err
+		}
+		for name, d := range delimsOf(f) {
+			if _, ok := ds[name]; !ok {
+				ds[name] = d
+			}
+		}
+		if f.Change != "" {
+			def.Change = f.Change
+		}
+		if f.Add != "" {
+			def.Add = f.Add
+		}
+		if f.Delete != "" {
+			def.Delete = f.Delete
+		}
+		if f.Move != "" {
+			def.Move = f.Move
+		}
+		if f.Same != "" {
+			def.Same = f.Same
+		}
+		def.Set = f.Set
+		def.Clear = f.Clear
+		def.Funcs = f.Funcs
+		def.Verb = f.Verb
+	}
+
+	if format.Change == "" {
+		format.Change = def.Change
+	}
+	if format.Add == "" {
+		format.Add = def.Add
+	}
+	if format.Delete == "" {
+		format.Delete = def.Delete
+	}
+	if format.Move == "" {
+		format.Move = def.Move
+	}
+	if format.Same == "" {
+		format.Same = def.Same
+	}
+	if format.Set == "" {
+		format.Set = def.Set
+	}
+	if format.Clear == "" {
+		format.Clear = def.Clear
+	}
+	if format.Funcs == nil {
+		format.Funcs = def.Funcs
+	}
+	if format.Verb == "" {
+		format.Verb = def.Verb
+	}
+
+	return format, ds, nil
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithLocale(t *testing.T) {
+
+	RegisterLocale("x-Test", Format{
+		Change: "{{.Name}}: {{.Before}} => {{.After}}",
+	})
+	RegisterLocale("x-Delims", Format{
+		Change:     "<.Name> <.Before> => <.After>",
+		LeftDelim:  "<",
+		RightDelim: ">",
+	})
+
+	before := config{true, "0.0.0", 30}
+	after := config{true, "0.0.0", 15}
+
+	cases := []struct {
+		locale  string
+		format  Format
+		want    []string
+		wantErr bool
+	}{
+		{
+			"de",
+			Format{},
+			[]string{".Timeout geändert von 30 zu 15"},
+			false,
+		},
+		{
+			"de_AT",
+			Format{},
+			[]string{".Timeout geändert von 30 zu 15"},
+			false,
+		},
+		{
+			"X-TEST",
+			Format{},
+			[]string{".Timeout: 30 => 15"},
+			false,
+		},
+		{
+			"de",
+			Format{Change: "{{.After}}"},
+			[]string{"15"},
+			false,
+		},
+		{
+			"x-delims",
+			Format{},
+			[]string{".Timeout 30 => 15"},
+			false,
+		},
+		{
+			"x-delims",
+			Format{Change: "[[.Name]] {{.After}}", LeftDelim: "[[", RightDelim: "]]"},
+			[]string{".Timeout {{.After}}"},
+			false,
+		},
+		{
+			"xx",
+			Format{},
+			nil,
+			true,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(c.format, before, after, WithLocale(c.locale))
+		if !equal(got, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v, WithLocale(%q))\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, error: %v",
+				c.format, before, after, c.locale, got, err, c.want, c.wantErr)
+		}
+	}
+}
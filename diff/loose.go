@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+/*
+ObjectsLoose works the same as Objects but allows before and
+after to be of different types, such as two versions of a
+struct used as an API model. Structs of different types are
+diffed by matching their exported fields by name. Fields
+whose names match but whose kinds don't, or that exist on only
+one side, are reported as deleted from before or added to
+after. Unexported fields of such structs are ignored.
+
+Structs of different types nested within before and after,
+including as the elements of slices and maps, are matched in
+the same way. Values of the same kind but different types,
+such as an int and an int64, are compared as they would be
+within an interface and so always differ.
+
+The arguments before and after must still be data structures
+of the same kind and maps must have keys of the same type.
+*/
+func ObjectsLoose(before, after interface{}, opts ...Option) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	t1 := reflect.TypeOf(before)
+	t2 := reflect.TypeOf(after)
+
+	if err := isObj(t1, "before"); err != nil {
+		return nil, err
+	}
+	if err := isObj(t2, "after"); err != nil {
+		return nil, err
+	}
+	if err := sameKind(t1, t2); err != nil {
+		return nil, err
+	}
+	if !compatible(t1, t2) {
+		return nil, &ObjectError{
+			Err:        ErrTypeMismatch,
+			BeforeKind: t1.Kind(),
+			AfterKind:  t2.Kind(),
+			BeforeType: t1.String(),
+			AfterType:  t2.String(),
+			msg: fmt.Sprintf(
+				`map keys must be same type - "before" was %s, "after" was %s`,
+				t1.Key(), t2.Key()),
+		}
+	}
+
+	o := newOptions(opts)
+	t, err := parseFormat(Format{}, o)
+	if err != nil {
+		return nil, err
+	}
+
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+
+	d := differ{opts: o, templates: t, loose: true}
+	err = d.run(&v1, &v2)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.errs) > 0 {
+		return d.changes, errors.Join(d.errs...)
+	}
+
+	return d.changes, nil
+}
+
+/*
+compatible reports whether values of types t1 and t2 can be
+diffed against each other by ObjectsLoose: they must be of
+the same kind and, if they're maps, have the same key type.
+*/
+func compatible(t1, t2 reflect.Type) bool {
+	if t1.Kind() != t2.Kind() {
+		return false
+	}
+	if t1.Kind() == reflect.Map {
+		return t1.Key() == t2.Key()
+	}
+	return true
+}
+
+/*
+diffStructLoose diffs structs of different types by their
+exported fields, matched by name. Fields of before come
+first in declaration order, followed by those only present
+in after, unless WithSortedFields is used.
+*/
+func (d *differ) diffStructLoose(v1, v2 *reflect.Value) error {
+
+	t1 := v1.Type()
+	t2 := v2.Type()
+
+	fields2 := map[string]fieldInfo{}
+	for _, fi := range fieldsOf(t2) {
+		if t2.Field(fi.index).IsExported() {
+			fields2[fi.name] = fi
+		}
+	}
+
+	type looseField struct {
+		name   string
+		redact bool
+		f1, f2 *reflect.Value
+	}
+
+	var fields []looseField
+	matched := map[string]bool{}
+
+	for _, fi := range fieldsOf(t1) {
+
+		sf := t1.Field(fi.index)
+		if !sf.IsExported() {
+			continue
+		}
+
+		f1 := v1.Field(fi.index)
+		lf := looseField{name: fi.name, redact: fi.redact, f1: &f1}
+
+		if fi2, ok := fields2[fi.name]; ok && compatible(sf.Type, t2.Field(fi2.index).Type) {
+			f2 := v2.Field(fi2.index)
+			lf.f2 = &f2
+			lf.redact = lf.redact || fi2.redact
+			matched[fi.name] = true
+		}
+
+		fields = append(fields, lf)
+	}
+
+	for _, fi := range fieldsOf(t2) {
+
+		if !t2.Field(fi.index).IsExported() || matched[fi.name] {
+			continue
+		}
+
+		f2 := v2.Field(fi.index)
+		fields = append(fields, looseField{name: fi.name, redact: fi.redact, f2: &f2})
+	}
+
+	// A field deleted for changing kind stays ahead of the
+	// field of the same name added in its place.
+	if d.opts.sortFields {
+		sort.SliceStable(fields, func(i, j int) bool {
+			return fields[i].name < fields[j].name
+		})
+	}
+
+	for _, lf := range fields {
+		if err := d.diffLooseField(lf.name, lf.redact, lf.f1, lf.f2); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) diffLooseField(name string, redact bool, f1, f2 *reflect.Value) error {
+
+	redacting := d.redacting
+	if redact {
+		d.redacting = true
+	}
+
+	d.pushPath(fieldSegment(name))
+	err := d.diff(f1, f2)
+	if err != nil {
+		return err
+	}
+	d.popPath()
+	d.redacting = redacting
+
+	return nil
+}
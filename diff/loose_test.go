@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestObjectsLoose(t *testing.T) {
+
+	type addressV1 struct {
+		City string
+	}
+	type addressV2 struct {
+		City     string
+		Postcode string
+	}
+	type userV1 struct {
+		Name     string
+		Age      int
+		Email    string
+		Address  addressV1
+		Friends  []addressV1
+		password string
+	}
+	type userV2 struct {
+		Name     string
+		Age      string
+		Address  addressV2
+		Friends  []addressV2
+		Phone    string
+		Password string `diff:"redact"`
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		want   []string
+	}{
+		{
+			userV1{
+				Name:     "Ann",
+				Age:      30,
+				Email:    "ann@example.com",
+				Address:  addressV1{"Oslo"},
+				Friends:  []addressV1{{"Rome"}},
+				password: "hunter2",
+			},
+			userV2{
+				Name:     "Anne",
+				Age:      "30",
+				Address:  addressV2{"Oslo", "0150"},
+				Friends:  []addressV2{{"Rome", ""}, {"Bern", ""}},
+				Phone:    "555",
+				Password: "hunter2",
+			},
+			[]string{
+				`.Name changed from "Ann" to "Anne"`,
+				`.Age deleted 30`,
+				`.Email deleted "ann@example.com"`,
+				`.Address.Postcode added "0150"`,
+				`.Friends[0].Postcode added ""`,
+				`.Friends[1].City added "Bern"`,
+				`.Friends[1].Postcode added ""`,
+				`.Age added "30"`,
+				`.Phone added "555"`,
+				`.Password added [REDACTED]`,
+			},
+		},
+		{
+			map[string]addressV1{"a": {"Oslo"}},
+			map[string]addressV2{"a": {"Oslo", ""}},
+			[]string{
+				`["a"].Postcode added ""`,
+			},
+		},
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.1", 30},
+			[]string{
+				`.Version changed from "0.0.0" to "0.0.1"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsLoose(c.before, c.after)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsLoose(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestObjectsLooseSorted(t *testing.T) {
+
+	type userV1 struct {
+		Name  string
+		Age   int
+		Email string
+	}
+	type userV2 struct {
+		Phone string
+		Email string
+		Age   string
+		Name  string
+	}
+
+	before := userV1{"Ann", 30, "ann@example.com"}
+	after := userV2{"555", "ann@example.org", "30", "Ann"}
+	want := []string{
+		`.Age deleted 30`,
+		`.Age added "30"`,
+		`.Email changed from "ann@example.com" to "ann@example.org"`,
+		`.Phone added "555"`,
+	}
+
+	got, err := ObjectsLoose(before, after, WithSortedFields())
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ObjectsLoose(%v, %v, WithSortedFields())\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			before, after, got, err, want)
+	}
+}
+
+func TestObjectsLooseErrors(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+	}{
+		{1, 2},
+		{config{}, []int{}},
+		{map[string]int{}, map[int]int{}},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsLoose(c.before, c.after)
+		if err == nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsLoose(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted nil, error",
+				c.before, c.after, got, err)
+		}
+	}
+}
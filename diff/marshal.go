@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+/*
+isMarshaler reports whether t, or a pointer to t, implements
+json.Marshaler or encoding.TextMarshaler.
+*/
+func isMarshaler(t reflect.Type) bool {
+	p := reflect.PtrTo(t)
+	return t.Implements(jsonMarshalerType) || p.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || p.Implements(textMarshalerType)
+}
+
+/*
+marshalOf returns i encoded by its MarshalJSON method or,
+failing that, its MarshalText method. Methods with pointer
+receivers are included. If neither method exists or the
+encoding fails, marshalOf returns false.
+*/
+func marshalOf(i interface{}) (string, bool) {
+
+	if m, ok := implementer(i, jsonMarshalerType); ok {
+		if b, err := m.(json.Marshaler).MarshalJSON(); err == nil {
+			return string(b), true
+		}
+	}
+
+	if m, ok := implementer(i, textMarshalerType); ok {
+		if b, err := m.(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(b), true
+		}
+	}
+
+	return "", false
+}
+
+/*
+implementer returns i if it implements iface, or a pointer to
+a copy of i if that does instead. Nil pointers are skipped as
+the methods are likely to dereference them.
+*/
+func implementer(i interface{}, iface reflect.Type) (interface{}, bool) {
+
+	v := reflect.ValueOf(i)
+	if !v.IsValid() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, false
+	}
+
+	if v.Type().Implements(iface) {
+		return i, true
+	}
+
+	if reflect.PtrTo(v.Type()).Implements(iface) {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface(), true
+	}
+
+	return nil, false
+}
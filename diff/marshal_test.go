@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+type accountID struct {
+	shard int
+	seq   int
+}
+
+func (id accountID) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(fmt.Sprintf("acct-%d-%d", id.shard, id.seq))), nil
+}
+
+type tier int
+
+func (t *tier) MarshalText() ([]byte, error) {
+	switch *t {
+	case 1:
+		return []byte("gold"), nil
+	case 2:
+		return []byte("silver"), nil
+	}
+	return nil, errors.New("unknown tier")
+}
+
+func (t tier) String() string {
+	return "tier " + strconv.Itoa(int(t))
+}
+
+// anyJSON is a comparable type which may hold values that
+// aren't, such as slices.
+type anyJSON struct {
+	V interface{}
+}
+
+func (a anyJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.V)
+}
+
+func TestWithMarshalers(t *testing.T) {
+
+	type account struct {
+		ID   accountID
+		Tier tier
+		Raw  anyJSON
+	}
+
+	before := account{ID: accountID{1, 2}, Tier: 1}
+	after := account{ID: accountID{1, 3}, Tier: 2}
+
+	cases := []struct {
+		before account
+		after  account
+		opts   []Option
+		want   []string
+	}{
+		{
+			before,
+			after,
+			nil,
+			[]string{
+				`.ID.seq changed from 2 to 3`,
+				`.Tier changed from tier 1 to tier 2`,
+			},
+		},
+		{
+			before,
+			after,
+			[]Option{WithMarshalers()},
+			[]string{
+				`.ID changed from "acct-1-2" to "acct-1-3"`,
+				`.Tier changed from gold to silver`,
+			},
+		},
+
+		// Leaves holding uncomparable values don't panic.
+		{
+			account{Raw: anyJSON{[]int{1}}},
+			account{Raw: anyJSON{[]int{2}}},
+			[]Option{WithMarshalers()},
+			[]string{`.Raw changed from [1] to [2]`},
+		},
+		{
+			account{Raw: anyJSON{[]int{1}}},
+			account{Raw: anyJSON{[]int{2}}},
+			[]Option{WithLeafTypes(anyJSON{})},
+			[]string{`.Raw changed from {[1]} to {[2]}`},
+		},
+		{
+			account{Raw: anyJSON{[]int{1}}},
+			account{Raw: anyJSON{[]int{1}}},
+			[]Option{WithLeafTypes(anyJSON{})},
+			nil,
+		},
+
+		// Failed encodings fall back to WithStringer.
+		{
+			account{Tier: 1},
+			account{Tier: 3},
+			[]Option{WithMarshalers(), WithStringer()},
+			[]string{`.Tier changed from gold to tier 3`},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+/*
+StrategicMergePatch returns a Kubernetes strategic merge
+patch which, applied to before, yields after. It is suitable
+for sending to the API server with a patch type of
+application/strategic-merge-patch+json.
+
+The objects are compared as their JSON encodings, as with
+WithJSONRoundTrip, and must both encode as JSON objects.
+Fields that are removed are set to null in the patch. Lists
+are replaced as a whole unless the struct field holding them
+is tagged as the Kubernetes API types are:
+
+	Containers []Container `json:"containers" patchStrategy:"merge" patchMergeKey:"name"`
+
+Elements of such lists are matched by their merge key, so
+that only the fields of an element that changed are given
+and an element that was removed is deleted with a
+{"$patch": "delete"} directive. Lists of primitive values
+tagged with a merge strategy alone, such as finalizers, have
+the values added given and those removed listed with a
+$deleteFromPrimitiveList directive.
+
+Struct tags are read from the type of after. Objects with no
+differences give the empty patch {}.
+*/
+func StrategicMergePatch(before, after interface{}) ([]byte, error) {
+
+	j1, err := jsonValue(reflect.ValueOf(before))
+	if err != nil {
+		return nil, err
+	}
+	j2, err := jsonValue(reflect.ValueOf(after))
+	if err != nil {
+		return nil, err
+	}
+
+	o1, ok1 := j1.Interface().(map[string]interface{})
+	o2, ok2 := j2.Interface().(map[string]interface{})
+	if !ok1 || !ok2 {
+		return nil, errors.New("before and after must encode as JSON objects")
+	}
+
+	return json.Marshal(objectPatch(o1, o2, reflect.TypeOf(after)))
+}
+
+/*
+objectPatch returns the patch from the JSON object o1 to o2,
+which were encoded from a value of type t. The type is nil if
+it isn't known.
+*/
+func objectPatch(o1, o2 map[string]interface{}, t reflect.Type) map[string]interface{} {
+
+	patch := map[string]interface{}{}
+
+	for k := range o1 {
+		if _, ok := o2[k]; !ok {
+			patch[k] = nil
+		}
+	}
+
+	for k, v2 := range o2 {
+
+		v1, ok := o1[k]
+		if !ok {
+			patch[k] = v2
+			continue
+		}
+
+		ft, strategy, mergeKey := jsonField(t, k)
+
+		switch v1 := v1.(type) {
+
+		case map[string]interface{}:
+			if v2, ok := v2.(map[string]interface{}); ok {
+				if p := objectPatch(v1, v2, ft); len(p) > 0 {
+					patch[k] = p
+				}
+				continue
+			}
+
+		case []interface{}:
+			v2, ok := v2.([]interface{})
+			if !ok || !hasOption(strategy, "merge") {
+				break
+			}
+			if mergeKey != "" {
+				if p, ok := mergeListPatch(v1, v2, sliceElem(ft), mergeKey); ok {
+					if len(p) > 0 {
+						patch[k] = p
+					}
+					continue
+				}
+				break
+			}
+			added, deleted := primitiveListPatch(v1, v2)
+			if len(added) > 0 {
+				patch[k] = added
+			}
+			if len(deleted) > 0 {
+				patch["$deleteFromPrimitiveList/"+k] = deleted
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(v1, v2) {
+			patch[k] = v2
+		}
+	}
+
+	return patch
+}
+
+/*
+mergeListPatch returns the patch from l1 to l2, lists of
+objects identified by the value of mergeKey, whose elements
+are of type t. It reports false if any element isn't an
+object with a merge key, in which case the list must be
+replaced.
+*/
+func mergeListPatch(l1, l2 []interface{}, t reflect.Type, mergeKey string) ([]interface{}, bool) {
+
+	keyOf := func(e interface{}) (interface{}, map[string]interface{}, bool) {
+		o, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, nil, false
+		}
+		k, ok := o[mergeKey]
+		if !ok {
+			return nil, nil, false
+		}
+		switch k.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, nil, false
+		}
+		return k, o, true
+	}
+
+	before := map[interface{}]map[string]interface{}{}
+	for _, e := range l1 {
+		k, o, ok := keyOf(e)
+		if !ok {
+			return nil, false
+		}
+		before[k] = o
+	}
+
+	patch := []interface{}{}
+	after := map[interface{}]bool{}
+
+	for _, e := range l2 {
+		k, o, ok := keyOf(e)
+		if !ok {
+			return nil, false
+		}
+		after[k] = true
+		o1, ok := before[k]
+		if !ok {
+			patch = append(patch, o)
+			continue
+		}
+		if p := objectPatch(o1, o, t); len(p) > 0 {
+			p[mergeKey] = k
+			patch = append(patch, p)
+		}
+	}
+
+	for _, e := range l1 {
+		k, _, _ := keyOf(e)
+		if !after[k] {
+			patch = append(patch, map[string]interface{}{
+				"$patch": "delete",
+				mergeKey: k,
+			})
+		}
+	}
+
+	return patch, true
+}
+
+// primitiveListPatch returns the values in l2 but not l1
+// and those in l1 but not l2.
+func primitiveListPatch(l1, l2 []interface{}) (added, deleted []interface{}) {
+
+	contains := func(l []interface{}, v interface{}) bool {
+		for _, e := range l {
+			if reflect.DeepEqual(e, v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, v := range l2 {
+		if !contains(l1, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range l1 {
+		if !contains(l2, v) {
+			deleted = append(deleted, v)
+		}
+	}
+
+	return added, deleted
+}
+
+/*
+jsonField returns the type of the value encoded as the JSON
+member name of a value of type t, along with the
+patchStrategy and patchMergeKey tags of the struct field
+holding it. The type is nil if it isn't known.
+*/
+func jsonField(t reflect.Type, name string) (ft reflect.Type, strategy, mergeKey string) {
+
+	t = deref(t)
+	if t != nil && t.Kind() == reflect.Map {
+		return t.Elem(), "", ""
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, "", ""
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+
+		// Embedded structs without a name are inlined, as
+		// are those tagged inline as Kubernetes types are.
+		if sf.Anonymous && tag[0] == "" || hasOption(sf.Tag.Get("json"), "inline") {
+			if ft, s, k := jsonField(sf.Type, name); ft != nil {
+				return ft, s, k
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		n := tag[0]
+		if n == "" {
+			n = sf.Name
+		}
+		if n == name {
+			return sf.Type, sf.Tag.Get("patchStrategy"), sf.Tag.Get("patchMergeKey")
+		}
+	}
+
+	return nil, "", ""
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func sliceElem(t reflect.Type) reflect.Type {
+	t = deref(t)
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		return t.Elem()
+	}
+	return nil
+}
+
+// hasOption reports whether the comma separated list
+// opts contains opt.
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if strings.TrimSpace(o) == opt {
+			return true
+		}
+	}
+	return false
+}
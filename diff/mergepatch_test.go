@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+type k8sMeta struct {
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Finalizers []string          `json:"finalizers,omitempty" patchStrategy:"merge"`
+}
+
+type k8sTypeMeta struct {
+	Kind string `json:"kind,omitempty"`
+}
+
+type k8sEnv struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type k8sContainer struct {
+	Name  string   `json:"name"`
+	Image string   `json:"image"`
+	Args  []string `json:"args,omitempty"`
+	Env   []k8sEnv `json:"env,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+}
+
+type k8sPod struct {
+	k8sTypeMeta `json:",inline"`
+	Metadata    k8sMeta        `json:"metadata"`
+	Containers  []k8sContainer `json:"containers" patchStrategy:"merge" patchMergeKey:"name"`
+	Volumes     map[string]k8sMeta
+}
+
+func TestStrategicMergePatch(t *testing.T) {
+
+	pod := func(edit func(p *k8sPod)) k8sPod {
+		p := k8sPod{
+			k8sTypeMeta: k8sTypeMeta{"Pod"},
+			Metadata: k8sMeta{
+				Name:       "web",
+				Labels:     map[string]string{"app": "web", "tier": "front"},
+				Finalizers: []string{"a", "b"},
+			},
+			Containers: []k8sContainer{
+				{Name: "app", Image: "app:1", Args: []string{"-v"}, Env: []k8sEnv{{"A", "1"}, {"B", "2"}}},
+				{Name: "sidecar", Image: "proxy:1"},
+			},
+		}
+		if edit != nil {
+			edit(&p)
+		}
+		return p
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		want   string
+	}{
+		{
+			pod(nil),
+			pod(nil),
+			`{}`,
+		},
+		{
+			pod(nil),
+			pod(func(p *k8sPod) {
+				p.Metadata.Labels = map[string]string{"app": "web", "env": "prod"}
+				p.Metadata.Finalizers = []string{"b", "c"}
+			}),
+			`{"metadata":{"$deleteFromPrimitiveList/finalizers":["a"],"finalizers":["c"],"labels":{"env":"prod","tier":null}}}`,
+		},
+		{
+			pod(nil),
+			pod(func(p *k8sPod) {
+				p.Containers[0].Image = "app:2"
+				p.Containers[0].Args = []string{"-q"}
+				p.Containers[0].Env = []k8sEnv{{"A", "1"}, {"B", "3"}}
+				p.Containers = append(p.Containers[:1], k8sContainer{Name: "log", Image: "log:1"})
+			}),
+			`{"containers":[` +
+				`{"args":["-q"],"env":[{"name":"B","value":"3"}],"image":"app:2","name":"app"},` +
+				`{"image":"log:1","name":"log"},` +
+				`{"$patch":"delete","name":"sidecar"}]}`,
+		},
+		{
+			pod(nil),
+			pod(func(p *k8sPod) {
+				p.Kind = "Deployment"
+				p.Volumes = map[string]k8sMeta{"data": {Name: "data"}}
+			}),
+			`{"Volumes":{"data":{"name":"data"}},"kind":"Deployment"}`,
+		},
+		{
+			pod(func(p *k8sPod) {
+				p.Volumes = map[string]k8sMeta{"data": {Name: "data", Finalizers: []string{"x"}}}
+			}),
+			pod(func(p *k8sPod) {
+				p.Volumes = map[string]k8sMeta{"data": {Name: "data", Finalizers: []string{"y"}}}
+			}),
+			`{"Volumes":{"data":{"$deleteFromPrimitiveList/finalizers":["x"],"finalizers":["y"]}}}`,
+		},
+		{
+			map[string]interface{}{"a": []interface{}{1, 2}, "b": 1},
+			map[string]interface{}{"a": []interface{}{2}},
+			`{"a":[2],"b":null}`,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := StrategicMergePatch(c.before, c.after)
+		if string(got) != c.want || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"StrategicMergePatch(%v, %v)\n"+
+					"    return %s, %v\n"+
+					"    wanted %s, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+
+	if _, err := StrategicMergePatch([]int{1}, []int{2}); err == nil {
+		t.Errorf("StrategicMergePatch of slices returned nil error")
+	}
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+/*
+Metrics receives measurements of the diffs performed by this
+package, for exporting to a monitoring system such as
+Prometheus. Its methods may be called concurrently and should
+return quickly. See SetMetrics.
+*/
+type Metrics interface {
+
+	// DiffRun is called once for each call to a function
+	// of this package that performs a diff, such as Objects,
+	// Equal, or Diffs, with the time it took and the error
+	// it returned, if any.
+	DiffRun(elapsed time.Duration, err error)
+
+	// DifferenceFound is called for each difference found,
+	// other than those of kind Same. Functions that stop at
+	// the first difference, such as Equal, find only one.
+	DifferenceFound(kind Kind)
+}
+
+type metricsHolder struct {
+	m Metrics
+}
+
+var metrics atomic.Pointer[metricsHolder]
+
+/*
+SetMetrics sets the Metrics that every diff is reported to,
+replacing any set previously. Calling it with nil stops
+reporting. No Metrics are set by default.
+
+For example, with the Prometheus client library:
+
+	type promMetrics struct {
+		runs    *prometheus.HistogramVec
+		changes *prometheus.CounterVec
+	}
+
+	func (p promMetrics) DiffRun(elapsed time.Duration, err error) {
+		p.runs.WithLabelValues(strconv.FormatBool(err == nil)).Observe(elapsed.Seconds())
+	}
+
+	func (p promMetrics) DifferenceFound(kind diff.Kind) {
+		p.changes.WithLabelValues(kind.String()).Inc()
+	}
+
+The histogram's count then gives the number of diffs run.
+*/
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&metricsHolder{m})
+}
+
+func currentMetrics() Metrics {
+	if h := metrics.Load(); h != nil {
+		return h.m
+	}
+	return nil
+}
+
+// observe reports a diff begun at start that returned *err.
+func observe(start time.Time, err *error) {
+	if m := currentMetrics(); m != nil {
+		m.DiffRun(time.Since(start), *err)
+	}
+}
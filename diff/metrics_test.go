@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	mu    sync.Mutex
+	runs  []error
+	kinds []Kind
+}
+
+func (m *testMetrics) DiffRun(elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = append(m.runs, err)
+}
+
+func (m *testMetrics) DifferenceFound(kind Kind) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kinds = append(m.kinds, kind)
+}
+
+func TestSetMetrics(t *testing.T) {
+
+	before := map[string]int{"a": 1, "b": 2}
+	after := map[string]int{"a": 2, "c": 3}
+
+	cases := []struct {
+		run       func() error
+		wantRuns  int
+		wantErr   bool
+		wantKinds []Kind
+	}{
+		{
+			func() error {
+				_, err := Objects(before, after, WithUnchanged())
+				return err
+			},
+			1,
+			false,
+			[]Kind{Change, Delete, Add},
+		},
+		{
+			func() error {
+				_, err := Equal(before, after)
+				return err
+			},
+			1,
+			false,
+			[]Kind{Change},
+		},
+		{
+			func() error {
+				_, err := Diffs(before, 1)
+				return err
+			},
+			1,
+			true,
+			nil,
+		},
+	}
+
+	defer SetMetrics(nil)
+
+	for i, c := range cases {
+
+		m := &testMetrics{}
+		SetMetrics(m)
+
+		c.run()
+
+		errs := 0
+		for _, err := range m.runs {
+			if err != nil {
+				errs++
+			}
+		}
+		if len(m.runs) != c.wantRuns || (errs > 0) != c.wantErr || !reflect.DeepEqual(m.kinds, c.wantKinds) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Metrics received runs %v, differences %v\n"+
+					"    wanted %d runs with error %v, differences %v",
+				m.runs, m.kinds, c.wantRuns, c.wantErr, c.wantKinds)
+		}
+	}
+
+	// Nothing is reported once the Metrics are removed.
+	m := &testMetrics{}
+	SetMetrics(m)
+	SetMetrics(nil)
+	Objects(before, after)
+	if len(m.runs) > 0 || len(m.kinds) > 0 {
+		t.Errorf("Metrics received %v, %v after SetMetrics(nil)", m.runs, m.kinds)
+	}
+}
@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/*
+diffSequenceMoves diffs two slices or arrays when WithMoves
+is in use. Equal elements are paired up, earliest first, and
+the largest set of pairs that keep their relative order is
+taken to have stayed put, along with any pair whose elements
+are at the same index. The remaining pairs have moved.
+*/
+func (d *differ) diffSequenceMoves(v1, v2 *reflect.Value) error {
+
+	n1 := v1.Len()
+	n2 := v2.Len()
+
+	queues := map[interface{}][]int{}
+	for i := 0; i < n1; i++ {
+		k := elemKey(v1.Index(i))
+		queues[k] = append(queues[k], i)
+	}
+
+	match1 := make([]int, n1)
+	match2 := make([]int, n2)
+	for i := range match1 {
+		match1[i] = -1
+	}
+	for j := range match2 {
+		match2[j] = -1
+	}
+
+	// Pairs are ordered by their index in v2.
+	var pairs [][2]int
+	for j := 0; j < n2; j++ {
+		k := elemKey(v2.Index(j))
+		if q := queues[k]; len(q) > 0 {
+			queues[k] = q[1:]
+			pairs = append(pairs, [2]int{q[0], j})
+			match1[q[0]] = j
+			match2[j] = q[0]
+		}
+	}
+
+	// An element whose index is unchanged hasn't moved even
+	// if it lies outside the subsequence.
+	moved := make([]bool, n1)
+	stayed := increasing(pairs)
+	for p, pair := range pairs {
+		if !stayed[p] && pair[0] != pair[1] {
+			moved[pair[0]] = true
+		}
+	}
+
+	longest := n1
+	if n2 > longest {
+		longest = n2
+	}
+
+	for k := 0; k < longest; k++ {
+
+		if k < n1 && moved[k] {
+			e1 := v1.Index(k)
+			e2 := v2.Index(match1[k])
+			d.pushPath(indexSegment(k))
+			err := d.report(Diff{From: k, To: match1[k]}, Move, &e1, &e2)
+			if err != nil {
+				return err
+			}
+			d.popPath()
+		}
+
+		// Elements that stayed put are only visited
+		// in order to report them as unchanged.
+		if d.opts.unchanged && k < n1 && match1[k] >= 0 && !moved[k] {
+			e1 := v1.Index(k)
+			e2 := v2.Index(match1[k])
+			d.pushPath(indexSegment(k))
+			err := d.diff(&e1, &e2)
+			if err != nil {
+				return err
+			}
+			d.popPath()
+		}
+
+		var elem1 *reflect.Value
+		var elem2 *reflect.Value
+		if k < n1 && match1[k] < 0 {
+			e1 := v1.Index(k)
+			elem1 = &e1
+		}
+		if k < n2 && match2[k] < 0 {
+			e2 := v2.Index(k)
+			elem2 = &e2
+		}
+		if elem1 == nil && elem2 == nil {
+			continue
+		}
+
+		d.pushPath(indexSegment(k))
+		err := d.diff(elem1, elem2)
+		if err != nil {
+			return err
+		}
+		d.popPath()
+	}
+
+	return nil
+}
+
+/*
+elemKey returns a value usable as a map key that is the same
+for equal elements. Values that can't safely be used as keys
+are represented by their Go syntax.
+*/
+func elemKey(v reflect.Value) interface{} {
+	if safelyComparable(v.Type()) {
+		return v.Interface()
+	}
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+/*
+increasing marks the members of the longest subsequence of
+pairs whose first elements are strictly increasing. Of the
+subsequences of that length, one with the most pairs whose
+elements are at the same index is chosen, so that elements
+which haven't moved are preferred as those that stayed put.
+*/
+func increasing(pairs [][2]int) []bool {
+
+	// best[p] is the best subsequence ending with pair p.
+	type subsequence struct {
+		length int
+		fixed  int
+		prev   int
+	}
+	best := make([]subsequence, len(pairs))
+	better := func(a, b subsequence) bool {
+		return a.length > b.length || a.length == b.length && a.fixed > b.fixed
+	}
+
+	// atLeast reports whether the subsequence ending with pair
+	// p is at least as good as that ending with q, if any. Of
+	// equally good ones, that ending with the later is kept.
+	atLeast := func(p, q int) bool {
+		return q < 0 || better(best[p], best[q]) || p > q && !better(best[q], best[p])
+	}
+
+	n := 0
+	for _, pair := range pairs {
+		if pair[0] >= n {
+			n = pair[0] + 1
+		}
+	}
+
+	// tree is a Fenwick tree over the first elements of the
+	// pairs, holding the index of the pair ending the best
+	// subsequence among those ending at or below each, so
+	// that the best a pair may extend is found in O(log n).
+	tree := make([]int, n+1)
+	for i := range tree {
+		tree[i] = -1
+	}
+	end := -1
+
+	for p, pair := range pairs {
+
+		s := subsequence{length: 1, prev: -1}
+		for i := pair[0]; i > 0; i -= i & -i {
+			if q := tree[i]; q >= 0 && atLeast(q, s.prev) {
+				s.prev = q
+			}
+		}
+		if s.prev >= 0 {
+			s.length = best[s.prev].length + 1
+			s.fixed = best[s.prev].fixed
+		}
+		if pair[0] == pair[1] {
+			s.fixed++
+		}
+		best[p] = s
+
+		for i := pair[0] + 1; i <= n; i += i & -i {
+			if atLeast(p, tree[i]) {
+				tree[i] = p
+			}
+		}
+		if atLeast(p, end) {
+			end = p
+		}
+	}
+
+	marked := make([]bool, len(pairs))
+	for p := end; p >= 0; p = best[p].prev {
+		marked[p] = true
+	}
+
+	return marked
+}
@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithMoves(t *testing.T) {
+
+	type playlist struct {
+		Songs []string
+	}
+
+	cases := []struct {
+		before []string
+		after  []string
+		want   []string
+	}{
+		// Unchanged.
+		{
+			[]string{"a", "b", "c"},
+			[]string{"a", "b", "c"},
+			nil,
+		},
+
+		// Single element moved to the end.
+		{
+			[]string{"a", "b", "c", "d"},
+			[]string{"b", "c", "d", "a"},
+			[]string{`.Songs[0] moved to [3]`},
+		},
+
+		// Insertion doesn't move everything after it.
+		{
+			[]string{"a", "b"},
+			[]string{"x", "a", "b"},
+			[]string{`.Songs[0] added "x"`},
+		},
+
+		// Swap with a change and a deletion.
+		{
+			[]string{"a", "b", "c", "d"},
+			[]string{"c", "b", "z"},
+			[]string{
+				`.Songs[0] deleted "a"`,
+				`.Songs[2] moved to [0]`,
+				`.Songs[2] added "z"`,
+				`.Songs[3] deleted "d"`,
+			},
+		},
+
+		// Reversal leaves the middle element where it is.
+		{
+			[]string{"a", "b", "c"},
+			[]string{"c", "b", "a"},
+			[]string{
+				`.Songs[0] moved to [2]`,
+				`.Songs[2] moved to [0]`,
+			},
+		},
+
+		// An element at the same index never moves, even
+		// if it's out of order with those that stay put.
+		{
+			[]string{"a", "b", "c", "d", "e"},
+			[]string{"d", "e", "c", "a", "b"},
+			[]string{
+				`.Songs[3] moved to [0]`,
+				`.Songs[4] moved to [1]`,
+			},
+		},
+
+		// In-place change.
+		{
+			[]string{"a", "b", "c"},
+			[]string{"a", "x", "c"},
+			[]string{`.Songs[1] changed from "b" to "x"`},
+		},
+	}
+
+	for i, c := range cases {
+		before := playlist{c.before}
+		after := playlist{c.after}
+		got, err := Objects(before, after, WithMoves())
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithMoves())\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestMoveDiff(t *testing.T) {
+
+	before := []int{1, 2, 3}
+	after := []int{2, 3, 1}
+
+	got, err := Diffs(before, after, WithMoves())
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Diffs(%v, %v, WithMoves()) returned %v, %v", before, after, got, err)
+	}
+	d := got[0]
+	if d.Name != "[0]" || d.From != 0 || d.To != 2 || d.Before != 1 || d.After != 1 {
+		t.Errorf("Diffs(%v, %v, WithMoves()) returned %+v", before, after, d)
+	}
+}
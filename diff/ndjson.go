@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+WriteNDJSON diffs before and after as Diffs does and writes
+the differences to w as newline-delimited JSON, one object
+per line holding the kind and path of a difference along
+with its before and after values and their types:
+
+	{"kind":"change","path":".Name","before":"Ann","after":"Anne","before_type":"string","after_type":"string"}
+	{"kind":"add","path":".Tags[1]","after":"b","after_type":"string"}
+
+Before and after are BeforeValue and AfterValue encoded as
+JSON, so numbers, structs, and so on keep their structure,
+and a nil value is null. Values that can't be encoded, such
+as funcs, are given as the string a template would print.
+Members for values absent from one side are omitted. Nothing
+is written if the arguments can't be diffed.
+*/
+func WriteNDJSON(w io.Writer, before, after interface{}, opts ...Option) error {
+
+	type change struct {
+		Kind       string          `json:"kind"`
+		Path       string          `json:"path"`
+		Before     json.RawMessage `json:"before,omitempty"`
+		After      json.RawMessage `json:"after,omitempty"`
+		BeforeType string          `json:"before_type,omitempty"`
+		AfterType  string          `json:"after_type,omitempty"`
+	}
+
+	diffs, err := Diffs(before, after, opts...)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, d := range diffs {
+		before, after := jsonValues(d)
+		c := change{
+			Kind:       d.Kind.String(),
+			Path:       d.Name,
+			Before:     before,
+			After:      after,
+			BeforeType: d.BeforeType,
+			AfterType:  d.AfterType,
+		}
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+jsonValues returns the BeforeValue and AfterValue of d
+encoded as JSON, or nil for a value absent from its side.
+Values that can't be encoded are given as the text of
+Before or After instead.
+*/
+func jsonValues(d Diff) (before, after json.RawMessage) {
+
+	encode := func(raw, rendered interface{}) json.RawMessage {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(raw); err != nil {
+			buf.Reset()
+			enc.Encode(fmt.Sprint(rendered))
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	}
+
+	if d.Kind != Add {
+		before = encode(d.BeforeValue, d.Before)
+	}
+	if d.Kind != Delete {
+		after = encode(d.AfterValue, d.After)
+	}
+
+	return before, after
+}
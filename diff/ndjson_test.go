@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+
+	type point struct {
+		X, Y int
+	}
+	type user struct {
+		Name  string
+		Email interface{}
+		Tags  []string
+		Home  point
+		Pos   interface{}
+		Z     complex128
+	}
+
+	before := user{"Ann", "<a@example.com>", []string{"a", "b"}, point{1, 2}, point{1, 2}, 1i}
+	after := user{"Anne", nil, []string{"a"}, point{1, 3}, 2.5, 2i}
+
+	want := `{"kind":"change","path":".Name","before":"Ann","after":"Anne",` +
+		`"before_type":"string","after_type":"string"}` + "\n" +
+		`{"kind":"change","path":".Email","before":"<a@example.com>","after":null,` +
+		`"before_type":"string"}` + "\n" +
+		`{"kind":"delete","path":".Tags[1]","before":"b","before_type":"string"}` + "\n" +
+		`{"kind":"change","path":".Home.Y","before":2,"after":3,` +
+		`"before_type":"int","after_type":"int"}` + "\n" +
+		`{"kind":"change","path":".Pos","before":{"X":1,"Y":2},"after":2.5,` +
+		`"before_type":"diff.point","after_type":"float64"}` + "\n" +
+		`{"kind":"change","path":".Z","before":"(0+1i)","after":"(0+2i)",` +
+		`"before_type":"complex128","after_type":"complex128"}` + "\n"
+
+	var buf bytes.Buffer
+	err := WriteNDJSON(&buf, before, after)
+	if got := buf.String(); got != want || err != nil {
+		t.Errorf(
+			"WriteNDJSON(w, %v, %v)\n"+
+				"    wrote %s, returned %v\n"+
+				"    wanted %s, nil",
+			before, after, got, err, want)
+	}
+
+	buf.Reset()
+	err = WriteNDJSON(&buf, 1, 2)
+	if buf.Len() != 0 || !errors.Is(err, ErrNotObject) {
+		t.Errorf(
+			"WriteNDJSON(w, 1, 2)\n"+
+				"    wrote %q, returned %v\n"+
+				"    wanted nothing, ErrNotObject",
+			buf.String(), err)
+	}
+}
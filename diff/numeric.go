@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"math"
+	"math/cmplx"
+	"reflect"
+)
+
+/*
+isNaN reports whether v, or the value held in it if it is an
+interface, is a floating point NaN or a complex number with a
+NaN part.
+*/
+func isNaN(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return cmplx.IsNaN(v.Complex())
+	}
+	return false
+}
+
+/*
+withinTolerance reports whether v1 and v2, or the values held
+in them if they are interfaces, are no further apart than tol.
+The distance between complex numbers is the modulus of their
+difference. It returns false for ok if they aren't floats or
+complex numbers of the same type.
+*/
+func withinTolerance(v1, v2 reflect.Value, tol float64) (equal, ok bool) {
+
+	if v1.Kind() == reflect.Interface {
+		v1 = v1.Elem()
+	}
+	if v2.Kind() == reflect.Interface {
+		v2 = v2.Elem()
+	}
+	if !v1.IsValid() || !v2.IsValid() || v1.Type() != v2.Type() {
+		return false, false
+	}
+
+	switch {
+	case isFloat(v1.Kind()):
+		return math.Abs(v1.Float()-v2.Float()) <= tol, true
+	case isComplex(v1.Kind()):
+		return cmplx.Abs(v1.Complex()-v2.Complex()) <= tol, true
+	}
+
+	return false, false
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isComplex(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
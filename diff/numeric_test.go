@@ -0,0 +1,115 @@
+package diff
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"testing"
+)
+
+type signal struct {
+	Gain   float64
+	Level  float32
+	Phase  complex128
+	Offset complex64
+	Extra  []interface{}
+}
+
+func TestWithTolerance(t *testing.T) {
+
+	before := signal{1.0, 2.0, complex(1, 1), complex(0, 0), []interface{}{1.0, float32(1)}}
+	after := signal{1.0005, 2.5, complex(1.0003, 0.9996), complex(0, 0.1), []interface{}{1.0001, 1.0001}}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Gain changed from 1 to 1.0005`,
+				`.Level changed from 2 to 2.5`,
+				`.Phase changed from (1+1i) to (1.0003+0.9996i)`,
+				`.Offset changed from (0+0i) to (0+0.1i)`,
+				`.Extra[0] changed from 1 to 1.0001`,
+				`.Extra[1] changed from 1 to 1.0001`,
+			},
+		},
+		{
+			[]Option{WithTolerance(0.001)},
+			[]string{
+				`.Level changed from 2 to 2.5`,
+				`.Offset changed from (0+0i) to (0+0.1i)`,
+				`.Extra[1] changed from 1 to 1.0001`,
+			},
+		},
+		{
+			[]Option{WithTolerance(0.0004)},
+			[]string{
+				`.Gain changed from 1 to 1.0005`,
+				`.Level changed from 2 to 2.5`,
+				`.Phase changed from (1+1i) to (1.0003+0.9996i)`,
+				`.Offset changed from (0+0i) to (0+0.1i)`,
+				`.Extra[1] changed from 1 to 1.0001`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestComplexValues(t *testing.T) {
+
+	nan := cmplx.NaN()
+	before := signal{Phase: nan, Offset: complex64(complex(math.NaN(), 0))}
+	after := signal{Phase: nan, Offset: 1 + 2i}
+
+	formatter := func(path string, v interface{}) string {
+		if c, ok := v.(complex64); ok {
+			return strconv.FormatComplex(complex128(c), 'f', 2, 64)
+		}
+		return fmt.Sprint(v)
+	}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Phase changed from (NaN+NaNi) to (NaN+NaNi)`,
+				`.Offset changed from (NaN+0i) to (1+2i)`,
+			},
+		},
+		{
+			[]Option{WithNaNEqual(), WithValueFormatter(formatter)},
+			[]string{
+				`.Offset changed from (NaN+0.00i) to (1.00+2.00i)`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package diff
+
+import "reflect"
+
+/*
+OpaqueMode selects how values of kind chan, func, and
+unsafe.Pointer are compared. Their contents can't be
+inspected, so they can only be compared by identity or by
+whether they're nil. See WithOpaque.
+*/
+type OpaqueMode int
+
+const (
+	// OpaqueIdentity compares chans and unsafe.Pointers
+	// by what they point to and considers funcs equal
+	// only if both are nil. It is the default.
+	OpaqueIdentity OpaqueMode = iota
+
+	// OpaqueNil considers values equal if both are nil
+	// or both are non-nil.
+	OpaqueNil
+
+	// OpaqueSkip ignores such values entirely, including
+	// when they're added or deleted.
+	OpaqueSkip
+)
+
+func isOpaque(k reflect.Kind) bool {
+	return k == reflect.Chan || k == reflect.Func || k == reflect.UnsafePointer
+}
+
+/*
+diffOpaque compares chans, funcs, and unsafe.Pointers
+according to the mode set with WithOpaque.
+*/
+func (d *differ) diffOpaque(v1, v2 *reflect.Value) error {
+
+	if d.opts.opaque == OpaqueSkip {
+		return nil
+	}
+
+	if v1 != nil && v2 != nil && isNil(*v1) == isNil(*v2) {
+		if d.opts.unchanged {
+			return d.report(Diff{}, Same, v1, v2)
+		}
+		return nil
+	}
+
+	return d.diffAtom(v1, v2)
+}
+
+func isNil(v reflect.Value) bool {
+	if v.Kind() == reflect.UnsafePointer {
+		return v.Pointer() == 0
+	}
+	return v.IsNil()
+}
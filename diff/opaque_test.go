@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+)
+
+func TestWithOpaque(t *testing.T) {
+
+	type handler struct {
+		Name    string
+		OnEvent func(string)
+		Done    chan struct{}
+		Ptr     unsafe.Pointer
+		Hooks   []func()
+	}
+
+	x := 1
+	before := handler{
+		Name:    "a",
+		OnEvent: func(string) {},
+		Done:    make(chan struct{}),
+		Ptr:     unsafe.Pointer(&x),
+		Hooks:   []func(){func() {}},
+	}
+	after := handler{
+		Name:    "b",
+		OnEvent: func(string) {},
+		Done:    nil,
+		Ptr:     unsafe.Pointer(&x),
+		Hooks:   []func(){func() {}, nil},
+	}
+
+	cases := []struct {
+		mode OpaqueMode
+		want []string
+	}{
+		{
+			OpaqueIdentity,
+			[]string{
+				`.Name changed from "a" to "b"`,
+				`.OnEvent changed`,
+				`.Done changed`,
+				`.Hooks[0] changed`,
+				`.Hooks[1] added`,
+			},
+		},
+		{
+			OpaqueNil,
+			[]string{
+				`.Name changed from "a" to "b"`,
+				`.Done changed`,
+				`.Hooks[1] added`,
+			},
+		},
+		{
+			OpaqueSkip,
+			[]string{
+				`.Name changed from "a" to "b"`,
+			},
+		},
+	}
+
+	// Funcs and chans render as addresses.
+	format := Format{
+		Change: `{{.Name}} changed{{if eq .Name ".Name"}} from {{.Before}} to {{.After}}{{end}}`,
+		Add:    `{{.Name}} added`,
+		Delete: `{{.Name}} deleted`,
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(format, before, after, WithOpaque(c.mode))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, WithOpaque(%d))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, c.mode, got, err, c.want)
+		}
+	}
+
+	equal, err := Equal(before, before, WithOpaque(OpaqueNil))
+	if !equal || err != nil {
+		t.Errorf(
+			"Equal(%v, %v, WithOpaque(OpaqueNil))\n"+
+				"    return %v, %v\n"+
+				"    wanted true, nil",
+			before, before, equal, err)
+	}
+}
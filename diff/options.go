@@ -0,0 +1,843 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/*
+Option configures optional behaviour for Objects, ObjectsF,
+Equal, and ChangedPaths. Options are applied in the order
+they are given.
+*/
+type Option func(*options)
+
+type options struct {
+	kinds     []Kind
+	pathStyle PathStyle
+	locale    string
+
+	maxValueLength int
+	charDiff       int
+	stringContext  int
+	editDistance   bool
+	minDistance    int
+	placeholder    *string
+	snapshotKey    []byte
+	normalizeSpace bool
+	nanEqual       bool
+	tolerance      float64
+	nilAsEmpty     bool
+	byteSummaries  bool
+	timeLocation   *time.Location
+	opaque         OpaqueMode
+	html           bool
+	theme          *Theme
+	stringer       bool
+	unquoted       bool
+	quoted         bool
+	marshalers     bool
+	formatter      func(path string, v interface{}) string
+	transforms     map[reflect.Type]reflect.Value
+	leafTypes      map[reflect.Type]bool
+	flags          map[reflect.Type]map[uint64]string
+	pathFormats    []pathFormat
+	fields         []pathPattern
+
+	aggregateErrors bool
+	promoteFields   bool
+	sortFields      bool
+	relativePaths   bool
+	moves           bool
+	hashPruning     bool
+	unchanged       bool
+	protoNumbers    bool
+	jsonRoundTrip   bool
+
+	span      Span
+	maxEvents int
+
+	ctx context.Context
+
+	workers int
+
+	limits Limits
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+/*
+WithKinds restricts results to differences of the supplied
+kinds. For example, WithKinds(Delete) reports only deletions.
+Calling it with no arguments, or not at all, reports every
+kind.
+*/
+func WithKinds(kinds ...Kind) Option {
+	return func(o *options) {
+		o.kinds = kinds
+	}
+}
+
+/*
+WithPathStyle sets the notation used for Diff.Name and
+the strings returned by ChangedPaths. The default is
+PathDefault.
+*/
+func WithPathStyle(style PathStyle) Option {
+	return func(o *options) {
+		o.pathStyle = style
+	}
+}
+
+/*
+WithPromotedFields names the fields of embedded structs as
+Go promotes them, so that a field Timeout of an embedded
+BaseConfig is named .Timeout rather than .BaseConfig.Timeout.
+This applies to names, including those used with
+WithPathFormat, but Diff.Path still holds a segment for the
+embedded struct. Embedded structs any of whose fields are
+shadowed by, or conflict with, other fields are named in
+full, as are those compared as a whole.
+*/
+func WithPromotedFields() Option {
+	return func(o *options) {
+		o.promoteFields = true
+	}
+}
+
+/*
+WithSortedFields diffs the fields of each struct in
+alphabetical order of their Go names, rather than in the
+order they're declared, so that structs declaring the same
+fields in different orders report their differences in the
+same order. With ObjectsLoose the fields of both structs
+are merged into one alphabetical order.
+*/
+func WithSortedFields() Option {
+	return func(o *options) {
+		o.sortFields = true
+	}
+}
+
+/*
+WithRelativePaths names the differences found by At relative
+to the path given to it, so that with ".Spec" a change to the
+field Image of .Spec is named .Image rather than .Spec.Image.
+Options such as WithPathFormat then see relative paths too.
+It has no effect on other functions.
+*/
+func WithRelativePaths() Option {
+	return func(o *options) {
+		o.relativePaths = true
+	}
+}
+
+/*
+WithWorkers diffs the pairs given to Batch on n goroutines
+at once. Their Results are returned in the same order
+regardless. Functions such as a formatter set with
+WithValueFormatter or a transform set with WithTransform
+must then be safe for concurrent use. A value of n of 1 or
+less diffs pairs one after another, which is the default.
+It has no effect on other functions.
+*/
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+/*
+WithLimits bounds the number of values a diff visits and the
+number of bytes of text it renders, guarding against
+unexpectedly large or deeply nested input, such as that from
+an untrusted source. A diff exceeding a limit stops and
+returns a *PathError wrapping ErrLimit, naming the path at
+which it stopped, unless l.Truncate is set.
+*/
+func WithLimits(l Limits) Option {
+	return func(o *options) {
+		o.limits = l
+	}
+}
+
+/*
+WithLocale selects the Format registered for locale with
+RegisterLocale in place of the default templates. See
+RegisterLocale for how locales are matched.
+*/
+func WithLocale(locale string) Option {
+	return func(o *options) {
+		o.locale = locale
+	}
+}
+
+/*
+WithMaxValueLength truncates the Before and After values of
+a Diff to n characters when they would otherwise be longer.
+Truncated values end with an ellipsis and their original
+length, as in `"abc…" (5000 chars)`. A value of zero or
+less disables truncation, which is the default.
+*/
+func WithMaxValueLength(n int) Option {
+	return func(o *options) {
+		o.maxValueLength = n
+	}
+}
+
+/*
+WithRedactPlaceholder sets the text that replaces the values
+of struct fields tagged with `diff:"redact"`. The default is
+"[REDACTED]".
+*/
+func WithRedactPlaceholder(placeholder string) Option {
+	return func(o *options) {
+		o.placeholder = &placeholder
+	}
+}
+
+/*
+WithSnapshotKey sets the key of the HMAC by which Snapshot
+records the values of struct fields tagged with
+`diff:"redact"`. Snapshots must be taken with the same key
+for changes to those values to be detected. The key should
+be kept as secret as the values themselves.
+*/
+func WithSnapshotKey(key []byte) Option {
+	return func(o *options) {
+		o.snapshotKey = key
+	}
+}
+
+/*
+WithNormalizedWhitespace causes strings to be compared after
+trimming leading and trailing whitespace and collapsing each
+run of internal whitespace to a single space. Strings which
+differ only in whitespace are then considered unchanged.
+Strings that do differ are rendered as they are.
+*/
+func WithNormalizedWhitespace() Option {
+	return func(o *options) {
+		o.normalizeSpace = true
+	}
+}
+
+/*
+WithNaNEqual causes floating point NaN values to compare as
+equal to each other, where normally NaN is unequal to every
+value including itself. Fields that hold NaN in both before
+and after are then unchanged. NaN is rendered as "NaN".
+Complex numbers with a NaN part are treated as NaN.
+*/
+func WithNaNEqual() Option {
+	return func(o *options) {
+		o.nanEqual = true
+	}
+}
+
+/*
+WithTolerance causes floating point and complex numbers of
+the same type to compare as equal if they differ by no more
+than tol, so that rounding errors aren't reported as changes.
+The difference between complex numbers is the modulus of
+their difference, as given by cmplx.Abs. A tol of 0 or less
+requires numbers to be exactly equal, which is the default.
+*/
+func WithTolerance(tol float64) Option {
+	return func(o *options) {
+		o.tolerance = tol
+	}
+}
+
+/*
+WithNilAsEmpty treats nil slices, maps, and interfaces as
+equal to empty slices and maps. Nil and empty slices or maps
+of the same type never differ element by element, but when
+held in interfaces, as in data decoded from JSON where null
+becomes nil and [] an empty slice, they are otherwise
+reported as a change from <no value> to []. The option also
+applies to DiffSnapshots.
+*/
+func WithNilAsEmpty() Option {
+	return func(o *options) {
+		o.nilAsEmpty = true
+	}
+}
+
+/*
+WithByteSummaries compares byte slices as single values
+rather than element by element, so that a changed binary blob
+is reported as one difference instead of one for each byte.
+Their values are summarised by their size and the start of
+their SHA-256 hash, as in
+
+	.Avatar changed from <1.2 KiB, sha256 ab12cd34…> to <3.4 KiB, sha256 9f00e1d2…>
+
+It applies to any slice whose elements are bytes, including
+named types such as json.RawMessage.
+*/
+func WithByteSummaries() Option {
+	return func(o *options) {
+		o.byteSummaries = true
+	}
+}
+
+/*
+WithUnquotedStrings passes strings to templates as they are
+rather than quoted and escaped as Go string literals, so
+that a change is rendered as
+
+	.Name changed from Ann to Anne
+
+String map keys are still quoted in Diff.Name, which must
+remain unambiguous.
+*/
+func WithUnquotedStrings() Option {
+	return func(o *options) {
+		o.unquoted = true
+	}
+}
+
+/*
+WithQuotedValues renders every value passed to templates as
+a quoted Go string literal, as strings are by default, so
+that newlines and other control characters are escaped and
+each difference stays on one line:
+
+	.Lines changed from "[a\nb]" to "[a]"
+	.Timeout changed from "30" to "60"
+
+This includes numbers, values rendered by WithStringer,
+WithMarshalers, or WithValueFormatter, and values of named
+string types. It takes precedence over WithUnquotedStrings.
+Redacted values are rendered as the placeholder alone.
+*/
+func WithQuotedValues() Option {
+	return func(o *options) {
+		o.quoted = true
+	}
+}
+
+/*
+WithStringer causes values implementing fmt.Stringer to be
+rendered with their String method before being passed to
+templates. Unlike the templates' own printing this includes
+types whose String method has a pointer receiver, and
+Before and After hold the resulting string, which is not
+quoted.
+*/
+func WithStringer() Option {
+	return func(o *options) {
+		o.stringer = true
+	}
+}
+
+/*
+WithMarshalers causes values implementing json.Marshaler or
+encoding.TextMarshaler, including through a pointer receiver,
+to be compared as a whole and rendered with MarshalJSON, or
+failing that MarshalText, rather than being diffed field by
+field. The encoding is used as is, so JSON strings remain
+quoted and text is not. Values whose encoding fails are
+rendered as usual. It takes precedence over WithStringer.
+*/
+func WithMarshalers() Option {
+	return func(o *options) {
+		o.marshalers = true
+	}
+}
+
+/*
+WithPathFormat renders the differences at paths matched by
+pattern with format rather than the Format passed to ObjectsF.
+Empty strings in format are substituted with the templates
+that would otherwise be used, except for Set and Clear which
+fall back to format's own Change. Funcs in format are added
+to those of the other Format.
+
+The pattern is a path in the default notation in which `.*`
+matches any struct field and `[*]` matches any slice or array
+index or map key. It matches differences at that path and
+anywhere beneath it. For example
+
+	WithPathFormat(".Password", diff.Format{Change: "{{.Name}} changed"})
+	WithPathFormat(".Tags[*]", diff.Format{Add: "+{{.After}}", Delete: "-{{.Before}}"})
+
+hide the values of .Password and abbreviate changes to the
+elements of .Tags. If several patterns match a path the first
+given is used. Patterns are matched against paths regardless
+of WithPathStyle.
+
+WithPathFormat panics if pattern isn't a valid path.
+*/
+func WithPathFormat(pattern string, format Format) Option {
+
+	p, err := parsePathPattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("diff: WithPathFormat pattern %q: %v", pattern, err))
+	}
+
+	return func(o *options) {
+		o.pathFormats = append(o.pathFormats, pathFormat{p, format})
+	}
+}
+
+/*
+WithFields restricts diffing to the values at the given
+paths and anywhere beneath them, such as
+
+	WithFields(".Name", ".Spec.Replicas")
+
+Everything else is skipped without being traversed, which
+makes comparing a few fields of a large object cheap. Paths
+may contain wildcards as for WithPathFormat, so ".Tags[*]"
+selects every element of .Tags. Calling it more than once
+adds to the paths already given. It applies to every
+function taking options, including Equal and ChangedPaths,
+and to the functions generated by cmd/diffgen.
+
+WithFields panics if a path isn't valid.
+*/
+func WithFields(paths ...string) Option {
+
+	patterns := make([]pathPattern, len(paths))
+	for i, path := range paths {
+		p, err := parsePathPattern(path)
+		if err != nil {
+			panic(fmt.Sprintf("diff: WithFields path %q: %v", path, err))
+		}
+		patterns[i] = p
+	}
+
+	return func(o *options) {
+		o.fields = append(o.fields, patterns...)
+	}
+}
+
+/*
+WithValueFormatter renders the Before and After values of
+each difference with format rather than the default
+rendering. It is given the Name of the difference and the
+value to be rendered, and may switch on either. Its result
+is used as is and takes precedence over WithStringer and
+WithMarshalers.
+
+The values of redacted fields are never passed to format.
+*/
+func WithValueFormatter(format func(path string, v interface{}) string) Option {
+	return func(o *options) {
+		o.formatter = format
+	}
+}
+
+/*
+WithTransform causes values of the type accepted by fn to be
+replaced by the result of calling fn on them before they are
+compared, so that noisy types can be normalised without
+altering the objects being diffed. For example,
+
+	WithTransform(func(t time.Time) int64 { return t.Unix() })
+
+ignores differences smaller than a second. The result may be
+of any type, including a data structure which is then diffed
+as usual, and it is what appears in Before and After. Only
+values of exactly the parameter's type are transformed, and
+fn isn't applied again to anything within its own result.
+
+WithTransform panics if fn isn't a function with a single
+parameter and a single result.
+*/
+func WithTransform(fn interface{}) Option {
+
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 1 || f.Type().IsVariadic() {
+		panic(fmt.Sprintf("diff: WithTransform requires a func with one parameter and one result, got %T", fn))
+	}
+
+	return func(o *options) {
+		if o.transforms == nil {
+			o.transforms = map[reflect.Type]reflect.Value{}
+		}
+		o.transforms[f.Type().In(0)] = f
+	}
+}
+
+/*
+WithLeafTypes causes values of the types of the given values
+to be compared as a whole rather than field by field or
+element by element, and rendered as they would be by the
+templates, such as with a String method. For example,
+
+	WithLeafTypes(time.Time{}, decimal.Decimal{}, netip.Addr{})
+
+Types with an Equal method taking a value of the same type
+and returning a bool, as these do, are compared with it.
+Other types are considered equal if they're deeply equal.
+Only values of exactly the given types are affected.
+
+WithLeafTypes panics if any of values is nil.
+*/
+func WithLeafTypes(values ...interface{}) Option {
+
+	types := make([]reflect.Type, len(values))
+	for i, v := range values {
+		if v == nil {
+			panic("diff: WithLeafTypes requires non-nil values")
+		}
+		types[i] = reflect.TypeOf(v)
+	}
+
+	return func(o *options) {
+		if o.leafTypes == nil {
+			o.leafTypes = make(map[reflect.Type]bool)
+		}
+		for _, t := range types {
+			o.leafTypes[t] = true
+		}
+	}
+}
+
+/*
+WithFlags names the bits of an integer type used as a set of
+flags, such as a permission mask, given as a map from each
+flag to its name:
+
+	WithFlags(map[Perm]string{Read: "Read", Write: "Write", Admin: "Admin"})
+
+A change to a value of that type is then reported as the
+flags added and removed, at the path of the value and with
+the flag's name as the value, as with sets. Changing a Perm
+field from Admin to Read|Write is reported as
+
+	.Perms added Read
+	.Perms added Write
+	.Perms deleted Admin
+
+in order of the flags' values. Bits without a name are
+rendered in hexadecimal, such as 0x10.
+
+WithFlags panics if flags isn't a map from an integer type to
+string, or if any of its keys isn't a single bit.
+*/
+func WithFlags(flags interface{}) Option {
+
+	m := reflect.ValueOf(flags)
+	if m.Kind() != reflect.Map || !isInteger(m.Type().Key()) || m.Type().Elem().Kind() != reflect.String {
+		panic(fmt.Sprintf("diff: WithFlags requires a map from an integer type to string, got %T", flags))
+	}
+
+	names := make(map[uint64]string, m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		bit := bits(iter.Key())
+		if bit == 0 || bit&(bit-1) != 0 {
+			panic(fmt.Sprintf("diff: WithFlags requires single bit flags, got %v", iter.Key()))
+		}
+		names[bit] = iter.Value().String()
+	}
+
+	return func(o *options) {
+		if o.flags == nil {
+			o.flags = map[reflect.Type]map[uint64]string{}
+		}
+		o.flags[m.Type().Key()] = names
+	}
+}
+
+/*
+WithAggregateErrors stops a failure to render one difference
+from aborting the whole diff. Instead each error is recorded
+as a *PathError and the diff carries on. Objects and ObjectsF
+then return the differences that rendered successfully along
+with all of the errors joined by errors.Join.
+
+Errors that aren't specific to a difference, such as invalid
+arguments or a cancelled context, still abort the diff.
+*/
+func WithAggregateErrors() Option {
+	return func(o *options) {
+		o.aggregateErrors = true
+	}
+}
+
+/*
+WithMoves reports an element of a slice or array whose value
+now appears at another index as a single difference of kind
+Move, rather than as a series of changes or a deletion and an
+addition. Elements that keep their order relative to one
+another aren't considered moved, so inserting an element at
+the start of a slice reports only the addition.
+
+Elements are matched by exact equality. Those that are
+unmatched are reported as changed if an unmatched element
+occupies the same index on the other side, or as added or
+deleted otherwise.
+*/
+func WithMoves() Option {
+	return func(o *options) {
+		o.moves = true
+	}
+}
+
+/*
+WithHashPruning hashes each struct, map, slice, and array on
+both sides before descending into it and skips it entirely
+if the hashes match. This greatly reduces the work done for
+large objects with few differences, at the cost of hashing
+every node when most things differ. Each side is hashed once,
+bottom up, when the first of them is reached, and the hashes
+of those within it are looked up as the differ descends, so
+the cost grows with the size of the objects but not their
+depth.
+
+The hashes are 64-bit FNV-1a so a collision, which would hide
+a difference, is vanishingly unlikely but not impossible.
+Values that are identical bit for bit are treated as equal,
+so a NaN that hasn't changed isn't reported.
+*/
+func WithHashPruning() Option {
+	return func(o *options) {
+		o.hashPruning = true
+	}
+}
+
+/*
+WithProtoFieldNumbers names the fields of protobuf messages
+in paths by their field numbers rather than their Go names,
+so that a change to a message's Name field, numbered 2, is
+reported at .2 rather than .Name. Fields without a number,
+such as oneofs, keep their Go names.
+*/
+func WithProtoFieldNumbers() Option {
+	return func(o *options) {
+		o.protoNumbers = true
+	}
+}
+
+/*
+WithUnchanged also reports every leaf value that hasn't
+changed, as a difference of kind Same rendered with the Same
+template, so that the result describes the whole of after
+with its changes in place. It disables WithHashPruning, as
+nothing may be skipped, and has no effect on Equal and
+ChangedPaths.
+*/
+func WithUnchanged() Option {
+	return func(o *options) {
+		o.unchanged = true
+	}
+}
+
+/*
+WithJSONRoundTrip marshals before and after with
+encoding/json and diffs the results decoded into generic
+maps, slices, and values instead of the objects themselves.
+This compares them as a client of an API serving them as
+JSON would see them: fields are named by their json tags,
+fields tagged "-" or omitted as empty are absent, and types
+implementing json.Marshaler are compared by their output.
+An error is returned if either object can't be marshaled.
+
+Objects are reported as JSON objects are, so a struct field
+tagged `json:"name"` is reported as ["name"]. Numbers are
+kept as json.Number, rendering as they were marshaled.
+Fields tagged with `diff:"redact"` are replaced by an HMAC of
+their values before marshaling, so that they're still
+redacted and changes to them are still found. An error is
+returned if such a field can't hold a string.
+*/
+func WithJSONRoundTrip() Option {
+	return func(o *options) {
+		o.jsonRoundTrip = true
+	}
+}
+
+/*
+WithCharDiff sets Diff.Chars for changes between strings of
+up to maxLen characters, marking the characters removed from
+before with "[-" and "-]" and those added in after with "{+"
+and "+}". It makes a small difference in a long identifier,
+such as a hash, easy to spot:
+
+	.Commit changed from "3f2a9c" to "3f2b9c" (3f2[-a-]{+b+}9c)
+
+The default templates don't print Chars; use a Format with
+DefaultCharChange or a template of your own that does. It is
+not set for redacted values. A maxLen of 0 or less disables
+it, which is the default.
+*/
+func WithCharDiff(maxLen int) Option {
+	return func(o *options) {
+		o.charDiff = maxLen
+	}
+}
+
+/*
+WithStringContext renders a change between two strings as
+only the n characters either side of the first character in
+which they differ, with "…" in place of the rest, so that a
+small change to a long string is easy to read:
+
+	.Body changed from "…abcXdef…" to "…abcYdef…"
+
+Strings short enough to need no cutting are rendered in
+full. Only Before and After are affected; Chars, Distance,
+BeforeValue, and AfterValue still reflect the whole strings.
+A value of n of 0 or less disables it, which is the default.
+*/
+func WithStringContext(n int) Option {
+	return func(o *options) {
+		o.stringContext = n
+	}
+}
+
+/*
+WithEditDistance sets Diff.Distance for changes between
+strings to the Levenshtein distance between them: the number
+of characters that must be inserted, deleted, or substituted
+to turn one into the other. Templates may print it as
+{{.Distance}}. It is not set for redacted values.
+
+Strings whose distance is less than min are considered
+unchanged, so that near-identical strings such as those
+differing only by a typo fix can be ignored. A min of 1 or
+less ignores no changes.
+*/
+func WithEditDistance(min int) Option {
+	return func(o *options) {
+		o.editDistance = true
+		o.minDistance = min
+	}
+}
+
+/*
+WithTimeLocation causes time.Time values to be compared as
+single values after converting them to loc, such as time.UTC,
+so that times representing the same instant are unchanged
+whatever their locations. Changed times are rendered in loc.
+Without it a time is diffed as any other struct would be.
+
+WithTimeLocation panics if loc is nil.
+*/
+func WithTimeLocation(loc *time.Location) Option {
+	if loc == nil {
+		panic("diff: WithTimeLocation requires a non-nil location")
+	}
+	return func(o *options) {
+		o.timeLocation = loc
+	}
+}
+
+/*
+WithOpaque sets how values of kind chan, func, and
+unsafe.Pointer are compared, such as the callbacks and
+channels held by many structs. By default a func is only
+equal to another if both are nil, so structs holding non-nil
+callbacks always differ. WithOpaque(OpaqueNil) reports them
+only when one is nil and the other isn't, and
+WithOpaque(OpaqueSkip) never reports them.
+*/
+func WithOpaque(mode OpaqueMode) Option {
+	return func(o *options) {
+		o.opaque = mode
+	}
+}
+
+/*
+WithHTMLEscaping renders the templates of the Format with
+html/template instead of text/template, so that the values
+they print are escaped according to where they appear in
+the surrounding markup. Use it when the output is destined
+for a web page, as values such as strings from users may
+otherwise inject markup into it. The templates are parsed
+as HTML and so must themselves be safe to use as such.
+*/
+func WithHTMLEscaping() Option {
+	return func(o *options) {
+		o.html = true
+	}
+}
+
+/*
+WithColor colors the text rendered for each difference with
+ANSI escape sequences for display in a terminal, in the
+Styles given by theme, such as DefaultTheme. Templates that
+compare Name, Before, or After to other values won't match
+once they're colored, and the values are passed to them as
+strings. Diff values returned by functions such as Diffs
+aren't affected.
+*/
+func WithColor(theme Theme) Option {
+	return func(o *options) {
+		o.theme = &theme
+	}
+}
+
+/*
+WithSpanEvents records each difference found as an event on
+span, named "diff.difference" with the attributes diff.kind,
+diff.path, diff.before, and diff.after, rendered as they
+would be for a template. At most max events are recorded per
+diff, after which a single "diff.truncated" event is added
+and the rest are dropped. If max is zero or less there's no
+limit.
+
+Span is satisfied by a small adapter around the span of a
+tracing library, such as OpenTelemetry's:
+
+	type otelSpan struct{ trace.Span }
+
+	func (s otelSpan) AddEvent(name string, attrs []diff.Attribute) {
+		kvs := make([]attribute.KeyValue, len(attrs))
+		for i, a := range attrs {
+			kvs[i] = attribute.String(a.Key, a.Value)
+		}
+		s.Span.AddEvent(name, trace.WithAttributes(kvs...))
+	}
+
+	span := otelSpan{trace.SpanFromContext(ctx)}
+	changes, err := diff.Objects(before, after, diff.WithSpanEvents(span, 50))
+
+Nothing is recorded by Equal and ChangedPaths, which don't
+render values.
+*/
+func WithSpanEvents(span Span, max int) Option {
+	return func(o *options) {
+		o.span = span
+		o.maxEvents = max
+	}
+}
+
+// withContext is used by ObjectsCtx.
+func withContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+func (o *options) redactPlaceholder() string {
+	if o.placeholder == nil {
+		return "[REDACTED]"
+	}
+	return *o.placeholder
+}
+
+func (o *options) wantKind(k Kind) bool {
+	if len(o.kinds) == 0 {
+		return true
+	}
+	for _, kind := range o.kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
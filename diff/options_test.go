@@ -0,0 +1,839 @@
+package diff
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithKinds(t *testing.T) {
+
+	before := []string{"a", "b"}
+	after := []string{"c"}
+
+	cases := []struct {
+		kinds []Kind
+		want  []string
+	}{
+		{
+			nil,
+			[]string{
+				`[0] changed from "a" to "c"`,
+				`[1] deleted "b"`,
+			},
+		},
+		{
+			[]Kind{Delete},
+			[]string{`[1] deleted "b"`},
+		},
+		{
+			[]Kind{Add},
+			nil,
+		},
+		{
+			[]Kind{Add, Change},
+			[]string{`[0] changed from "a" to "c"`},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, WithKinds(c.kinds...))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithKinds(%v))\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, nil",
+				before, after, c.kinds, got, err, c.want)
+		}
+	}
+}
+
+func TestWithMaxValueLength(t *testing.T) {
+
+	type blob struct {
+		Data string
+		Size int
+	}
+
+	before := blob{"short", 12345}
+	after := blob{"héllo world", 123456}
+
+	cases := []struct {
+		max  int
+		want []string
+	}{
+		{
+			0,
+			[]string{
+				`.Data changed from "short" to "héllo world"`,
+				`.Size changed from 12345 to 123456`,
+			},
+		},
+		{
+			5,
+			[]string{
+				`.Data changed from "short" to "héllo"… (11 chars)`,
+				`.Size changed from 12345 to 12345… (6 chars)`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, WithMaxValueLength(c.max))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithMaxValueLength(%d))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, c.max, got, err, c.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+
+	type secrets struct {
+		APIKey string
+	}
+	type account struct {
+		User     string
+		Password string  `diff:"redact"`
+		Secrets  secrets `diff:"redact"`
+	}
+
+	before := account{"bob", "hunter2", secrets{"abc"}}
+	after := account{"bobby", "hunter3", secrets{"def"}}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.User changed from "bob" to "bobby"`,
+				`.Password changed from [REDACTED] to [REDACTED]`,
+				`.Secrets.APIKey changed from [REDACTED] to [REDACTED]`,
+			},
+		},
+		{
+			[]Option{WithRedactPlaceholder("***")},
+			[]string{
+				`.User changed from "bob" to "bobby"`,
+				`.Password changed from *** to ***`,
+				`.Secrets.APIKey changed from *** to ***`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithNormalizedWhitespace(t *testing.T) {
+
+	before := []interface{}{" a  b\t", "c", 1}
+	after := []interface{}{"a b", "c d", 1}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`[0] changed from " a  b\t" to "a b"`,
+				`[1] changed from "c" to "c d"`,
+			},
+		},
+		{
+			[]Option{WithNormalizedWhitespace()},
+			[]string{
+				`[1] changed from "c" to "c d"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%q, %q)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithNaNEqual(t *testing.T) {
+
+	type reading struct {
+		Temp  float64
+		Hum   float32
+		Extra []interface{}
+	}
+
+	nan := math.NaN()
+	before := reading{nan, float32(nan), []interface{}{nan, 1.0}}
+	after := reading{nan, 0.5, []interface{}{nan, nan}}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Temp changed from NaN to NaN`,
+				`.Hum changed from NaN to 0.5`,
+				`.Extra[0] changed from NaN to NaN`,
+				`.Extra[1] changed from 1 to NaN`,
+			},
+		},
+		{
+			[]Option{WithNaNEqual()},
+			[]string{
+				`.Hum changed from NaN to 0.5`,
+				`.Extra[1] changed from 1 to NaN`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+type BaseConfig struct {
+	Timeout int
+	Retry
+}
+
+type Retry struct {
+	Attempts int
+}
+
+type Shadow struct {
+	Name  string
+	Debug bool
+}
+
+func TestWithPromotedFields(t *testing.T) {
+
+	type service struct {
+		BaseConfig
+		Shadow
+		Name string
+	}
+
+	before := service{
+		BaseConfig: BaseConfig{30, Retry{1}},
+		Shadow:     Shadow{"a", false},
+		Name:       "svc",
+	}
+	after := service{
+		BaseConfig: BaseConfig{60, Retry{3}},
+		Shadow:     Shadow{"b", true},
+		Name:       "api",
+	}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.BaseConfig.Timeout changed from 30 to 60`,
+				`.BaseConfig.Retry.Attempts changed from 1 to 3`,
+				`.Shadow.Name changed from "a" to "b"`,
+				`.Shadow.Debug changed from false to true`,
+				`.Name changed from "svc" to "api"`,
+			},
+		},
+		{
+			[]Option{WithPromotedFields()},
+			[]string{
+				`.Timeout changed from 30 to 60`,
+				`.Attempts changed from 1 to 3`,
+				`.Shadow.Name changed from "a" to "b"`,
+				`.Shadow.Debug changed from false to true`,
+				`.Name changed from "svc" to "api"`,
+			},
+		},
+		{
+			[]Option{
+				WithPromotedFields(),
+				WithPathStyle(PathJSONPointer),
+				WithKinds(Change),
+				WithPathFormat(".Attempts", Format{Change: "{{.Name}} retried"}),
+			},
+			[]string{
+				`/Timeout changed from 30 to 60`,
+				`/Attempts retried`,
+				`/Shadow/Name changed from "a" to "b"`,
+				`/Shadow/Debug changed from false to true`,
+				`/Name changed from "svc" to "api"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+
+	diffs, _ := Diffs(before, after, WithPromotedFields())
+	if len(diffs) == 0 || len(diffs[0].Path) != 2 {
+		t.Errorf("Diffs(%v, %v, WithPromotedFields())\n"+
+			"    Path of first difference was %v, wanted 2 segments",
+			before, after, diffs)
+	}
+}
+
+func TestWithSortedFields(t *testing.T) {
+
+	type endpoint struct {
+		Port int
+		Host string
+	}
+	type service struct {
+		Name     string
+		Endpoint endpoint
+		Debug    bool
+	}
+
+	before := service{"svc", endpoint{80, "a"}, false}
+	after := service{"api", endpoint{8080, "b"}, true}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Name changed from "svc" to "api"`,
+				`.Endpoint.Port changed from 80 to 8080`,
+				`.Endpoint.Host changed from "a" to "b"`,
+				`.Debug changed from false to true`,
+			},
+		},
+		{
+			[]Option{WithSortedFields()},
+			[]string{
+				`.Debug changed from false to true`,
+				`.Endpoint.Host changed from "a" to "b"`,
+				`.Endpoint.Port changed from 80 to 8080`,
+				`.Name changed from "svc" to "api"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+type status int
+
+// Pointer receivers aren't called by fmt for values.
+func (s *status) String() string {
+	return [...]string{"Pending", "Active", "Closed"}[*s]
+}
+
+type ticket struct {
+	Status status
+	Code   code
+}
+
+// Strings are normally quoted.
+type code string
+
+func (c code) String() string {
+	return "#" + string(c)
+}
+
+func TestWithStringer(t *testing.T) {
+
+	before := ticket{0, "a"}
+	after := ticket{2, "b"}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Status changed from 0 to 2`,
+				`.Code changed from #a to #b`,
+			},
+		},
+		{
+			[]Option{WithStringer()},
+			[]string{
+				`.Status changed from Pending to Closed`,
+				`.Code changed from #a to #b`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+
+	diffs, err := Diffs(before, after, WithStringer())
+	if err != nil || len(diffs) != 2 || diffs[0].After != "Closed" {
+		t.Errorf(
+			"Diffs(%v, %v, WithStringer())\n"+
+				"    return %v, %v\n"+
+				"    wanted After of first Diff to be %q",
+			before, after, diffs, err, "Closed")
+	}
+}
+
+func TestWithUnquotedStrings(t *testing.T) {
+
+	type message struct {
+		Text  string
+		Tags  map[string]string
+		Count int
+	}
+
+	before := message{"hi \"there\"", map[string]string{"a b": "x"}, 1}
+	after := message{"bye", map[string]string{"a b": "y\tz"}, 2}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Text changed from "hi \"there\"" to "bye"`,
+				`.Tags["a b"] changed from "x" to "y\tz"`,
+				`.Count changed from 1 to 2`,
+			},
+		},
+		{
+			[]Option{WithUnquotedStrings()},
+			[]string{
+				`.Text changed from hi "there" to bye`,
+				".Tags[\"a b\"] changed from x to y\tz",
+				`.Count changed from 1 to 2`,
+			},
+		},
+		{
+			[]Option{WithUnquotedStrings(), WithMaxValueLength(2)},
+			[]string{
+				`.Text changed from hi… (10 chars) to by… (3 chars)`,
+				".Tags[\"a b\"] changed from x to y\t… (3 chars)",
+				`.Count changed from 1 to 2`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithQuotedValues(t *testing.T) {
+
+	type entry struct {
+		Lines   []string
+		Code    code
+		Status  status
+		Timeout int
+		Note    string
+	}
+
+	before := entry{[]string{"a\nb"}, "x", 0, 30, "a"}
+	after := entry{[]string{"a"}, "y\n", 2, 60, "b\n"}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			[]Option{WithQuotedValues()},
+			[]string{
+				`.Lines[0] changed from "a\nb" to "a"`,
+				`.Code changed from "#x" to "#y\n"`,
+				`.Status changed from "0" to "2"`,
+				`.Timeout changed from "30" to "60"`,
+				`.Note changed from "a" to "b\n"`,
+			},
+		},
+		{
+			[]Option{WithQuotedValues(), WithUnquotedStrings(), WithStringer()},
+			[]string{
+				`.Lines[0] changed from "a\nb" to "a"`,
+				`.Code changed from "#x" to "#y\n"`,
+				`.Status changed from "Pending" to "Closed"`,
+				`.Timeout changed from "30" to "60"`,
+				`.Note changed from "a" to "b\n"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestWithFields(t *testing.T) {
+
+	type container struct {
+		Name  string
+		Image string
+	}
+	type spec struct {
+		Replicas   int
+		Containers []container
+		Labels     map[string]string
+	}
+	type deployment struct {
+		Name string
+		Note string
+		Spec spec
+	}
+
+	before := deployment{"api", "a", spec{1, []container{{"app", "app:1"}}, map[string]string{"x": "1"}}}
+	after := deployment{"web", "b", spec{2, []container{{"app2", "app:2"}}, map[string]string{"x": "2"}}}
+
+	cases := []struct {
+		fields []string
+		want   []string
+	}{
+		{
+			[]string{".Name", ".Spec.Replicas"},
+			[]string{
+				`.Name changed from "api" to "web"`,
+				`.Spec.Replicas changed from 1 to 2`,
+			},
+		},
+		{
+			[]string{".Spec.Containers[*].Image"},
+			[]string{
+				`.Spec.Containers[0].Image changed from "app:1" to "app:2"`,
+			},
+		},
+		{
+			[]string{`.Spec.Labels["x"]`, ".Missing"},
+			[]string{
+				`.Spec.Labels["x"] changed from "1" to "2"`,
+			},
+		},
+		{
+			nil,
+			[]string{
+				`.Name changed from "api" to "web"`,
+				`.Note changed from "a" to "b"`,
+				`.Spec.Replicas changed from 1 to 2`,
+				`.Spec.Containers[0].Name changed from "app" to "app2"`,
+				`.Spec.Containers[0].Image changed from "app:1" to "app:2"`,
+				`.Spec.Labels["x"] changed from "1" to "2"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, WithFields(c.fields...))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithFields(%q...))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, c.fields, got, err, c.want)
+		}
+	}
+
+	// Values outside the selected paths aren't visited.
+	visited := 0
+	count := WithTransform(func(c container) container {
+		visited++
+		return c
+	})
+	if _, err := Objects(before, after, count, WithFields(".Name")); err != nil || visited != 0 {
+		t.Errorf(
+			"Objects(%v, %v, WithFields(\".Name\"))\n"+
+				"    visited %d containers, returned %v\n"+
+				"    wanted 0, nil",
+			before, after, visited, err)
+	}
+}
+
+func TestWithValueFormatter(t *testing.T) {
+
+	type job struct {
+		Name    string
+		Timeout time.Duration
+		Secret  string `diff:"redact"`
+	}
+
+	before := job{"a", 90 * time.Second, "x"}
+	after := job{"b", 2 * time.Minute, "y"}
+
+	format := func(path string, v interface{}) string {
+		switch v := v.(type) {
+		case time.Duration:
+			return v.String()
+		case string:
+			if path == ".Secret" {
+				return v
+			}
+			return "<" + v + ">"
+		}
+		return fmt.Sprint(v)
+	}
+
+	want := []string{
+		`.Name changed from <a> to <b>`,
+		`.Timeout changed from 1m30s to 2m0s`,
+		`.Secret changed from [REDACTED] to [REDACTED]`,
+	}
+
+	got, err := Objects(before, after, WithValueFormatter(format))
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"Objects(%v, %v, WithValueFormatter(format))\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			before, after, got, err, want)
+	}
+}
+
+func TestWithTransform(t *testing.T) {
+
+	type event struct {
+		At   time.Time
+		Tags string
+	}
+
+	unix := WithTransform(func(t time.Time) int64 { return t.Unix() })
+	split := WithTransform(func(s string) []string { return strings.Split(s, ",") })
+
+	base := time.Unix(100, 0)
+
+	cases := []struct {
+		before event
+		after  event
+		opts   []Option
+		want   []string
+	}{
+		{
+			event{base, "a"},
+			event{base.Add(time.Millisecond), "a"},
+			[]Option{unix},
+			nil,
+		},
+		{
+			event{base, "a"},
+			event{base.Add(time.Minute), "a"},
+			[]Option{unix},
+			[]string{`.At changed from 100 to 160`},
+		},
+		{
+			event{base, "a,b"},
+			event{base, "a,c,d"},
+			[]Option{unix, split},
+			[]string{
+				`.Tags[1] changed from "b" to "c"`,
+				`.Tags[2] added "d"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+
+	for _, fn := range []interface{}{nil, 5, func() int { return 0 }, func(...int) int { return 0 }} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithTransform(%T) did not panic", fn)
+				}
+			}()
+			WithTransform(fn)
+		}()
+	}
+}
+
+func TestWithUnchanged(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []string
+	}{
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.1", 30},
+			[]Option{WithUnchanged()},
+			[]string{
+				`.Debug unchanged true`,
+				`.Version changed from "0.0.0" to "0.0.1"`,
+				`.Timeout unchanged 30`,
+			},
+		},
+		{
+			[]string{"a", "b"},
+			[]string{"a", "c"},
+			[]Option{WithUnchanged(), WithKinds(Same)},
+			[]string{`[0] unchanged "a"`},
+		},
+		{
+			[]string{"a", "b", "c"},
+			[]string{"c", "a", "b"},
+			[]Option{WithUnchanged(), WithMoves()},
+			[]string{
+				`[0] unchanged "a"`,
+				`[1] unchanged "b"`,
+				`[2] moved to [0]`,
+			},
+		},
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.0", 30},
+			[]Option{WithUnchanged(), WithHashPruning(), WithLocale("de")},
+			[]string{
+				`.Debug unverändert: true`,
+				`.Version unverändert: "0.0.0"`,
+				`.Timeout unverändert: 30`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+
+	before := config{true, "0.0.0", 30}
+	if ok, err := Equal(before, before, WithUnchanged()); !ok || err != nil {
+		t.Errorf("Equal(%v, %v, WithUnchanged()) returned %v, %v", before, before, ok, err)
+	}
+}
+
+func TestWithNilAsEmpty(t *testing.T) {
+
+	type doc struct {
+		Tags  interface{}
+		Attrs interface{}
+		List  []int
+	}
+
+	before := doc{nil, nil, nil}
+	after := doc{[]interface{}{}, map[string]interface{}{}, []int{}}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Tags changed from <no value> to []`,
+				`.Attrs changed from <no value> to map[]`,
+			},
+		},
+		{
+			[]Option{WithNilAsEmpty()},
+			nil,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/*
+sortValues sorts vs, which are map keys, into the order in
+which map entries are diffed. See compareValues.
+*/
+func sortValues(vs []reflect.Value) {
+	sort.SliceStable(vs, func(i, j int) bool {
+		return compareValues(vs[i], vs[j]) < 0
+	})
+}
+
+/*
+sortedFields returns a copy of fields sorted by name, for
+WithSortedFields. Fields are cached per type so they mustn't
+be sorted in place.
+*/
+func sortedFields(fields []fieldInfo) []fieldInfo {
+	sorted := append([]fieldInfo(nil), fields...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted
+}
+
+/*
+compareValues returns -1, 0, or +1 depending on whether a
+sorts before, the same as, or after b. It gives a total order
+over comparable values, which are the only ones that may be
+map keys, so that maps are always diffed in the same order:
+
+  - numbers are ordered numerically, with NaN first,
+  - strings are ordered byte-wise,
+  - false comes before true,
+  - structs and arrays are ordered by their fields or elements
+    in turn,
+  - interfaces are ordered by the values they hold, with nil
+    first, and values of differing types by their type names.
+
+Pointers and channels are ordered by address, so maps keyed
+by them are ordered consistently within a process but not
+across runs.
+*/
+func compareValues(a, b reflect.Value) int {
+
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0
+	case !a.IsValid():
+		return -1
+	case !b.IsValid():
+		return 1
+	case a.Type() != b.Type():
+		return strings.Compare(a.Type().String(), b.Type().String())
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := compareOrdered(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
+		}
+		return compareOrdered(imag(a.Complex()), imag(b.Complex()))
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		}
+		return 1
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan:
+		return compareOrdered(a.Pointer(), b.Pointer())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareValues(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareValues(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return 0
+}
+
+/*
+compareOrdered returns -1, 0 or +1 as a is less than, equal to
+or greater than b. NaN is ordered before any other float and
+equal to itself. It mirrors cmp.Compare, which needs Go 1.21.
+*/
+func compareOrdered[T int64 | uint64 | uintptr | float64](a, b T) int {
+
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN || a < b:
+		return -1
+	case bNaN || a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCompareValues(t *testing.T) {
+
+	type pair struct {
+		A string
+		B int
+	}
+
+	cases := []struct {
+		a    interface{}
+		b    interface{}
+		want int
+	}{
+		{1, 2, -1},
+		{uint8(3), uint8(3), 0},
+		{math.NaN(), -math.MaxFloat64, -1},
+		{complex(1, 2), complex(1, 1), 1},
+		{"b", "a", 1},
+		{false, true, -1},
+		{pair{"a", 2}, pair{"a", 1}, 1},
+		{[2]int{1, 2}, [2]int{1, 3}, -1},
+		{"1", 1, 1}, // "string" sorts after "int".
+	}
+
+	for i, c := range cases {
+		got := compareValues(reflect.ValueOf(c.a), reflect.ValueOf(c.b))
+		if got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"compareValues(%v, %v)\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestMapOrder(t *testing.T) {
+
+	before := map[interface{}]int{
+		"b": 1, "a": 1, 3: 1, 10: 1, nil: 1, 2.5: 1,
+	}
+	after := map[interface{}]int{}
+
+	want := []string{
+		`[<nil>] deleted 1`,
+		`[2.5] deleted 1`,
+		`[3] deleted 1`,
+		`[10] deleted 1`,
+		`["a"] deleted 1`,
+		`["b"] deleted 1`,
+	}
+
+	// Map iteration order is random so one run proves little.
+	for i := 0; i < 20; i++ {
+		got, err := Objects(before, after)
+		if !equal(got, want) || err != nil {
+			t.Fatalf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package diff
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+SegmentKind identifies what a Segment of a Path refers to.
+*/
+type SegmentKind int
+
+const (
+	FieldSegment SegmentKind = iota + 1 // A struct field.
+	IndexSegment                        // A slice or array index.
+	KeySegment                          // A map key.
+)
+
+/*
+Segment is a single step in a Path. Only the member that
+corresponds to its Kind is meaningful: Name for a struct
+field, Index for a slice or array element, and Key for a
+map entry. Key holds the map key's original value.
+
+Keys implementing encoding.TextMarshaler, including through
+a pointer receiver, are rendered with MarshalText as strings
+would be, as they are as JSON object keys. Keys whose kind
+is string are rendered as they are.
+
+In the default notation a key that is a bool or a number,
+such as `[true]` or `[-1.5]`, is written bare, as is a nil
+interface key as `[<nil>]`. Every other
+key, including one of a named string type and one whose
+String method is used in place of its number, is written as
+a double-quoted Go string literal, as in `["a]b"]`, so that
+any brackets, dots, or quotes in it are escaped and a path
+can be read back with ParsePath.
+*/
+type Segment struct {
+	Kind  SegmentKind
+	Name  string
+	Index int
+	Key   interface{}
+
+	// promoted is set for an embedded struct field whose
+	// fields are promoted when using WithPromotedFields.
+	promoted bool
+}
+
+/*
+String renders the segment as it appears in Diff.Name,
+such as `.Timeout`, `[3]`, or `["key"]`.
+*/
+func (s Segment) String() string {
+	switch s.Kind {
+	case FieldSegment:
+		return "." + s.Name
+	case IndexSegment:
+		return "[" + strconv.Itoa(s.Index) + "]"
+	case KeySegment:
+		k := keyText(s.Key)
+		if v := reflect.ValueOf(k); v.Kind() == reflect.String {
+			return "[" + strconv.Quote(v.String()) + "]"
+		}
+		text := fmt.Sprint(k)
+		if _, ok := parseBareKey(text); ok && (k == nil || isBare(k)) {
+			return "[" + text + "]"
+		}
+		return "[" + strconv.Quote(text) + "]"
+	}
+	return ""
+}
+
+/*
+Path is the location of a difference within the objects
+being diffed, starting from the outermost object. Unlike
+Diff.Name it can be inspected without parsing, so a map
+key containing characters such as `.` or `[` is
+unambiguous.
+*/
+type Path []Segment
+
+/*
+String joins the segments of p. It is the same as the
+Name of the Diff the path belongs to.
+*/
+func (p Path) String() string {
+	var sb strings.Builder
+	sb.Grow(8 * len(p))
+	for _, s := range p.visible() {
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
+
+/*
+ParsePath reads s, a path in the default notation such as
+`.Mapping["a.b"][3]`, back into a Path. It is the inverse of
+Path.String: for any path p,
+
+	ParsePath(p.String())
+
+returns a path rendering the same text as p.
+
+Since the notation doesn't say what type a map key was, a
+quoted key is returned as a string and a bare one as an int,
+uint64, float64, complex128, bool, or nil. A bare non-negative
+integer such as `[3]` is returned as an IndexSegment, even
+if it was a map key. An error is returned if s isn't a valid
+path.
+*/
+func ParsePath(s string) (Path, error) {
+
+	pp, err := parsePathPattern(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Path
+	for _, seg := range pp.segments {
+
+		if seg[0] == '.' {
+			p = append(p, fieldSegment(seg[1:]))
+			continue
+		}
+
+		text := seg[1 : len(seg)-1]
+		if strings.HasPrefix(text, `"`) {
+			k, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %s: %w", seg, err)
+			}
+			p = append(p, keySegment(k))
+			continue
+		}
+
+		k, ok := parseBareKey(text)
+		if !ok {
+			return nil, fmt.Errorf("invalid key %s: must be quoted", seg)
+		}
+		if i, ok := k.(int); ok && i >= 0 && !strings.HasPrefix(text, "+") {
+			p = append(p, indexSegment(i))
+			continue
+		}
+		p = append(p, keySegment(k))
+	}
+
+	return p, nil
+}
+
+/*
+Parent returns the path of the struct, map, slice, or array
+containing the location p refers to. The parent of an empty
+path is empty.
+*/
+func (p Path) Parent() Path {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[:len(p)-1]
+}
+
+/*
+Last returns the final segment of p, which names the struct
+field, element, or map entry p refers to within its parent.
+It returns the zero Segment if p is empty.
+*/
+func (p Path) Last() Segment {
+	if len(p) == 0 {
+		return Segment{}
+	}
+	return p[len(p)-1]
+}
+
+/*
+JSONPointer renders p in RFC 6901 JSON Pointer notation. It
+is the same as p.Format(PathJSONPointer).
+*/
+func (p Path) JSONPointer() string {
+	return p.jsonPointer()
+}
+
+/*
+Match reports whether p is matched by pattern, a path in the
+default notation in which `.*` matches any struct field and
+`[*]` matches any slice or array index or map key, as for
+WithPathFormat. Unlike WithPathFormat the whole of p must be
+matched, not just its start. The leading dot of a pattern
+beginning with a field name may be left out, so
+
+	path.Match("Spec.Containers[*].Image")
+
+matches the image of every container. An error is returned
+if pattern isn't a valid path.
+*/
+func (p Path) Match(pattern string) (bool, error) {
+
+	if pattern != "" && pattern[0] != '.' && pattern[0] != '[' {
+		pattern = "." + pattern
+	}
+
+	pp, err := parsePathPattern(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return pp.matchAll(p), nil
+}
+
+/*
+PathStyle selects a notation for rendering a Path.
+*/
+type PathStyle int
+
+const (
+	// PathDefault is this package's own notation, as in
+	// `.Mapping["key"][3]`.
+	PathDefault PathStyle = iota
+
+	// PathJSONPointer is RFC 6901 JSON Pointer notation,
+	// as in `/Mapping/key/3`.
+	PathJSONPointer
+
+	// PathJSONPath is JSONPath notation, as in
+	// `$.Mapping['key'][3]`.
+	PathJSONPath
+
+	// PathJQ is the notation used by jq, as in
+	// `.Mapping["key"][3]`.
+	PathJQ
+)
+
+/*
+Format renders p in the notation given by style. Map keys
+that are not strings are rendered as they would be by
+fmt.Sprint, since each of the JSON based notations requires
+that object keys are strings.
+*/
+func (p Path) Format(style PathStyle) string {
+
+	switch style {
+	case PathJSONPointer:
+		return p.jsonPointer()
+	case PathJSONPath:
+		return p.jsonPath()
+	case PathJQ:
+		return p.jq()
+	}
+
+	return p.String()
+}
+
+/*
+visible returns p without the segments of embedded structs
+whose fields are promoted, as set by WithPromotedFields, so
+that a promoted field is named as it's accessed in Go. Such
+a segment is kept if it's the last in p or isn't followed by
+a field, as when the embedded struct is compared as a whole.
+*/
+func (p Path) visible() Path {
+
+	hidden := func(i int) bool {
+		return p[i].promoted && i+1 < len(p) && p[i+1].Kind == FieldSegment
+	}
+
+	n := 0
+	for i := range p {
+		if hidden(i) {
+			n++
+		}
+	}
+	if n == 0 {
+		return p
+	}
+
+	v := make(Path, 0, len(p)-n)
+	for i, s := range p {
+		if !hidden(i) {
+			v = append(v, s)
+		}
+	}
+
+	return v
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func (p Path) jsonPointer() string {
+	var sb strings.Builder
+	for _, s := range p.visible() {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(s.text()))
+	}
+	return sb.String()
+}
+
+var jsonPathEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+func (p Path) jsonPath() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, s := range p.visible() {
+		switch {
+		case s.Kind == IndexSegment:
+			fmt.Fprintf(&sb, "[%d]", s.Index)
+		case s.Kind == FieldSegment && isIdent(s.Name):
+			sb.WriteString("." + s.Name)
+		default:
+			sb.WriteString("['" + jsonPathEscaper.Replace(s.text()) + "']")
+		}
+	}
+	return sb.String()
+}
+
+func (p Path) jq() string {
+	p = p.visible()
+	if len(p) == 0 {
+		return "."
+	}
+	var sb strings.Builder
+	for _, s := range p {
+		switch {
+		case s.Kind == IndexSegment:
+			fmt.Fprintf(&sb, "[%d]", s.Index)
+		case s.Kind == FieldSegment && isIdent(s.Name):
+			sb.WriteString("." + s.Name)
+		default:
+			sb.WriteString("[" + jsonQuote(s.text()) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// text returns the segment's name, index, or key
+// without any decoration.
+func (s Segment) text() string {
+	switch s.Kind {
+	case FieldSegment:
+		return s.Name
+	case IndexSegment:
+		return fmt.Sprint(s.Index)
+	case KeySegment:
+		return fmt.Sprint(keyText(s.Key))
+	}
+	return ""
+}
+
+/*
+keyText returns the text of k if it is a map key implementing
+encoding.TextMarshaler and not a string, or k itself.
+*/
+func keyText(k interface{}) interface{} {
+
+	if v := reflect.ValueOf(k); v.Kind() == reflect.String {
+		return k
+	}
+
+	if m, ok := implementer(k, textMarshalerType); ok {
+		if b, err := m.(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+
+	return k
+}
+
+// isBare reports whether k is of a kind whose keys may be
+// written without quotes, which is true of bools and numbers.
+func isBare(k interface{}) bool {
+	switch reflect.ValueOf(k).Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+/*
+parseBareKey returns the nil, bool, or number written as
+text, and false if text is none of these. Integers too large
+for an int are returned as a uint64 if they fit one.
+*/
+func parseBareKey(text string) (interface{}, bool) {
+
+	if text == "<nil>" {
+		return nil, true
+	}
+
+	if b, err := strconv.ParseBool(text); err == nil && (text == "true" || text == "false") {
+		return b, true
+	}
+	if i, err := strconv.Atoi(text); err == nil {
+		return i, true
+	}
+	if u, err := strconv.ParseUint(text, 10, 64); err == nil {
+		return u, true
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, true
+	}
+	if c, err := strconv.ParseComplex(text, 128); err == nil && strings.HasPrefix(text, "(") {
+		return c, true
+	}
+
+	return nil, false
+}
+
+func jsonQuote(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return string(b)
+}
+
+// isIdent reports whether s may follow a dot in
+// JSONPath and jq, which is the case for ASCII
+// identifiers.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func fieldSegment(name string) Segment {
+	return Segment{Kind: FieldSegment, Name: name}
+}
+
+func indexSegment(i int) Segment {
+	return Segment{Kind: IndexSegment, Index: i}
+}
+
+func keySegment(k interface{}) Segment {
+	return Segment{Kind: KeySegment, Key: k}
+}
@@ -0,0 +1,296 @@
+package diff
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type uuid [4]byte
+
+func (u uuid) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+type gridPoint struct {
+	X, Y int
+}
+
+func (p *gridPoint) MarshalText() ([]byte, error) {
+	if p.X < 0 {
+		return nil, errors.New("negative")
+	}
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+type upperKey string
+
+func (k upperKey) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(k))), nil
+}
+
+// level is rendered through its String method rather than as
+// a number.
+type level int
+
+func (l level) String() string {
+	return [...]string{"debug", "info", "warn"}[l]
+}
+
+func TestPathString(t *testing.T) {
+
+	cases := []struct {
+		path Path
+		want string
+	}{
+		{
+			nil,
+			"",
+		},
+		{
+			Path{fieldSegment("Mapping"), keySegment("a.b"), indexSegment(3)},
+			`.Mapping["a.b"][3]`,
+		},
+		{
+			Path{keySegment(7), fieldSegment("Debug")},
+			`[7].Debug`,
+		},
+		{
+			Path{keySegment(uuid{0xde, 0xad, 0xbe, 0xef}), keySegment(gridPoint{1, 2})},
+			`["deadbeef"]["1,2"]`,
+		},
+		{
+			Path{keySegment(gridPoint{-1, 2}), keySegment(upperKey("a"))},
+			`["{-1 2}"]["a"]`,
+		},
+		{
+			Path{keySegment(`a"]["b`), keySegment(true), keySegment(-1.5), keySegment(uint8(3))},
+			`["a\"][\"b"][true][-1.5][3]`,
+		},
+		{
+			Path{keySegment(level(2)), keySegment(nil)},
+			`["warn"][<nil>]`,
+		},
+	}
+
+	for i, c := range cases {
+		if got := c.path.String(); got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Path.String()\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				got, c.want)
+		}
+	}
+}
+
+func TestDiffs(t *testing.T) {
+
+	before := nestedTest{
+		Mapping: map[string][]string{
+			"a.b": []string{"hi", "there"},
+		},
+	}
+	after := nestedTest{
+		Mapping: map[string][]string{
+			"a.b": []string{"hi"},
+		},
+	}
+
+	want := []Diff{
+		{
+			Name: `.Mapping["a.b"][1]`,
+			Path: Path{
+				fieldSegment("Mapping"),
+				keySegment("a.b"),
+				indexSegment(1),
+			},
+			Before:      `"there"`,
+			After:       "",
+			Kind:        Delete,
+			BeforeType:  "string",
+			BeforeValue: "there",
+		},
+	}
+
+	got, err := Diffs(before, after)
+	if !reflect.DeepEqual(got, want) || err != nil {
+		t.Errorf(
+			"Diffs(%v, %v)\n"+
+				"    return %v, %v\n"+
+				"    wanted %v, nil",
+			before, after, got, err, want)
+	}
+}
+
+func TestPathFormat(t *testing.T) {
+
+	path := Path{
+		fieldSegment("Mapping"),
+		keySegment("a/b~c"),
+		indexSegment(3),
+		keySegment("it's"),
+		keySegment(7),
+	}
+
+	cases := []struct {
+		path  Path
+		style PathStyle
+		want  string
+	}{
+		{path, PathDefault, `.Mapping["a/b~c"][3]["it's"][7]`},
+		{path, PathJSONPointer, `/Mapping/a~1b~0c/3/it's/7`},
+		{path, PathJSONPath, `$.Mapping['a/b~c'][3]['it\'s']['7']`},
+		{path, PathJQ, `.Mapping["a/b~c"][3]["it's"]["7"]`},
+		{Path{keySegment(uuid{1, 2, 3, 4})}, PathJSONPointer, `/01020304`},
+		{nil, PathJSONPointer, ``},
+		{nil, PathJSONPath, `$`},
+		{nil, PathJQ, `.`},
+	}
+
+	for i, c := range cases {
+		if got := c.path.Format(c.style); got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Path.Format(%v)\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.style, got, c.want)
+		}
+	}
+}
+
+func TestWithPathStyle(t *testing.T) {
+
+	before := nestedTest{}
+	after := nestedTest{
+		Mapping: map[string][]string{
+			"yo": []string{"hi"},
+		},
+	}
+	want := []string{`/Mapping/yo/0`}
+
+	got, err := ChangedPaths(before, after, WithPathStyle(PathJSONPointer))
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"ChangedPaths(%v, %v, WithPathStyle(PathJSONPointer))\n"+
+				"    return %v, %v\n"+
+				"    wanted %v, nil",
+			before, after, got, err, want)
+	}
+}
+
+func TestPathHelpers(t *testing.T) {
+
+	path := Path{fieldSegment("Spec"), fieldSegment("Containers"), indexSegment(2), fieldSegment("Image")}
+
+	if got, want := path.Parent().String(), ".Spec.Containers[2]"; got != want {
+		t.Errorf("Path.Parent()\n    return %q\n    wanted %q", got, want)
+	}
+	if got, want := path.Last(), fieldSegment("Image"); got != want {
+		t.Errorf("Path.Last()\n    return %v\n    wanted %v", got, want)
+	}
+	if got := Path(nil).Parent(); got != nil {
+		t.Errorf("Path(nil).Parent()\n    return %v\n    wanted nil", got)
+	}
+	if got := Path(nil).Last(); got != (Segment{}) {
+		t.Errorf("Path(nil).Last()\n    return %v\n    wanted zero Segment", got)
+	}
+	if got, want := path.JSONPointer(), "/Spec/Containers/2/Image"; got != want {
+		t.Errorf("Path.JSONPointer()\n    return %q\n    wanted %q", got, want)
+	}
+}
+
+func TestPathMatch(t *testing.T) {
+
+	path := Path{fieldSegment("Spec"), fieldSegment("Containers"), indexSegment(2), fieldSegment("Image")}
+
+	cases := []struct {
+		pattern string
+		want    bool
+		wantErr bool
+	}{
+		{".Spec.Containers[2].Image", true, false},
+		{"Spec.Containers[*].Image", true, false},
+		{".Spec.*[*].*", true, false},
+		{".Spec.Containers[*]", false, false},
+		{".Spec.Containers[*].Image.Tag", false, false},
+		{".Spec.Containers.*.Image", false, false},
+		{"[*].Containers[2].Image", false, false},
+		{".Spec[", false, true},
+		{"", false, false},
+	}
+
+	for i, c := range cases {
+		got, err := path.Match(c.pattern)
+		if got != c.want || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Path.Match(%q)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, error: %v",
+				c.pattern, got, err, c.want, c.wantErr)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+
+	cases := []struct {
+		path    string
+		want    Path
+		wantErr bool
+	}{
+		{"", nil, false},
+		{
+			`.Mapping["a.b"][3]`,
+			Path{fieldSegment("Mapping"), keySegment("a.b"), indexSegment(3)},
+			false,
+		},
+		{
+			`["a\"][\"b"]["{-1 2}"][<nil>]`,
+			Path{keySegment(`a"]["b`), keySegment("{-1 2}"), keySegment(nil)},
+			false,
+		},
+		{
+			`[-7][true][1.5][(1+2i)][18446744073709551615]`,
+			Path{
+				keySegment(-7),
+				keySegment(true),
+				keySegment(1.5),
+				keySegment(complex(1, 2)),
+				keySegment(uint64(18446744073709551615)),
+			},
+			false,
+		},
+		{`[a]`, nil, true},
+		{`["a]`, nil, true},
+		{`.Spec[`, nil, true},
+		{`Spec`, nil, true},
+	}
+
+	for i, c := range cases {
+		got, err := ParsePath(c.path)
+		if !reflect.DeepEqual(got, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ParsePath(%q)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, error: %v",
+				c.path, got, err, c.want, c.wantErr)
+			continue
+		}
+		if err == nil && got.String() != c.path {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ParsePath(%q).String()\n"+
+					"    return %q\n"+
+					"    wanted %q",
+				c.path, got.String(), c.path)
+		}
+	}
+}
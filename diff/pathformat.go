@@ -0,0 +1,198 @@
+package diff
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// pathFormat is a Format set with WithPathFormat.
+type pathFormat struct {
+	pattern pathPattern
+	format  Format
+}
+
+/*
+pathPattern is a path in the default notation, split into
+its segments, in which a segment of `.*` matches any struct
+field and one of `[*]` matches any slice or array index or
+map key.
+*/
+type pathPattern struct {
+	text     string
+	segments []string
+}
+
+func (p pathPattern) String() string {
+	return p.text
+}
+
+/*
+parsePathPattern splits pattern into its segments. Each
+begins with a dot, followed by a field name, or is enclosed
+in brackets, which may contain a quoted string with brackets
+of its own.
+*/
+func parsePathPattern(pattern string) (pathPattern, error) {
+
+	p := pathPattern{text: pattern}
+
+	for i := 0; i < len(pattern); {
+
+		start := i
+
+		switch pattern[i] {
+		case '.':
+			i++
+			for i < len(pattern) && pattern[i] != '.' && pattern[i] != '[' {
+				i++
+			}
+			if i == start+1 {
+				return pathPattern{}, fmt.Errorf("empty field name at offset %d", start)
+			}
+		case '[':
+			end, err := closingBracket(pattern, i)
+			if err != nil {
+				return pathPattern{}, err
+			}
+			i = end + 1
+		default:
+			return pathPattern{}, fmt.Errorf("unexpected %q at offset %d", pattern[i], i)
+		}
+
+		p.segments = append(p.segments, pattern[start:i])
+	}
+
+	return p, nil
+}
+
+// closingBracket returns the index of the bracket closing
+// the one at index open in s, skipping any quoted string.
+func closingBracket(s string, open int) (int, error) {
+
+	quoted := false
+	for i := open + 1; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == ']':
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unclosed bracket at offset %d", open)
+}
+
+/*
+match reports whether path, or a path containing it, is
+matched by p.
+*/
+func (p pathPattern) match(path Path) bool {
+
+	path = path.visible()
+	if len(path) < len(p.segments) {
+		return false
+	}
+
+	for i, seg := range p.segments {
+		if !segmentMatches(seg, path[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+leadsTo reports whether path is the start of a path matched
+by p, so that what p matches may lie beneath it.
+*/
+func (p pathPattern) leadsTo(path Path) bool {
+
+	path = path.visible()
+	if len(path) > len(p.segments) {
+		return false
+	}
+
+	for i, s := range path {
+		if !segmentMatches(p.segments[i], s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// segmentMatches reports whether the segment seg of a
+// pattern matches s.
+func segmentMatches(seg string, s Segment) bool {
+	switch seg {
+	case ".*":
+		return s.Kind == FieldSegment
+	case "[*]":
+		return s.Kind == IndexSegment || s.Kind == KeySegment
+	}
+	return seg == s.String()
+}
+
+// matchAll reports whether the whole of path is matched by p.
+func (p pathPattern) matchAll(path Path) bool {
+	return len(path.visible()) == len(p.segments) && p.match(path)
+}
+
+/*
+overlayFormat substitutes the empty templates of format
+with those of base, which has already been filled in. Set
+and Clear aren't inherited, so that a format hiding the
+values of a change also applies to values being set.
+*/
+func overlayFormat(format, base Format) Format {
+
+	for _, t := range []struct {
+		dst *string
+		src string
+	}{
+		{&format.Change, base.Change},
+		{&format.Add, base.Add},
+		{&format.Delete, base.Delete},
+		{&format.Move, base.Move},
+		{&format.Same, base.Same},
+		{&format.Verb, base.Verb},
+	} {
+		if *t.dst == "" {
+			*t.dst = t.src
+		}
+	}
+
+	funcs := make(template.FuncMap, len(base.Funcs)+len(format.Funcs))
+	for name, fn := range base.Funcs {
+		funcs[name] = fn
+	}
+	for name, fn := range format.Funcs {
+		funcs[name] = fn
+	}
+	format.Funcs = funcs
+
+	return format
+}
+
+/*
+overlayDelims returns the delimiters of the templates that
+overlayFormat produces from format and a base whose
+delimiters are base.
+*/
+func overlayDelims(format Format, base delims) delims {
+
+	ds := delimsOf(format)
+	for name, d := range base {
+		if name == "set" || name == "clear" {
+			continue
+		}
+		if _, ok := ds[name]; !ok {
+			ds[name] = d
+		}
+	}
+
+	return ds
+}
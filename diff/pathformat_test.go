@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestParsePathPattern(t *testing.T) {
+
+	cases := []struct {
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{".Tags[*]", []string{".Tags", "[*]"}, false},
+		{`.M["a.b[c]"].*[0]`, []string{".M", `["a.b[c]"]`, ".*", "[0]"}, false},
+		{`["say \"]\""]`, []string{`["say \"]\""]`}, false},
+		{"Tags", nil, true},
+		{".Tags[", nil, true},
+		{"..Tags", nil, true},
+	}
+
+	for i, c := range cases {
+		got, err := parsePathPattern(c.pattern)
+		if !reflect.DeepEqual(got.segments, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"parsePathPattern(%q)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, error %v",
+				c.pattern, got.segments, err, c.want, c.wantErr)
+		}
+	}
+}
+
+func TestWithPathFormat(t *testing.T) {
+
+	type account struct {
+		Name     string
+		Password string
+		Tags     []string
+		Meta     map[string]string
+	}
+
+	before := account{"a", "hunter2", []string{"x"}, map[string]string{"k": "1"}}
+	after := account{"b", "hunter3", []string{"x", "y"}, map[string]string{"k": "2"}}
+
+	cases := []struct {
+		format Format
+		opts   []Option
+		want   []string
+	}{
+		{
+			Format{},
+			[]Option{
+				WithPathFormat(".Password", Format{Change: "{{.Name}} changed"}),
+				WithPathFormat(".Tags[*]", Format{Add: "{{.Name}} +{{.After}}"}),
+			},
+			[]string{
+				`.Name changed from "a" to "b"`,
+				`.Password changed`,
+				`.Tags[1] +"y"`,
+				`.Meta["k"] changed from "1" to "2"`,
+			},
+		},
+		{
+			Format{Change: "{{.Name}}: {{.After}}"},
+			[]Option{
+				WithPathFormat(".*", Format{Change: "{{upper .Name}}: {{.After}}"}),
+				WithPathFormat(".Meta", Format{Change: "ignored"}),
+			},
+			[]string{
+				`.NAME: "b"`,
+				`.PASSWORD: "hunter3"`,
+				`.Tags[1] added "y"`,
+				`.META["K"]: "2"`,
+			},
+		},
+		{
+			Format{Set: DefaultSet},
+			[]Option{
+				WithPathFormat(`.Meta["k"]`, Format{Change: "{{.Name}} updated"}),
+				WithPathStyle(PathJSONPointer),
+			},
+			[]string{
+				`/Name changed from "a" to "b"`,
+				`/Password changed from "hunter2" to "hunter3"`,
+				`/Tags/1 added "y"`,
+				`/Meta/k updated`,
+			},
+		},
+		{
+			Format{Change: "[[.Name]]: [[.After]]", LeftDelim: "[[", RightDelim: "]]"},
+			[]Option{
+				WithPathFormat(".Password", Format{Change: "<<.Name>> hidden", LeftDelim: "<<", RightDelim: ">>"}),
+				WithPathFormat(".Tags[*]", Format{Add: "{{.Name}} +{{.After}}"}),
+				WithPathFormat(".Meta", Format{Delete: "{{.Name}} gone"}),
+			},
+			[]string{
+				`.Name: "b"`,
+				`.Password hidden`,
+				`.Tags[1] +"y"`,
+				`.Meta["k"]: "2"`,
+			},
+		},
+	}
+
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+
+	for i, c := range cases {
+		if c.format.Change != "" {
+			c.format.Funcs = funcs
+		}
+		got, err := ObjectsF(c.format, before, after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.format, before, after, got, err, c.want)
+		}
+	}
+}
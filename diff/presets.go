@@ -0,0 +1,46 @@
+package diff
+
+/*
+These are ready-made Formats for use with ObjectsF and the
+other functions taking a Format, for when the default wording
+isn't wanted. Differences are rendered by each as follows:
+
+	FormatCompact:  .Timeout: 30 -> 60
+	                .Tags[2]: + "c"
+	                .Env["DEBUG"]: - "1"
+
+	FormatSymbols:  ~ .Timeout: 30 -> 60
+	                + .Tags[2]: "c"
+	                - .Env["DEBUG"]: "1"
+
+	FormatEmoji:    ✏️ .Timeout: 30 -> 60
+	                ➕ .Tags[2]: "c"
+	                ➖ .Env["DEBUG"]: "1"
+
+They should be treated as read-only; copy one to change it.
+*/
+var (
+	FormatCompact = Format{
+		Change: "{{.Name}}: {{.Before}} -> {{.After}}",
+		Add:    "{{.Name}}: + {{.After}}",
+		Delete: "{{.Name}}: - {{.Before}}",
+		Move:   "{{.Name}}: -> [{{.To}}]",
+		Same:   "{{.Name}}: = {{.After}}",
+	}
+
+	FormatSymbols = Format{
+		Change: "~ {{.Name}}: {{.Before}} -> {{.After}}",
+		Add:    "+ {{.Name}}: {{.After}}",
+		Delete: "- {{.Name}}: {{.Before}}",
+		Move:   "> {{.Name}}: [{{.To}}]",
+		Same:   "= {{.Name}}: {{.After}}",
+	}
+
+	FormatEmoji = Format{
+		Change: "✏️ {{.Name}}: {{.Before}} -> {{.After}}",
+		Add:    "➕ {{.Name}}: {{.After}}",
+		Delete: "➖ {{.Name}}: {{.Before}}",
+		Move:   "🔀 {{.Name}}: [{{.To}}]",
+		Same:   "⚪ {{.Name}}: {{.After}}",
+	}
+)
@@ -0,0 +1,60 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatPresets(t *testing.T) {
+
+	type service struct {
+		Timeout int
+		Tags    []string
+		Env     map[string]string
+	}
+
+	before := service{30, []string{"a", "b"}, map[string]string{"DEBUG": "1"}}
+	after := service{60, []string{"a", "b", "c"}, map[string]string{}}
+
+	cases := []struct {
+		format Format
+		want   []string
+	}{
+		{
+			FormatCompact,
+			[]string{
+				`.Timeout: 30 -> 60`,
+				`.Tags[2]: + "c"`,
+				`.Env["DEBUG"]: - "1"`,
+			},
+		},
+		{
+			FormatSymbols,
+			[]string{
+				`~ .Timeout: 30 -> 60`,
+				`+ .Tags[2]: "c"`,
+				`- .Env["DEBUG"]: "1"`,
+			},
+		},
+		{
+			FormatEmoji,
+			[]string{
+				`✏️ .Timeout: 30 -> 60`,
+				`➕ .Tags[2]: "c"`,
+				`➖ .Env["DEBUG"]: "1"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(c.format, before, after)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.format, before, after, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Structs generated by protoc-gen-go carry bookkeeping fields
+alongside the message's own fields. These differ between
+messages that are equal by proto.Equal, for example once one
+of them has been marshalled, so they are never diffed.
+Messages generated by the older github.com/golang/protobuf
+use fields prefixed with XXX_ instead.
+*/
+var protoInternalFields = map[string]bool{
+	"state":           true,
+	"sizeCache":       true,
+	"unknownFields":   true,
+	"extensionFields": true,
+}
+
+var protoTypes sync.Map
+
+/*
+isProtoMessage reports whether t is a struct generated by
+protoc-gen-go. These are recognised by the methods their
+pointers have, so the protobuf module isn't needed.
+*/
+func isProtoMessage(t reflect.Type) bool {
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if ok, cached := protoTypes.Load(t); cached {
+		return ok.(bool)
+	}
+
+	p := reflect.PtrTo(t)
+	_, ok := p.MethodByName("ProtoReflect")
+	if !ok {
+		_, ok = p.MethodByName("ProtoMessage")
+	}
+
+	protoTypes.Store(t, ok)
+	return ok
+}
+
+func isProtoInternal(f reflect.StructField) bool {
+	return protoInternalFields[f.Name] || strings.HasPrefix(f.Name, "XXX_")
+}
+
+/*
+protoNumber returns the field number in the protobuf struct
+tag of f, such as 2 for `protobuf:"bytes,2,opt,name=name"`,
+or zero if it has none. Oneof fields have no number of their
+own as each of their wrappers carries one instead.
+*/
+func protoNumber(f reflect.StructField) int {
+	parts := strings.Split(f.Tag.Get("protobuf"), ",")
+	if len(parts) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+/*
+protoElems follows pointers to protobuf messages so that
+nested messages are diffed field by field, as proto.Equal
+compares them, rather than by address. If v1 and v2 are
+fields of a message, any pointer is followed, such as the
+wrapper of a oneof member or a proto3 optional scalar, as
+those are compared by value too. A nil pointer is treated as
+absent unless both are nil.
+*/
+func protoElems(v1, v2 *reflect.Value, field bool) (*reflect.Value, *reflect.Value) {
+
+	follow := func(v *reflect.Value) bool {
+		return v != nil && v.Kind() == reflect.Ptr &&
+			(field || isProtoMessage(v.Type().Elem()))
+	}
+	if !follow(v1) && !follow(v2) {
+		return v1, v2
+	}
+	// Different members of a oneof are compared as a whole.
+	if v1 != nil && v2 != nil && v1.Type() != v2.Type() {
+		return v1, v2
+	}
+
+	nil1 := v1 == nil || v1.IsNil()
+	nil2 := v2 == nil || v2.IsNil()
+	if nil1 && nil2 {
+		return v1, v2
+	}
+
+	elem := func(v *reflect.Value, isNil bool) *reflect.Value {
+		if isNil {
+			return nil
+		}
+		e := v.Elem()
+		return &e
+	}
+
+	return elem(v1, nil1), elem(v2, nil2)
+}
@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+// These mimic the structs generated by protoc-gen-go.
+type protoUser struct {
+	state         struct{ atomic *int }
+	sizeCache     int32
+	unknownFields []byte
+
+	Id      int64         `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string        `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Address *protoAddress `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+type protoAddress struct {
+	state         struct{ atomic *int }
+	sizeCache     int32
+	unknownFields []byte
+
+	City string `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+}
+
+func (*protoUser) ProtoReflect()    {}
+func (*protoAddress) ProtoReflect() {}
+
+// legacyUser mimics github.com/golang/protobuf messages.
+type legacyUser struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (*legacyUser) ProtoMessage() {}
+
+func TestProtoMessages(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []string
+	}{
+		// Bookkeeping fields are ignored.
+		{
+			protoUser{sizeCache: 12, unknownFields: []byte{1}, Name: "a"},
+			protoUser{Name: "a"},
+			nil,
+			nil,
+		},
+		{
+			legacyUser{Name: "a", XXX_sizecache: 3},
+			legacyUser{Name: "b"},
+			nil,
+			[]string{`.Name changed from "a" to "b"`},
+		},
+
+		// Nested messages are followed.
+		{
+			protoUser{Address: &protoAddress{City: "Oslo", sizeCache: 4}},
+			protoUser{Address: &protoAddress{City: "Bergen"}},
+			nil,
+			[]string{`.Address.City changed from "Oslo" to "Bergen"`},
+		},
+		{
+			protoUser{},
+			protoUser{Address: &protoAddress{City: "Oslo"}},
+			nil,
+			[]string{`.Address.City added "Oslo"`},
+		},
+
+		// Field numbers.
+		{
+			protoUser{Id: 1, Address: &protoAddress{City: "Oslo"}},
+			protoUser{Id: 2, Address: &protoAddress{City: "Bergen"}},
+			[]Option{WithProtoFieldNumbers()},
+			[]string{
+				`.1 changed from 1 to 2`,
+				`.3.1 changed from "Oslo" to "Bergen"`,
+			},
+		},
+	}
+
+	nick := func(s string) *string { return &s }
+	email := func(s string) isProtoAccount_Contact { return &protoAccount_Email{s} }
+	phone := func(s string) isProtoAccount_Contact { return &protoAccount_Phone{s} }
+
+	cases = append(cases, []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []string
+	}{
+		// Oneof members and optional scalars are compared
+		// by value.
+		{
+			protoAccount{Contact: email("x")},
+			protoAccount{Contact: email("x")},
+			nil,
+			nil,
+		},
+		{
+			protoAccount{Contact: email("x")},
+			protoAccount{Contact: email("y")},
+			nil,
+			[]string{`.Contact.Email changed from "x" to "y"`},
+		},
+		{
+			protoAccount{Contact: email("x")},
+			protoAccount{Contact: phone("x")},
+			nil,
+			[]string{`.Contact changed from {x} to {x}`},
+		},
+		{
+			protoAccount{Nick: nick("x")},
+			protoAccount{Nick: nick("x")},
+			nil,
+			nil,
+		},
+		{
+			protoAccount{Nick: nick("x")},
+			protoAccount{Nick: nick("y")},
+			nil,
+			[]string{`.Nick changed from "x" to "y"`},
+		},
+		{
+			protoAccount{},
+			protoAccount{Nick: nick("")},
+			nil,
+			[]string{`.Nick added ""`},
+		},
+		{
+			protoAccount{Nick: nick("x")},
+			protoAccount{Nick: nick("x")},
+			[]Option{WithHashPruning()},
+			nil,
+		},
+	}...)
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%+v, %+v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
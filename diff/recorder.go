@@ -0,0 +1,238 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/*
+Recorder renders differences found by code other than this
+package's own traversal using the same templates and options
+as ObjectsF. It exists to support the code generated by
+cmd/diffgen, which compares struct fields directly rather
+than by reflection, and is unlikely to be useful otherwise.
+
+Run and Struct diff a pair of structs as ObjectsF would,
+applying every option to them and to each of their fields,
+but hand each field to a function so that it may be compared
+directly. That function uses Change for fields that are
+compared with ==, Struct for fields that are themselves
+handled this way, and Diff for anything else.
+
+A Recorder tracks the Path of the value being compared. When
+not using Run or Struct, Enter and Leave must be called in
+pairs around each comparison.
+*/
+type Recorder struct {
+	d      differ
+	redact []bool
+}
+
+/*
+NewRecorder returns a Recorder rendering with format and
+opts. Empty strings in format are substituted as they would
+be by ObjectsF. An error is returned if the templates cannot
+be parsed.
+*/
+func NewRecorder(format Format, opts ...Option) (*Recorder, error) {
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{d: differ{opts: o, templates: t}}, nil
+}
+
+/*
+Enter descends into the struct field, element, or map entry
+described by s. If redact is true everything beneath it is
+redacted as if it were tagged with `diff:"redact"`.
+*/
+func (r *Recorder) Enter(s Segment, redact bool) {
+	r.redact = append(r.redact, r.d.redacting)
+	r.d.pushPath(s)
+	if redact {
+		r.d.redacting = true
+	}
+}
+
+/*
+Leave returns to the location prior to the last call to Enter.
+*/
+func (r *Recorder) Leave() {
+	if len(r.redact) == 0 {
+		return
+	}
+	r.d.popPath()
+	r.d.redacting = r.redact[len(r.redact)-1]
+	r.redact = r.redact[:len(r.redact)-1]
+}
+
+/*
+Change records a change from before to after at the current
+location, treating them as the differ would a field holding
+them, so that options such as WithFields, WithFlags, and
+WithTransform apply. Values that are considered equal under
+the Recorder's options, such as strings differing only in
+whitespace when WithNormalizedWhitespace is used, are not
+recorded unless WithUnchanged is used.
+*/
+func (r *Recorder) Change(before, after interface{}) error {
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+	return r.d.safely(func() error {
+		return r.d.diff(&v1, &v2)
+	})
+}
+
+/*
+Unchanged reports whether the Recorder was created with
+WithUnchanged, in which case Change must be called for equal
+values as well as those that differ.
+*/
+func (r *Recorder) Unchanged() bool {
+	return r.d.opts.unchanged
+}
+
+/*
+Exhaustive reports whether Change must be called for every
+value compared with ==, including those that are equal,
+rather than only for those that differ. This is the case
+with WithUnchanged, and with Limits.MaxNodes, as each value
+compared counts towards the limit as it would for ObjectsF.
+*/
+func (r *Recorder) Exhaustive() bool {
+	return r.d.opts.unchanged || r.d.opts.limits.MaxNodes > 0
+}
+
+/*
+Diff records the differences between the values pointed to
+by before and after at the current location, using the same
+traversal as Objects. The pointers must be of the same type.
+*/
+func (r *Recorder) Diff(before, after interface{}) error {
+
+	v1, v2, err := elemsOf(before, after, "Diff")
+	if err != nil {
+		return err
+	}
+
+	return r.d.safely(func() error {
+		return r.d.diff(&v1, &v2)
+	})
+}
+
+/*
+Struct records the differences between the structs pointed
+to by before and after at the current location, which must be
+of the same type, as Diff would. Where Diff would compare
+them field by field, field is called instead with the index
+of each field in turn, after entering its location, in the
+order ObjectsF would visit them. Blank fields, which field
+can't refer to, are compared by reflection.
+*/
+func (r *Recorder) Struct(before, after interface{}, field func(i int) error) error {
+
+	v1, v2, err := elemsOf(before, after, "Struct")
+	if err != nil {
+		return err
+	}
+
+	return r.d.safely(func() error {
+		r.d.generated = &generatedStruct{&v1, &v2, field}
+		defer func() { r.d.generated = nil }()
+		return r.d.diff(&v1, &v2)
+	})
+}
+
+/*
+Run works as Struct does for the structs given to a
+generated diff function, returning their differences as
+ObjectsF would. Options applying to the structs as a whole,
+such as WithJSONRoundTrip, are applied first, which may
+leave field uncalled. Run must be called at most once, before
+any other use of the Recorder.
+*/
+func (r *Recorder) Run(before, after interface{}, field func(i int) error) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	v1, v2, err := elemsOf(before, after, "Run")
+	if err != nil {
+		return nil, err
+	}
+
+	r.d.generated = &generatedStruct{&v1, &v2, field}
+	err = r.d.run(&v1, &v2)
+	r.d.generated = nil
+	if err != nil {
+		return nil, err
+	}
+	if len(r.d.errs) > 0 {
+		return r.d.changes, errors.Join(r.d.errs...)
+	}
+
+	return r.d.changes, nil
+}
+
+// elemsOf returns what before and after point to, which
+// must be pointers of the same type, for method.
+func elemsOf(before, after interface{}, method string) (reflect.Value, reflect.Value, error) {
+
+	p1 := reflect.ValueOf(before)
+	p2 := reflect.ValueOf(after)
+
+	if p1.Kind() != reflect.Ptr || p1.Type() != p2.Type() {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf(
+			"Recorder.%s wants pointers of the same type, got %T and %T",
+			method, before, after)
+	}
+
+	return p1.Elem(), p2.Elem(), nil
+}
+
+/*
+generatedStruct holds the structs given to Recorder.Struct
+along with the function diffing their fields. The differ
+calls field in place of diffing their fields itself if it
+reaches v1 and v2 as they are, rather than, say, the result
+of a transform of them.
+*/
+type generatedStruct struct {
+	v1, v2 *reflect.Value
+	field  func(i int) error
+}
+
+/*
+generatedFor returns the function diffing the fields of v1
+and v2 given to Recorder.Struct, if they are those structs.
+It is returned only once, so that structs within them are
+diffed as usual.
+*/
+func (d *differ) generatedFor(v1, v2 *reflect.Value) func(i int) error {
+	g := d.generated
+	if g == nil || g.v1 != v1 || g.v2 != v2 {
+		return nil
+	}
+	d.generated = nil
+	return g.field
+}
+
+/*
+Changes returns the rendered differences recorded so far.
+*/
+func (r *Recorder) Changes() []string {
+	return r.d.changes
+}
+
+/*
+Err returns the errors recorded when using WithAggregateErrors,
+joined as they would be by ObjectsF, or nil if there were none.
+*/
+func (r *Recorder) Err() error {
+	return errors.Join(r.d.errs...)
+}
@@ -0,0 +1,213 @@
+package diff
+
+import (
+	"reflect"
+	"sync"
+)
+
+var redactableTypes sync.Map
+
+/*
+redactable reports whether a value of type t may contain a
+struct field tagged with `diff:"redact"`, such that it must
+be redacted before being rendered whole. Interfaces may hold
+anything and so are always redactable.
+*/
+func redactable(t reflect.Type) bool {
+
+	if ok, cached := redactableTypes.Load(t); cached {
+		return ok.(bool)
+	}
+
+	// Only the result for t itself is cached, as those of the
+	// types within it may have been found while assuming a
+	// recursive type wasn't redactable.
+	ok := redactableIn(t, map[reflect.Type]bool{})
+	redactableTypes.Store(t, ok)
+	return ok
+}
+
+func redactableIn(t reflect.Type, seen map[reflect.Type]bool) bool {
+
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Array, reflect.Slice:
+		return redactableIn(t.Elem(), seen)
+	case reflect.Map:
+		return redactableIn(t.Key(), seen) || redactableIn(t.Elem(), seen)
+	case reflect.Struct:
+		for _, fi := range fieldsOf(t) {
+			if fi.redact || redactableIn(t.Field(fi.index).Type, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+/*
+redactedCopy returns v with the values of the struct fields
+tagged with `diff:"redact"` within it replaced by placeholder,
+so that v may be rendered whole, such as when it is a leaf or
+the dynamic type of an interface changes. Only what must
+change is copied; v itself is left as it is. It returns false
+if a redacted field can't hold a string, in which case v must
+be replaced by placeholder as a whole.
+*/
+func redactedCopy(v reflect.Value, placeholder string) (reflect.Value, bool) {
+	r := redactor{placeholder: placeholder, seen: map[uintptr]reflect.Value{}}
+	return r.copy(v)
+}
+
+type redactor struct {
+	placeholder string
+
+	// mask, if not nil, gives the text replacing each
+	// redacted field in place of the placeholder.
+	mask func(f reflect.Value) string
+
+	// redacted counts the fields replaced so far.
+	redacted int
+
+	// seen holds the copies made of the values pointed to,
+	// by their addresses, so that cycles are copied as such.
+	seen map[uintptr]reflect.Value
+}
+
+func (r *redactor) copy(v reflect.Value) (reflect.Value, bool) {
+
+	if !v.IsValid() || !redactable(v.Type()) {
+		return v, true
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, true
+		}
+		e, ok := r.copy(v.Elem())
+		if !ok {
+			return v, false
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(e)
+		return c, true
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, true
+		}
+		if c, ok := r.seen[v.Pointer()]; ok {
+			return c, true
+		}
+		c := reflect.New(v.Type().Elem())
+		r.seen[v.Pointer()] = c
+		redacted := r.redacted
+		e, ok := r.copy(v.Elem())
+		if !ok {
+			return v, false
+		}
+		// Pointers to nothing redacted are kept as they are
+		// so that they print as the same address.
+		if r.redacted == redacted {
+			r.seen[v.Pointer()] = v
+			return v, true
+		}
+		c.Elem().Set(e)
+		return c, true
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for _, fi := range fieldsOf(v.Type()) {
+			f := field(c.Field(fi.index))
+			if fi.redact {
+				if !r.redact(f) {
+					return v, false
+				}
+				continue
+			}
+			e, ok := r.copy(*f)
+			if !ok {
+				return v, false
+			}
+			f.Set(e)
+		}
+		return c, true
+
+	case reflect.Array, reflect.Slice:
+		var c reflect.Value
+		if v.Kind() == reflect.Array {
+			c = reflect.New(v.Type()).Elem()
+		} else {
+			if v.IsNil() {
+				return v, true
+			}
+			c = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		}
+		for i := 0; i < v.Len(); i++ {
+			e, ok := r.copy(v.Index(i))
+			if !ok {
+				return v, false
+			}
+			c.Index(i).Set(e)
+		}
+		return c, true
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, true
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k, ok := r.copy(iter.Key())
+			if !ok {
+				return v, false
+			}
+			e, ok := r.copy(iter.Value())
+			if !ok {
+				return v, false
+			}
+			c.SetMapIndex(k, e)
+		}
+		return c, true
+	}
+
+	return v, true
+}
+
+// redact sets f to the placeholder, or its mask, returning
+// false if it can't hold a string.
+func (r *redactor) redact(f *reflect.Value) bool {
+
+	text := r.placeholder
+	if r.mask != nil {
+		// A nil interface is left as it is so that it's
+		// omitted from JSON as it would be otherwise.
+		if f.Kind() == reflect.Interface && f.IsNil() {
+			return true
+		}
+		text = r.mask(*f)
+	}
+	p := reflect.ValueOf(text)
+
+	switch {
+	case f.Kind() == reflect.String:
+		f.SetString(text)
+	case f.Kind() == reflect.Interface && p.Type().AssignableTo(f.Type()):
+		f.Set(p)
+	default:
+		return false
+	}
+
+	r.redacted++
+	return true
+}
@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type secretHolder struct {
+	Name     string
+	Password string `diff:"redact"`
+}
+
+type pinHolder struct {
+	Name string
+	PIN  int `diff:"redact"`
+}
+
+type wrapper struct {
+	Data interface{}
+}
+
+func TestRedactWhole(t *testing.T) {
+
+	type node struct {
+		Secret secretHolder
+		Next   *node
+	}
+	type leaf struct {
+		Owner  secretHolder
+		Tokens []interface{}
+	}
+
+	cyclic := &node{Secret: secretHolder{"a", "pw1"}}
+	cyclic.Next = cyclic
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		format Format
+		opts   []Option
+		want   []string
+	}{
+		{
+			wrapper{secretHolder{"bob", "hunter2"}},
+			wrapper{5},
+			Format{},
+			nil,
+			[]string{
+				`.Data changed from {bob [REDACTED]} to 5`,
+			},
+		},
+		{
+			wrapper{[]interface{}{secretHolder{"bob", "hunter2"}}},
+			wrapper{map[string]*secretHolder{"x": {"eve", "pw"}}},
+			Format{Verb: "%+v"},
+			[]Option{WithRedactPlaceholder("***")},
+			[]string{
+				`.Data changed from [{Name:bob Password:***}] to map[x:0x`,
+			},
+		},
+		{
+			wrapper{pinHolder{"bob", 1234}},
+			wrapper{"x"},
+			Format{},
+			nil,
+			[]string{
+				`.Data changed from [REDACTED] to "x"`,
+			},
+		},
+		{
+			leaf{secretHolder{"bob", "hunter2"}, []interface{}{secretHolder{"x", "pw"}}},
+			leaf{secretHolder{"bob", "hunter3"}, nil},
+			Format{Verb: "%+v"},
+			[]Option{WithLeafTypes(leaf{})},
+			[]string{
+				` changed from {Owner:{Name:bob Password:[REDACTED]} ` +
+					`Tokens:[{Name:x Password:[REDACTED]}]} ` +
+					`to {Owner:{Name:bob Password:[REDACTED]} Tokens:[]}`,
+			},
+		},
+		{
+			wrapper{cyclic},
+			wrapper{1},
+			Format{Change: "{{.Name}} {{.BeforeValue}}"},
+			nil,
+			[]string{
+				`.Data {{a [REDACTED]} 0x`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ObjectsF(c.format, c.before, c.after, c.opts...)
+		if !hasPrefixes(got, c.want) || err != nil || strings.Contains(fmt.Sprint(got), "pw") {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ObjectsF(%v, %+v, %+v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.format, c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestRedactWholeAt(t *testing.T) {
+
+	before := map[string]wrapper{"a": {secretHolder{"bob", "pw"}}}
+	after := map[string]wrapper{"a": {secretHolder{"bob", "pw2"}}, "b": {pinHolder{"eve", 1}}}
+
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{
+			`["a"].Data`,
+			[]string{
+				`["a"].Data.Password changed from [REDACTED] to [REDACTED]`,
+			},
+		},
+		{
+			`["b"]`,
+			[]string{
+				`["b"].Data.Name added "eve"`,
+				`["b"].Data.PIN added [REDACTED]`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := At(c.path, before, after)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"At(%q, %v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.path, before, after, got, err, c.want)
+		}
+	}
+
+	got, err := At(".Data", wrapper{secretHolder{"bob", "pw"}}, wrapper{false})
+	want := []string{`.Data changed from {bob [REDACTED]} to false`}
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"At(%q, ...)\n"+
+				"    return %q, %v\n"+
+				"    wanted %q, nil",
+			".Data", got, err, want)
+	}
+}
+
+// hasPrefixes reports whether each of ss begins with the
+// corresponding element of prefixes.
+func hasPrefixes(ss, prefixes []string) bool {
+	if len(ss) != len(prefixes) {
+		return false
+	}
+	for i := range ss {
+		if !strings.HasPrefix(ss[i], prefixes[i]) {
+			return false
+		}
+	}
+	return true
+}
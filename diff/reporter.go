@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"reflect"
+	"time"
+)
+
+/*
+Reporter receives the steps of a traversal of two objects
+along with the differences found, allowing output of any
+form to be built. See Report.
+*/
+type Reporter interface {
+
+	// Enter is called upon descending into the struct
+	// field, element, or map entry described by s.
+	Enter(s Segment)
+
+	// Exit is called upon returning from the step
+	// entered by the last call to Enter that hasn't yet
+	// been exited.
+	Exit()
+
+	// Report is called for each difference found at
+	// the current step, with its kind. Fields of d are
+	// set as they would be for a template.
+	Report(kind Kind, d Diff)
+}
+
+/*
+Report diffs before and after as Objects does, but rather
+than rendering each difference with a template it drives r,
+calling Enter and Exit as it descends into and returns from
+each struct field, element, and map entry it compares, and
+Report for each difference. Values that are skipped as they
+can be cheaply shown to be identical may not be entered.
+
+Several differences may be reported for a step, such as
+members added to and removed from a set. If the diff fails,
+Exit is not called for the steps in progress. Panics in the
+methods of r are not recovered.
+
+The same restrictions on before and after apply as for
+Objects and violating them will return an error.
+*/
+func Report(before, after interface{}, r Reporter, opts ...Option) (err error) {
+
+	defer observe(time.Now(), &err)
+
+	if err := validate(before, after); err != nil {
+		return err
+	}
+
+	v1 := reflect.ValueOf(before)
+	v2 := reflect.ValueOf(after)
+
+	d := differ{opts: newOptions(opts), reporter: r}
+	return d.run(&v1, &v2)
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// traceReporter records each call made to it, indented by
+// the depth of the traversal.
+type traceReporter struct {
+	depth int
+	calls []string
+}
+
+func (r *traceReporter) Enter(s Segment) {
+	r.calls = append(r.calls, strings.Repeat("  ", r.depth)+"enter "+s.String())
+	r.depth++
+}
+
+func (r *traceReporter) Exit() {
+	r.depth--
+	r.calls = append(r.calls, strings.Repeat("  ", r.depth)+"exit")
+}
+
+func (r *traceReporter) Report(kind Kind, d Diff) {
+	r.calls = append(r.calls, fmt.Sprintf("%s%s %s %v %v", strings.Repeat("  ", r.depth), kind, d.Name, d.Before, d.After))
+}
+
+func TestReport(t *testing.T) {
+
+	type item struct {
+		Name string
+		Qty  int
+	}
+	type cart struct {
+		Owner string
+		Items []item
+		Tags  map[string]struct{}
+	}
+
+	before := cart{"ann", []item{{"pen", 1}}, map[string]struct{}{"a": {}}}
+	after := cart{"ann", []item{{"pen", 2}, {"ink", 1}}, map[string]struct{}{"b": {}}}
+
+	want := []string{
+		`enter .Owner`,
+		`exit`,
+		`enter .Items`,
+		`  enter [0]`,
+		`    enter .Name`,
+		`    exit`,
+		`    enter .Qty`,
+		`      change .Items[0].Qty 1 2`,
+		`    exit`,
+		`  exit`,
+		`  enter [1]`,
+		`    enter .Name`,
+		`      add .Items[1].Name  "ink"`,
+		`    exit`,
+		`    enter .Qty`,
+		`      add .Items[1].Qty  1`,
+		`    exit`,
+		`  exit`,
+		`exit`,
+		`enter .Tags`,
+		`  delete .Tags "a" `,
+		`  add .Tags  "b"`,
+		`exit`,
+	}
+
+	r := &traceReporter{}
+	err := Report(before, after, r)
+	if !reflect.DeepEqual(r.calls, want) || err != nil {
+		t.Errorf(
+			"Report(%v, %v, r)\n"+
+				"    return %v, calls:\n%s\n"+
+				"    wanted nil, calls:\n%s",
+			before, after, err, strings.Join(r.calls, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := Report(1, 2, r); err == nil {
+		t.Errorf("Report(1, 2, r) returned nil error")
+	}
+}
@@ -0,0 +1,47 @@
+package diff
+
+import "reflect"
+
+/*
+isSet reports whether t is a map used as a set, one whose
+values are empty structs, as in map[string]struct{}. Such
+values carry no information so the differ reports changes to
+the keys themselves.
+*/
+func isSet(t reflect.Type) bool {
+	return t.Kind() == reflect.Map &&
+		t.Elem().Kind() == reflect.Struct &&
+		t.Elem().NumField() == 0
+}
+
+/*
+diffSet reports the members added to and removed from a set
+as differences of kind Add and Delete at the path of the set
+itself, with the member as the value, so that adding "b" to
+the set .Tags is rendered as `.Tags added "b"`.
+*/
+func (d *differ) diffSet(v1, v2 *reflect.Value) error {
+
+	for _, k := range alignMapKeys(v1, v2) {
+		key := k.key
+		if err := d.diffMember(k.before, k.after, &key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffMember reports the difference, if any, for a member
+// of a set that may be in either set.
+func (d *differ) diffMember(before, after bool, key *reflect.Value) error {
+	switch {
+	case !before:
+		return d.report(Diff{member: true}, Add, nil, key)
+	case !after:
+		return d.report(Diff{member: true}, Delete, key, nil)
+	case d.opts.unchanged:
+		return d.report(Diff{member: true}, Same, key, key)
+	}
+	return nil
+}
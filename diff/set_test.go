@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffSet(t *testing.T) {
+
+	type user struct {
+		Roles map[string]struct{}
+		IDs   map[int]struct{}
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []string
+	}{
+		{
+			user{
+				Roles: map[string]struct{}{"admin": {}, "dev": {}},
+				IDs:   map[int]struct{}{1: {}},
+			},
+			user{
+				Roles: map[string]struct{}{"dev": {}, "ops": {}},
+				IDs:   nil,
+			},
+			nil,
+			[]string{
+				`.Roles deleted "admin"`,
+				`.Roles added "ops"`,
+				`.IDs deleted 1`,
+			},
+		},
+		{
+			map[string]struct{}{"a": {}},
+			map[string]struct{}{"a": {}, "b": {}},
+			[]Option{WithUnchanged()},
+			[]string{
+				` unchanged "a"`,
+				` added "b"`,
+			},
+		},
+		{
+			map[string]map[string]struct{}{"x": {"a": {}}},
+			map[string]map[string]struct{}{"y": {"a": {}}},
+			nil,
+			[]string{
+				`["x"] deleted "a"`,
+				`["y"] added "a"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+)
+
+/*
+WriteSideBySide diffs before and after and writes to w an
+HTML table with a row for every leaf value of either, showing
+its path along with its value in before and in after, as a
+reviewer would see the objects side by side. Rows for values
+that changed, were added, deleted, or moved are highlighted
+and carry the class "diff-change", "diff-add", "diff-delete",
+or "diff-move" respectively, and unchanged rows the class
+"diff-same", so the default styling, written in a style
+element ahead of the table, may be overridden.
+
+All text is escaped with html/template. WithKinds has no
+effect. The same restrictions on before and after apply as
+for Objects and violating them will return an error, in
+which case nothing is written.
+*/
+func WriteSideBySide(w io.Writer, before, after interface{}, opts ...Option) error {
+
+	type row struct {
+		Class  string
+		Path   string
+		Before string
+		After  string
+	}
+
+	opts = append(opts, WithUnchanged(), WithKinds())
+
+	diffs, err := Diffs(before, after, opts...)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]row, len(diffs))
+	for i, d := range diffs {
+		r := row{Class: "diff-" + d.Kind.String(), Path: d.Name}
+		if d.Kind != Add {
+			r.Before = fmt.Sprint(d.Before)
+		}
+		if d.Kind != Delete {
+			r.After = fmt.Sprint(d.After)
+		}
+		if d.Kind == Move {
+			r.After = fmt.Sprintf("%v (moved to [%d])", d.After, d.To)
+		}
+		rows[i] = r
+	}
+
+	return sideBySide.Execute(w, rows)
+}
+
+var sideBySide = htmltemplate.Must(htmltemplate.New("side-by-side").Parse(`<style>
+.diff-side-by-side { border-collapse: collapse; font-family: monospace; }
+.diff-side-by-side th, .diff-side-by-side td { border: 1px solid #d0d7de; padding: 2px 8px; text-align: left; vertical-align: top; white-space: pre-wrap; }
+.diff-side-by-side .diff-change td, .diff-side-by-side .diff-move td { background: #fff8c5; }
+.diff-side-by-side .diff-add td.diff-after { background: #dafbe1; }
+.diff-side-by-side .diff-delete td.diff-before { background: #ffebe9; }
+.diff-side-by-side .diff-change td.diff-before { background: #ffebe9; }
+.diff-side-by-side .diff-change td.diff-after { background: #dafbe1; }
+</style>
+<table class="diff-side-by-side">
+<thead><tr><th>Path</th><th>Before</th><th>After</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr class="{{.Class}}"><td class="diff-path">{{.Path}}</td><td class="diff-before">{{.Before}}</td><td class="diff-after">{{.After}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+`))
@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWriteSideBySide(t *testing.T) {
+
+	type config struct {
+		Name    string
+		Timeout int
+		Hosts   []string
+	}
+
+	before := config{"<api>", 30, []string{"a", "b"}}
+	after := config{"<api>", 60, []string{"a"}}
+
+	var buf bytes.Buffer
+	if err := WriteSideBySide(&buf, before, after, WithKinds(Add)); err != nil {
+		t.Fatalf("WriteSideBySide(w, %v, %v) returned %v", before, after, err)
+	}
+	got := buf.String()
+
+	rows := []string{
+		`<tr class="diff-same"><td class="diff-path">.Name</td><td class="diff-before">&#34;&lt;api&gt;&#34;</td><td class="diff-after">&#34;&lt;api&gt;&#34;</td></tr>`,
+		`<tr class="diff-change"><td class="diff-path">.Timeout</td><td class="diff-before">30</td><td class="diff-after">60</td></tr>`,
+		`<tr class="diff-same"><td class="diff-path">.Hosts[0]</td><td class="diff-before">&#34;a&#34;</td><td class="diff-after">&#34;a&#34;</td></tr>`,
+		`<tr class="diff-delete"><td class="diff-path">.Hosts[1]</td><td class="diff-before">&#34;b&#34;</td><td class="diff-after"></td></tr>`,
+	}
+
+	last := 0
+	for i, r := range rows {
+		at := strings.Index(got, r)
+		if at < last {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"WriteSideBySide(w, %v, %v)\n"+
+					"    wrote %s\n"+
+					"    wanted row %s in order",
+				before, after, got, r)
+			continue
+		}
+		last = at
+	}
+	if n := strings.Count(got, "<tr class="); n != len(rows) {
+		t.Errorf("WriteSideBySide(w, %v, %v) wrote %d rows, wanted %d", before, after, n, len(rows))
+	}
+
+	buf.Reset()
+	err := WriteSideBySide(&buf, 1, 2)
+	if buf.Len() != 0 || !errors.Is(err, ErrNotObject) {
+		t.Errorf(
+			"WriteSideBySide(w, 1, 2)\n"+
+				"    wrote %q, returned %v\n"+
+				"    wanted nothing, ErrNotObject",
+			buf.String(), err)
+	}
+}
@@ -0,0 +1,69 @@
+//go:build go1.21
+
+package diff
+
+import (
+	"log/slog"
+)
+
+/*
+Attrs converts diffs, as returned by Diffs, to attributes for
+use with log/slog. Each difference becomes a group keyed by its
+Name containing its kind followed by its before value, after
+value, or both. Moves hold the element's original and new
+indices instead, and unchanged values hold only their value.
+Attrs and LogValuer are only built with Go 1.21 or later,
+which log/slog requires.
+
+	diffs, _ := diff.Diffs(before, after)
+	logger.LogAttrs(ctx, slog.LevelInfo, "entity updated", diff.Attrs(diffs)...)
+*/
+func Attrs(diffs []Diff) []slog.Attr {
+
+	attrs := make([]slog.Attr, 0, len(diffs))
+
+	for _, d := range diffs {
+
+		var group []slog.Attr
+		if d.Kind != 0 {
+			group = append(group, slog.String("kind", d.Kind.String()))
+		}
+
+		switch d.Kind {
+		case Add:
+			group = append(group, slog.Any("after", d.After))
+		case Delete:
+			group = append(group, slog.Any("before", d.Before))
+		case Move:
+			group = append(group, slog.Int("from", d.From), slog.Int("to", d.To))
+		case Same:
+			group = append(group, slog.Any("value", d.After))
+		default:
+			group = append(group,
+				slog.Any("before", d.Before),
+				slog.Any("after", d.After))
+		}
+
+		attrs = append(attrs, slog.Attr{Key: d.Name, Value: slog.GroupValue(group...)})
+	}
+
+	return attrs
+}
+
+/*
+LogValuer returns a slog.LogValuer that resolves to a group of
+the attributes returned by Attrs. Conversion is deferred until
+the value is logged, so it costs nothing when the log level is
+disabled.
+
+	logger.Debug("entity updated", "changes", diff.LogValuer(diffs))
+*/
+func LogValuer(diffs []Diff) slog.LogValuer {
+	return logValuer(diffs)
+}
+
+type logValuer []Diff
+
+func (l logValuer) LogValue() slog.Value {
+	return slog.GroupValue(Attrs(l)...)
+}
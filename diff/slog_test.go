@@ -0,0 +1,99 @@
+//go:build go1.21
+
+package diff
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestAttrs(t *testing.T) {
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   []slog.Attr
+	}{
+		{
+			config{true, "0.0.0", 30},
+			config{true, "0.0.1", 30},
+			nil,
+			[]slog.Attr{
+				slog.Group(".Version",
+					"kind", "change",
+					"before", `"0.0.0"`,
+					"after", `"0.0.1"`),
+			},
+		},
+		{
+			[]string{"a", "b"},
+			[]string{"a", "c", "d"},
+			nil,
+			[]slog.Attr{
+				slog.Group("[1]",
+					"kind", "change",
+					"before", `"b"`,
+					"after", `"c"`),
+				slog.Group("[2]",
+					"kind", "add",
+					"after", `"d"`),
+			},
+		},
+		{
+			[]int{1, 2, 3},
+			[]int{3, 1, 2},
+			[]Option{WithMoves()},
+			[]slog.Attr{
+				slog.Group("[2]",
+					"kind", "move",
+					"from", 2,
+					"to", 0),
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		diffs, err := Diffs(c.before, c.after, c.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := Attrs(diffs)
+		if !attrsEqual(got, c.want) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Attrs(Diffs(%v, %v))\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.before, c.after, got, c.want)
+		}
+
+		got = LogValuer(diffs).LogValue().Group()
+		if !attrsEqual(got, c.want) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"LogValuer(Diffs(%v, %v))\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.before, c.after, got, c.want)
+		}
+	}
+}
+
+func attrsEqual(a1, a2 []slog.Attr) bool {
+
+	if len(a1) != len(a2) {
+		return false
+	}
+
+	for i := range a1 {
+		if !a1[i].Equal(a2[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,636 @@
+package diff
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const snapshotVersion = 1
+
+type snapshot struct {
+	Version int           `json:"v"`
+	Root    *snapshotNode `json:"root"`
+}
+
+/*
+snapshotNode records a value. Leaves are recorded by their
+text as printed by fmt, except for redacted leaves which are
+recorded only by an HMAC of that text. See WithSnapshotKey.
+*/
+type snapshotNode struct {
+	Kind    string          `json:"k"`
+	Type    string          `json:"t,omitempty"`
+	Name    string          `json:"n,omitempty"`
+	Text    string          `json:"x,omitempty"`
+	Hash    string          `json:"h,omitempty"`
+	Nil     bool            `json:"nil,omitempty"`
+	Elem    *snapshotNode   `json:"e,omitempty"`
+	Fields  []snapshotField `json:"f,omitempty"`
+	Elems   []*snapshotNode `json:"s,omitempty"`
+	Entries []snapshotEntry `json:"m,omitempty"`
+}
+
+type snapshotField struct {
+	Name   string        `json:"n"`
+	Redact bool          `json:"r,omitempty"`
+	Value  *snapshotNode `json:"v"`
+}
+
+type snapshotEntry struct {
+	Key   *snapshotNode `json:"k"`
+	Value *snapshotNode `json:"v"`
+}
+
+/*
+Snapshot records the state of v so that it may later be diffed
+with DiffSnapshots, possibly in another process, without
+keeping v itself. The same restrictions apply to v as to the
+arguments of Objects.
+
+Leaf values are recorded as their text would be printed in a
+template and are compared by that text, so types are expected
+to print the same way when they are equal. Pointers, funcs,
+and channels print as addresses and so will usually differ
+between processes. The values of fields tagged with
+`diff:"redact"` are not stored, nor are they printed as part
+of any value that contains them; only an HMAC of them is kept
+so that changes can still be detected. Unless a key is given
+with WithSnapshotKey, a random key is chosen once per process,
+so redacted values recorded by different processes always
+compare as changed.
+
+WithSnapshotKey and WithRedactPlaceholder are the only options
+which apply to Snapshot.
+
+The returned bytes are JSON but their structure is not part
+of this package's API.
+*/
+func Snapshot(v interface{}, opts ...Option) (snap []byte, err error) {
+
+	if err := isObj(reflect.TypeOf(v), "v"); err != nil {
+		return nil, err
+	}
+
+	// Printing leaves may call String methods.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	o := newOptions(opts)
+	s := snapshotter{key: o.snapshotKey, placeholder: o.redactPlaceholder()}
+	if s.key == nil {
+		s.key = processKey()
+	}
+
+	root := s.of(reflect.ValueOf(v), false)
+	return json.Marshal(snapshot{Version: snapshotVersion, Root: root})
+}
+
+/*
+DiffSnapshots returns the difference between two values
+recorded by Snapshot in the same form as Objects. Before and
+After are rendered from the recorded text of each value
+and so are passed to a formatter set with WithValueFormatter
+as strings, except for values of Go's basic types such as
+int and string, which are restored. The fields of structs
+are matched by name, so snapshots taken before and after a
+field was added, removed, or moved may be diffed.
+
+WithMoves, WithHashPruning, and WithFlags have no effect.
+*/
+func DiffSnapshots(before, after []byte, opts ...Option) (changes []string, err error) {
+	return DiffSnapshotsF(Format{}, before, after, opts...)
+}
+
+/*
+DiffSnapshotsF works the same as DiffSnapshots with an
+additional parameter allowing for custom formatting, as with
+ObjectsF.
+*/
+func DiffSnapshotsF(format Format, before, after []byte, opts ...Option) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	s1, err := readSnapshot(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %v", err)
+	}
+	s2, err := readSnapshot(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %v", err)
+	}
+
+	if s1.Kind != s2.Kind {
+		return nil, &ObjectError{
+			Err:        ErrKindMismatch,
+			BeforeType: s1.Name,
+			AfterType:  s2.Name,
+			msg: fmt.Sprintf(
+				`objects must be same kind - "before" was %s, "after" was %s`,
+				s1.Kind, s2.Kind),
+		}
+	}
+	if s1.Name != s2.Name {
+		return nil, &ObjectError{
+			Err:        ErrTypeMismatch,
+			BeforeType: s1.Name,
+			AfterType:  s2.Name,
+			msg: fmt.Sprintf(
+				`objects must be same type - "before" was %s, "after" was %s`,
+				s1.Name, s2.Name),
+		}
+	}
+
+	o := newOptions(opts)
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	d := differ{opts: o, templates: t}
+	err = d.truncated(d.safely(func() error {
+		return d.diffSnapshot(s1, s2)
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return d.changes, nil
+}
+
+func readSnapshot(b []byte) (*snapshotNode, error) {
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	if s.Version != snapshotVersion || s.Root == nil {
+		return nil, fmt.Errorf("not a snapshot of version %d", snapshotVersion)
+	}
+	return s.Root, nil
+}
+
+type snapshotter struct {
+	key         []byte
+	placeholder string
+}
+
+var (
+	processKeyOnce sync.Once
+	processKeyData []byte
+)
+
+// processKey returns the key used for snapshots when none is
+// given, chosen at random the first time it's needed.
+func processKey() []byte {
+	processKeyOnce.Do(func() {
+		processKeyData = make([]byte, sha256.Size)
+		if _, err := rand.Read(processKeyData); err != nil {
+			panic(fmt.Sprintf("diff: can't read random snapshot key: %v", err))
+		}
+	})
+	return processKeyData
+}
+
+func (s *snapshotter) of(v reflect.Value, redact bool) *snapshotNode {
+
+	n := &snapshotNode{
+		Kind: v.Kind().String(),
+		Type: v.Type().String(),
+		Name: v.Type().Name(),
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		// The text is kept in case the interfaces on
+		// each side hold different types and so are
+		// compared as a whole.
+		s.setText(n, s.text(v), redact)
+		if v.IsNil() {
+			n.Nil = true
+		} else {
+			n.Elem = s.of(v.Elem(), redact)
+		}
+	case reflect.Struct:
+		// Unexported fields can't be read by fmt
+		// unless they're made accessible.
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for _, fi := range fieldsOf(v.Type()) {
+			n.Fields = append(n.Fields, snapshotField{
+				Name:   fi.name,
+				Redact: fi.redact,
+				Value:  s.of(*field(c.Field(fi.index)), redact || fi.redact),
+			})
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			n.Elems = append(n.Elems, s.of(v.Index(i), redact))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sortValues(keys)
+		for _, k := range keys {
+			n.Entries = append(n.Entries, snapshotEntry{
+				Key:   s.of(k, false),
+				Value: s.of(v.MapIndex(k), redact),
+			})
+		}
+	default:
+		s.setText(n, s.text(v), redact)
+	}
+
+	return n
+}
+
+/*
+text prints v as it would be rendered whole, with any fields
+tagged with `diff:"redact"` within it, such as those of a
+struct pointed to, replaced by the placeholder.
+*/
+func (s *snapshotter) text(v reflect.Value) string {
+	c, ok := redactedCopy(v, s.placeholder)
+	if !ok {
+		return s.placeholder
+	}
+	return fmt.Sprint(c.Interface())
+}
+
+// setText records text in n, or only its HMAC if redacted.
+func (s *snapshotter) setText(n *snapshotNode, text string, redact bool) {
+	if redact {
+		mac := hmac.New(sha256.New, s.key)
+		mac.Write([]byte(text))
+		n.Hash = hex.EncodeToString(mac.Sum(nil))
+	} else {
+		n.Text = text
+	}
+}
+
+/*
+diffSnapshot mirrors diff for snapshot nodes. A nil node
+means the value doesn't exist on that side.
+*/
+func (d *differ) diffSnapshot(n1, n2 *snapshotNode) error {
+
+	if ctx := d.opts.ctx; ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	n1, n2 = snapshotElems(n1, n2)
+
+	if d.opts.nilAsEmpty && snapshotEmpty(n1) && snapshotEmpty(n2) {
+		return nil
+	}
+
+	kind := ""
+	if n1 == nil {
+		kind = n2.Kind
+	} else {
+		kind = n1.Kind
+	}
+
+	switch kind {
+	case "struct":
+		return d.diffSnapshotStruct(n1, n2)
+	case "map":
+		return d.diffSnapshotMap(n1, n2)
+	case "array", "slice":
+		return d.diffSnapshotSequence(n1, n2)
+	}
+
+	return d.diffSnapshotAtom(n1, n2)
+}
+
+// snapshotElems is the equivalent of elems for snapshots.
+func snapshotElems(n1, n2 *snapshotNode) (*snapshotNode, *snapshotNode) {
+
+	isIface := func(n *snapshotNode) bool {
+		return n != nil && n.Kind == "interface" && !n.Nil
+	}
+
+	switch {
+	case n1 == nil && isIface(n2):
+		return nil, n2.Elem
+	case n2 == nil && isIface(n1):
+		return n1.Elem, nil
+	case isIface(n1) && isIface(n2) && n1.Elem.Type == n2.Elem.Type:
+		return n1.Elem, n2.Elem
+	}
+
+	return n1, n2
+}
+
+// snapshotEmpty is the equivalent of isEmpty for snapshots.
+func snapshotEmpty(n *snapshotNode) bool {
+
+	if n == nil {
+		return false
+	}
+	if n.Kind == "interface" {
+		if n.Nil {
+			return true
+		}
+		n = n.Elem
+	}
+
+	switch n.Kind {
+	case "slice":
+		return len(n.Elems) == 0
+	case "map":
+		return len(n.Entries) == 0
+	}
+	return false
+}
+
+/*
+diffSnapshotStruct diffs the fields of two structs by name,
+as the snapshots may have been taken by different versions
+of a program. Fields are taken in the order of n1, followed
+by those only n2 has, which are reported as added, while
+those only n1 has are reported as deleted.
+*/
+func (d *differ) diffSnapshotStruct(n1, n2 *snapshotNode) error {
+
+	type pair struct {
+		name          string
+		redact        bool
+		before, after *snapshotNode
+	}
+
+	var pairs []*pair
+	byName := map[string]*pair{}
+
+	add := func(n *snapshotNode, before bool) {
+		if n == nil {
+			return
+		}
+		for _, f := range n.Fields {
+			p, ok := byName[f.Name]
+			if !ok {
+				p = &pair{name: f.Name}
+				byName[f.Name] = p
+				pairs = append(pairs, p)
+			}
+			p.redact = p.redact || f.Redact
+			if before {
+				p.before = f.Value
+			} else {
+				p.after = f.Value
+			}
+		}
+	}
+	add(n1, true)
+	add(n2, false)
+
+	for _, p := range pairs {
+
+		redacting := d.redacting
+		if p.redact {
+			d.redacting = true
+		}
+
+		d.pushPath(fieldSegment(p.name))
+		err := d.diffSnapshot(p.before, p.after)
+		if err != nil {
+			return err
+		}
+		d.popPath()
+		d.redacting = redacting
+	}
+
+	return nil
+}
+
+func (d *differ) diffSnapshotSequence(n1, n2 *snapshotNode) error {
+
+	var e1, e2 []*snapshotNode
+	if n1 != nil {
+		e1 = n1.Elems
+	}
+	if n2 != nil {
+		e2 = n2.Elems
+	}
+
+	longest := len(e1)
+	if len(e2) > longest {
+		longest = len(e2)
+	}
+
+	for i := 0; i < longest; i++ {
+
+		var elem1, elem2 *snapshotNode
+		if i < len(e1) {
+			elem1 = e1[i]
+		}
+		if i < len(e2) {
+			elem2 = e2[i]
+		}
+
+		d.pushPath(indexSegment(i))
+		err := d.diffSnapshot(elem1, elem2)
+		if err != nil {
+			return err
+		}
+		d.popPath()
+	}
+
+	return nil
+}
+
+func (d *differ) diffSnapshotMap(n1, n2 *snapshotNode) error {
+
+	type pair struct {
+		key           *snapshotNode
+		before, after *snapshotNode
+	}
+
+	var order []string
+	pairs := map[string]*pair{}
+
+	add := func(n *snapshotNode, before bool) {
+		if n == nil {
+			return
+		}
+		for _, e := range n.Entries {
+			id := e.Key.Type + "\x00" + e.Key.Text
+			p, ok := pairs[id]
+			if !ok {
+				p = &pair{key: e.Key}
+				pairs[id] = p
+				order = append(order, id)
+			}
+			if before {
+				p.before = e.Value
+			} else {
+				p.after = e.Value
+			}
+		}
+	}
+	add(n1, true)
+	add(n2, false)
+
+	// Keys are restored from their snapshots in order to sort
+	// them as diffMap does. Those that can't be restored sort
+	// by their text instead.
+	sort.SliceStable(order, func(i, j int) bool {
+		k1 := snapshotLeaf(pairs[order[i]].key)
+		k2 := snapshotLeaf(pairs[order[j]].key)
+		return compareValues(k1, k2) < 0
+	})
+
+	for _, id := range order {
+
+		p := pairs[id]
+
+		// Sets are recognised by their members as an empty
+		// map's snapshot doesn't record its value type.
+		if snapshotMember(p.before) || snapshotMember(p.after) {
+			key := snapshotLeaf(p.key)
+			err := d.diffMember(p.before != nil, p.after != nil, &key)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		d.pushPath(keySegment(snapshotLeaf(p.key).Interface()))
+		err := d.diffSnapshot(p.before, p.after)
+		if err != nil {
+			return err
+		}
+		d.popPath()
+	}
+
+	return nil
+}
+
+// snapshotMember reports whether n is the value of a member
+// of a set, an empty struct. See isSet.
+func snapshotMember(n *snapshotNode) bool {
+	return n != nil && n.Kind == "struct" && len(n.Fields) == 0
+}
+
+func (d *differ) diffSnapshotAtom(n1, n2 *snapshotNode) error {
+
+	var kind Kind
+
+	switch {
+	case n1 == nil:
+		kind = Add
+	case n2 == nil:
+		kind = Delete
+	case !snapshotAtomsEqual(n1, n2):
+		kind = Change
+	case d.opts.unchanged:
+		kind = Same
+	default:
+		return nil
+	}
+
+	var v1, v2 *reflect.Value
+	if n1 != nil {
+		l := snapshotLeaf(n1)
+		v1 = &l
+	}
+	if n2 != nil {
+		l := snapshotLeaf(n2)
+		v2 = &l
+	}
+
+	return d.report(Diff{}, kind, v1, v2)
+}
+
+func snapshotAtomsEqual(n1, n2 *snapshotNode) bool {
+	return snapshotType(n1) == snapshotType(n2) &&
+		n1.Hash+n1.Text == n2.Hash+n2.Text
+}
+
+// snapshotType returns the dynamic type of a node.
+func snapshotType(n *snapshotNode) string {
+	if n.Kind == "interface" && !n.Nil {
+		return n.Elem.Type
+	}
+	return n.Type
+}
+
+// snapshotText is printed as is rather than quoted.
+type snapshotText string
+
+var basicTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		false, "", uintptr(0),
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), complex64(0), complex128(0),
+	} {
+		t := reflect.TypeOf(v)
+		basicTypes[t.Name()] = t
+	}
+}
+
+/*
+snapshotLeaf returns a value standing in for the original
+leaf. Values of Go's basic types are restored as such; any
+other value is represented by its text.
+*/
+func snapshotLeaf(n *snapshotNode) reflect.Value {
+
+	text := reflect.ValueOf(snapshotText(n.Text))
+
+	if n.Kind == "interface" && !n.Nil && n.Elem.Kind != "interface" {
+		n = n.Elem
+	}
+
+	t, ok := basicTypes[n.Type]
+	if !ok || n.Hash != "" {
+		return text
+	}
+
+	v := reflect.New(t).Elem()
+	var err error
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(n.Text)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(n.Text)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(n.Text, 10, t.Bits())
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, err = strconv.ParseUint(n.Text, 10, t.Bits())
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(n.Text, t.Bits())
+		v.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		c, err = strconv.ParseComplex(n.Text, t.Bits())
+		v.SetComplex(c)
+	}
+	if err != nil {
+		return text
+	}
+
+	return v
+}
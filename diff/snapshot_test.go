@@ -0,0 +1,312 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+
+	type inner struct {
+		N int8
+	}
+	type entity struct {
+		Name     string
+		Status   code
+		Score    float32
+		Tags     []string
+		Attrs    map[int]string
+		Any      interface{}
+		Inner    inner
+		password string `diff:"redact"`
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+	}{
+		{
+			config{true, "0.0.0", 30},
+			config{false, "0.0.1", 15},
+		},
+		{
+			entity{
+				Name:     "a",
+				Status:   "x",
+				Score:    0.1,
+				Tags:     []string{"a", "b"},
+				Attrs:    map[int]string{1: "one"},
+				Any:      1,
+				Inner:    inner{1},
+				password: "hunter2",
+			},
+			entity{
+				Name:     "b",
+				Status:   "y",
+				Score:    0.2,
+				Tags:     []string{"a"},
+				Attrs:    map[int]string{1: "uno"},
+				Any:      "one",
+				Inner:    inner{2},
+				password: "hunter3",
+			},
+		},
+		{
+			nestedTest{},
+			nestedTest{
+				Mapping: map[string][]string{
+					"yo": []string{"hi"},
+				},
+			},
+		},
+		{
+			[]interface{}{map[string]int{"a": 1}, 2},
+			[]interface{}{map[string]int{"a": 2}, 2.5},
+		},
+		{
+			map[string]map[int]struct{}{"a": {1: {}, 2: {}}},
+			map[string]map[int]struct{}{"a": {2: {}, 3: {}}, "b": {}},
+		},
+	}
+
+	for i, c := range cases {
+
+		want, wantErr := Objects(c.before, c.after)
+
+		s1, err1 := Snapshot(c.before)
+		s2, err2 := Snapshot(c.after)
+		if err1 != nil || err2 != nil {
+			t.Fatalf("Snapshot returned %v, %v", err1, err2)
+		}
+
+		got, err := DiffSnapshots(s1, s2)
+		if !equal(got, want) || err != wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"DiffSnapshots(Snapshot(%v), Snapshot(%v))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, %v",
+				c.before, c.after, got, err, want, wantErr)
+		}
+	}
+}
+
+func TestDiffSnapshotsNilAsEmpty(t *testing.T) {
+
+	type row struct {
+		Meta  map[string]string
+		Extra interface{}
+		Rows  []interface{}
+	}
+
+	before := row{nil, nil, []interface{}{nil}}
+	after := row{
+		map[string]string{},
+		map[string]interface{}{},
+		[]interface{}{[]int{}},
+	}
+
+	s1, err1 := Snapshot(before)
+	s2, err2 := Snapshot(after)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Snapshot returned %v, %v", err1, err2)
+	}
+
+	cases := []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			nil,
+			[]string{
+				`.Extra changed from <nil> to map[]`,
+				`.Rows[0] changed from <nil> to []`,
+			},
+		},
+		{
+			[]Option{WithNilAsEmpty()},
+			nil,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := DiffSnapshots(s1, s2, c.opts...)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"DiffSnapshots(Snapshot(%v), Snapshot(%v))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, got, err, c.want)
+		}
+	}
+}
+
+func TestSnapshotRedaction(t *testing.T) {
+
+	type account struct {
+		Password string `diff:"redact"`
+	}
+
+	digest := sha256.Sum256([]byte("hunter2"))
+
+	cases := []interface{}{
+		account{"hunter2"},
+		wrapper{secretHolder{"bob", "hunter2"}},
+		wrapper{[]interface{}{&secretHolder{"bob", "hunter2"}}},
+	}
+
+	for i, c := range cases {
+		snap, err := Snapshot(c)
+		if err != nil ||
+			bytes.Contains(snap, []byte("hunter2")) ||
+			bytes.Contains(snap, []byte(hex.EncodeToString(digest[:]))) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf("Snapshot(%+v) contains redacted value: %s, %v", c, snap, err)
+		}
+	}
+
+	// Pointers beside a redacted field keep their address.
+	type linked struct {
+		Secret secretHolder
+		Next   *wrapper
+	}
+	v := wrapper{&linked{secretHolder{"bob", "hunter2"}, &wrapper{1}}}
+	s1, _ := Snapshot(v)
+	s2, _ := Snapshot(v)
+	if got, err := DiffSnapshots(s1, s2); got != nil || err != nil {
+		t.Errorf("DiffSnapshots of the same value returned %q, %v", got, err)
+	}
+}
+
+func TestSnapshotKey(t *testing.T) {
+
+	before := wrapper{secretHolder{"bob", "hunter2"}}
+	after := wrapper{secretHolder{"bob", "hunter3"}}
+
+	cases := []struct {
+		key1, key2 []byte
+		after      interface{}
+		want       []string
+	}{
+		{
+			nil, nil,
+			before,
+			nil,
+		},
+		{
+			nil, nil,
+			after,
+			[]string{`.Data.Password changed from [REDACTED] to [REDACTED]`},
+		},
+		{
+			[]byte("k"), []byte("k"),
+			before,
+			nil,
+		},
+		{
+			[]byte("k"), []byte("k"),
+			after,
+			[]string{`.Data.Password changed from [REDACTED] to [REDACTED]`},
+		},
+		{
+			[]byte("k1"), []byte("k2"),
+			before,
+			[]string{`.Data.Password changed from [REDACTED] to [REDACTED]`},
+		},
+	}
+
+	for i, c := range cases {
+		s1, err1 := Snapshot(before, WithSnapshotKey(c.key1))
+		s2, err2 := Snapshot(c.after, WithSnapshotKey(c.key2))
+		got, err := DiffSnapshots(s1, s2)
+		if !equal(got, c.want) || err1 != nil || err2 != nil || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"DiffSnapshots(%+v, %+v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestDiffSnapshotsLayout(t *testing.T) {
+
+	// Each version of account is a different type of the
+	// same name, as if from different builds of a program.
+	v1 := func() []byte {
+		type account struct {
+			Name  string
+			Roles []string
+			Age   int
+		}
+		s, _ := Snapshot(account{"ann", []string{"a"}, 30})
+		return s
+	}()
+	v2 := func() []byte {
+		type account struct {
+			Age   int
+			Name  string
+			Email string
+		}
+		s, _ := Snapshot(account{31, "ann", "a@b"})
+		return s
+	}()
+
+	cases := []struct {
+		before, after []byte
+		want          []string
+	}{
+		{
+			v1,
+			v2,
+			[]string{
+				`.Roles[0] deleted "a"`,
+				`.Age changed from 30 to 31`,
+				`.Email added "a@b"`,
+			},
+		},
+		{
+			v2,
+			v1,
+			[]string{
+				`.Age changed from 31 to 30`,
+				`.Email deleted "a@b"`,
+				`.Roles[0] added "a"`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := DiffSnapshots(c.before, c.after)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"DiffSnapshots(%s, %s)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestDiffSnapshotsErrors(t *testing.T) {
+
+	s1, _ := Snapshot(config{})
+	s2, _ := Snapshot(notConfig{})
+
+	if _, err := DiffSnapshots(s1, s2); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("DiffSnapshots of different types returned %v, wanted %v", err, ErrTypeMismatch)
+	}
+	if _, err := DiffSnapshots(s1, []byte(`{}`)); err == nil {
+		t.Errorf("DiffSnapshots of an invalid snapshot returned nil error")
+	}
+	if _, err := Snapshot(5); !errors.Is(err, ErrNotObject) {
+		t.Errorf("Snapshot(5) returned %v, wanted %v", err, ErrNotObject)
+	}
+}
@@ -0,0 +1,43 @@
+package diff
+
+import "fmt"
+
+/*
+Span is the part of a tracing span used by WithSpanEvents.
+*/
+type Span interface {
+	AddEvent(name string, attrs []Attribute)
+}
+
+/*
+Attribute is a key and value attached to an event recorded
+on a Span.
+*/
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// addEvent records s on the span set with WithSpanEvents.
+func (d *differ) addEvent(s Diff) {
+
+	max := d.opts.maxEvents
+	d.events++
+
+	switch {
+	case max > 0 && d.events == max+1:
+		d.opts.span.AddEvent("diff.truncated", []Attribute{
+			{"diff.max_events", fmt.Sprint(max)},
+		})
+		return
+	case max > 0 && d.events > max:
+		return
+	}
+
+	d.opts.span.AddEvent("diff.difference", []Attribute{
+		{"diff.kind", s.Kind.String()},
+		{"diff.path", s.Name},
+		{"diff.before", fmt.Sprint(s.Before)},
+		{"diff.after", fmt.Sprint(s.After)},
+	})
+}
@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testSpan struct {
+	events []string
+}
+
+func (s *testSpan) AddEvent(name string, attrs []Attribute) {
+	s.events = append(s.events, fmt.Sprint(name, attrs))
+}
+
+func TestWithSpanEvents(t *testing.T) {
+
+	type secret struct {
+		User     string
+		Password string `diff:"redact"`
+		Tags     []string
+	}
+
+	before := secret{"a", "x", []string{"1"}}
+	after := secret{"b", "y", []string{"1", "2", "3"}}
+
+	cases := []struct {
+		max  int
+		want []string
+	}{
+		{
+			0,
+			[]string{
+				`diff.difference[{diff.kind change} {diff.path .User} {diff.before "a"} {diff.after "b"}]`,
+				`diff.difference[{diff.kind change} {diff.path .Password} {diff.before [REDACTED]} {diff.after [REDACTED]}]`,
+				`diff.difference[{diff.kind add} {diff.path .Tags[1]} {diff.before } {diff.after "2"}]`,
+				`diff.difference[{diff.kind add} {diff.path .Tags[2]} {diff.before } {diff.after "3"}]`,
+			},
+		},
+		{
+			2,
+			[]string{
+				`diff.difference[{diff.kind change} {diff.path .User} {diff.before "a"} {diff.after "b"}]`,
+				`diff.difference[{diff.kind change} {diff.path .Password} {diff.before [REDACTED]} {diff.after [REDACTED]}]`,
+				`diff.truncated[{diff.max_events 2}]`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		span := &testSpan{}
+		changes, err := Objects(before, after, WithSpanEvents(span, c.max))
+		if !reflect.DeepEqual(span.events, c.want) || len(changes) != 4 || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithSpanEvents(span, %d))\n"+
+					"    recorded %q, %v\n"+
+					"    wanted %q, nil",
+				before, after, c.max, span.events, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package diff
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"unicode/utf8"
+)
+
+/*
+sqlNull stands in for the value of a database/sql Null type,
+such as sql.NullString, that isn't Valid. It is rendered as
+NULL.
+*/
+type sqlNull struct{}
+
+/*
+sqlNullValue reports whether t is one of the Null types in
+database/sql, such as sql.NullInt64 or sql.Null[T], and if so
+returns the index of the field holding its value. These are
+structs of a value and a Valid field.
+*/
+func sqlNullValue(t reflect.Type) (int, bool) {
+
+	if t.Kind() != reflect.Struct || t.PkgPath() != "database/sql" || t.NumField() != 2 {
+		return 0, false
+	}
+
+	valid, ok := t.FieldByName("Valid")
+	if !ok || valid.Type.Kind() != reflect.Bool {
+		return 0, false
+	}
+
+	return 1 - valid.Index[0], true
+}
+
+/*
+sqlNullElem returns the value held by v, a database/sql Null
+type whose value is held in the field at index, or sqlNull if
+it isn't Valid.
+*/
+func sqlNullElem(v *reflect.Value, index int) *reflect.Value {
+	if v == nil {
+		return nil
+	}
+	if !v.FieldByName("Valid").Bool() {
+		null := reflect.ValueOf(sqlNull{})
+		return &null
+	}
+	e := v.Field(index)
+	return &e
+}
+
+/*
+diffSQLNull compares two database/sql Null values as leaves
+by their values, so that a change is reported once rather
+than as separate changes to Valid and the value.
+*/
+func (d *differ) diffSQLNull(v1, v2 *reflect.Value, index int) error {
+	return d.diffAtom(sqlNullElem(v1, index), sqlNullElem(v2, index))
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+/*
+isValuer reports whether t, or a pointer to t, implements
+driver.Valuer. The Null types of database/sql are excluded
+as they're handled by diffSQLNull.
+*/
+func isValuer(t reflect.Type) bool {
+	if _, ok := sqlNullValue(t); ok {
+		return false
+	}
+	return t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType)
+}
+
+/*
+driverValue returns the value v produces for a database
+driver. A nil value is returned as sqlNull and a []byte
+holding valid UTF-8 as a string, which is how such values
+are usually meant to be read.
+*/
+func driverValue(v *reflect.Value) (*reflect.Value, error) {
+
+	if v == nil {
+		return nil, nil
+	}
+
+	m, ok := implementer(v.Interface(), valuerType)
+	if !ok {
+		return v, nil
+	}
+
+	dv, err := m.(driver.Valuer).Value()
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := dv.([]byte); ok && utf8.Valid(b) {
+		dv = string(b)
+	}
+	if dv == nil {
+		dv = sqlNull{}
+	}
+
+	r := reflect.ValueOf(dv)
+	return &r, nil
+}
+
+/*
+diffValuer compares two values implementing driver.Valuer
+as leaves by the values they produce for a database driver,
+rather than by their fields or elements.
+*/
+func (d *differ) diffValuer(v1, v2 *reflect.Value) error {
+
+	dv1, err := driverValue(v1)
+	if err != nil {
+		return &PathError{Path: d.name(), Err: err}
+	}
+	dv2, err := driverValue(v2)
+	if err != nil {
+		return &PathError{Path: d.name(), Err: err}
+	}
+
+	return d.diffAtom(dv1, dv2)
+}
@@ -0,0 +1,164 @@
+package diff
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSQLNull(t *testing.T) {
+
+	type row struct {
+		Name  sql.NullString
+		Count sql.NullInt64
+		Seen  sql.NullTime
+		Score sql.Null[float64]
+	}
+
+	seen := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		before row
+		after  row
+		want   []string
+	}{
+		{
+			row{},
+			row{},
+			nil,
+		},
+
+		// An invalid value is NULL whatever it holds.
+		{
+			row{Count: sql.NullInt64{Int64: 3}},
+			row{},
+			nil,
+		},
+		{
+			row{},
+			row{
+				Name:  sql.NullString{String: "a", Valid: true},
+				Count: sql.NullInt64{Int64: 5, Valid: true},
+				Seen:  sql.NullTime{Time: seen, Valid: true},
+				Score: sql.Null[float64]{V: 1.5, Valid: true},
+			},
+			[]string{
+				`.Name changed from NULL to "a"`,
+				`.Count changed from NULL to 5`,
+				`.Seen changed from NULL to 2020-01-02 03:04:05 +0000 UTC`,
+				`.Score changed from NULL to 1.5`,
+			},
+		},
+		{
+			row{Count: sql.NullInt64{Int64: 5, Valid: true}},
+			row{Count: sql.NullInt64{Int64: 0, Valid: true}},
+			[]string{`.Count changed from 5 to 0`},
+		},
+		{
+			row{Name: sql.NullString{String: "", Valid: true}},
+			row{},
+			[]string{`.Name changed from "" to NULL`},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+type money struct {
+	cents    int64
+	currency string
+}
+
+func (m money) Value() (driver.Value, error) {
+	if m.currency == "" {
+		return nil, nil
+	}
+	return fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency), nil
+}
+
+type jsonColumn struct {
+	raw []byte
+}
+
+func (j *jsonColumn) Value() (driver.Value, error) {
+	if j.raw == nil {
+		return nil, errors.New("no value")
+	}
+	return j.raw, nil
+}
+
+func TestValuer(t *testing.T) {
+
+	type row struct {
+		Price money
+		Meta  jsonColumn
+		Note  sql.NullString
+	}
+
+	meta := jsonColumn{[]byte(`{"a":1}`)}
+
+	cases := []struct {
+		before row
+		after  row
+		want   []string
+	}{
+		{
+			row{money{150, "EUR"}, meta, sql.NullString{}},
+			row{money{150, "EUR"}, meta, sql.NullString{}},
+			nil,
+		},
+		{
+			row{money{150, "EUR"}, meta, sql.NullString{}},
+			row{money{1250, "USD"}, jsonColumn{[]byte(`{"a":2}`)}, sql.NullString{}},
+			[]string{
+				`.Price changed from "1.50 EUR" to "12.50 USD"`,
+				`.Meta changed from "{\"a\":1}" to "{\"a\":2}"`,
+			},
+		},
+		{
+			row{money{}, meta, sql.NullString{}},
+			row{money{99, "GBP"}, jsonColumn{[]byte{0xff}}, sql.NullString{}},
+			[]string{
+				`.Price changed from NULL to "0.99 GBP"`,
+				`.Meta changed from "{\"a\":1}" to [255]`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after)
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+
+	before := row{Meta: meta}
+	after := row{}
+	_, err := Objects(before, after)
+	var pathErr *PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != ".Meta" {
+		t.Errorf(
+			"Objects(%v, %v)\n"+
+				"    return %v\n"+
+				"    wanted *PathError at .Meta",
+			before, after, err)
+	}
+}
@@ -0,0 +1,85 @@
+package diff
+
+/*
+Count is the number of leaves compared within before and
+after, such as the fields of a struct that hold strings or
+numbers, and how many of those differ. A leaf added or
+deleted in its entirety, such as a map entry holding a
+struct, counts once.
+*/
+type Count struct {
+	Leaves  int
+	Changed int
+}
+
+/*
+Fraction returns the fraction of leaves that changed, from 0
+to 1. It is 0 if no leaves were compared.
+*/
+func (c Count) Fraction() float64 {
+	if c.Leaves == 0 {
+		return 0
+	}
+	return float64(c.Changed) / float64(c.Leaves)
+}
+
+/*
+Stats holds the Count of before and after as a whole along
+with that of each top-level field, entry, or element, keyed
+by its path rendered in the style chosen with WithPathStyle.
+*/
+type Stats struct {
+	Count
+	Fields map[string]Count
+}
+
+/*
+ChangeStats counts the leaves of before and after that
+changed, overall and for each top-level field, entry, or
+element. A leaf is anything Objects would describe with a
+single difference and is counted as changed if it was
+changed, added, deleted, or moved. A large fraction of
+changes may suggest that an object was replaced wholesale
+rather than edited:
+
+	stats, _ := diff.ChangeStats(before, after)
+	if stats.Fraction() > 0.5 {
+		return errors.New("more than half of the settings changed")
+	}
+
+The same restrictions on before and after apply as for
+Objects and violating them will return an error. WithKinds
+has no effect.
+*/
+func ChangeStats(before, after interface{}, opts ...Option) (Stats, error) {
+
+	opts = append(opts, WithUnchanged(), WithKinds())
+
+	diffs, err := Diffs(before, after, opts...)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	style := newOptions(opts).pathStyle
+
+	stats := Stats{Fields: make(map[string]Count)}
+	for _, d := range diffs {
+
+		top := d.Path
+		if len(top) > 1 {
+			top = top[:1]
+		}
+		key := top.Format(style)
+
+		c := stats.Fields[key]
+		c.Leaves++
+		stats.Leaves++
+		if d.Kind != Same {
+			c.Changed++
+			stats.Changed++
+		}
+		stats.Fields[key] = c
+	}
+
+	return stats, nil
+}
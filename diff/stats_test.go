@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChangeStats(t *testing.T) {
+
+	type server struct {
+		Host  string
+		Port  int
+		Flags map[string]bool
+	}
+	type deployment struct {
+		Name    string
+		Servers []server
+		Limits  map[string]int
+	}
+
+	before := deployment{
+		Name:    "api",
+		Servers: []server{{"a", 80, map[string]bool{"tls": true}}},
+		Limits:  map[string]int{"cpu": 2, "mem": 4},
+	}
+	after := deployment{
+		Name:    "api",
+		Servers: []server{{"b", 80, map[string]bool{"tls": true}}},
+		Limits:  map[string]int{"cpu": 3, "disk": 8},
+	}
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		opts   []Option
+		want   Stats
+	}{
+		{
+			before,
+			after,
+			nil,
+			Stats{
+				Count{Leaves: 7, Changed: 4},
+				map[string]Count{
+					".Name":    {Leaves: 1, Changed: 0},
+					".Servers": {Leaves: 3, Changed: 1},
+					".Limits":  {Leaves: 3, Changed: 3},
+				},
+			},
+		},
+		{
+			before,
+			before,
+			[]Option{WithKinds(Change), WithPathStyle(PathJSONPointer)},
+			Stats{
+				Count{Leaves: 6, Changed: 0},
+				map[string]Count{
+					"/Name":    {Leaves: 1, Changed: 0},
+					"/Servers": {Leaves: 3, Changed: 0},
+					"/Limits":  {Leaves: 2, Changed: 0},
+				},
+			},
+		},
+		{
+			[]int{},
+			[]int{},
+			nil,
+			Stats{Fields: map[string]Count{}},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ChangeStats(c.before, c.after, c.opts...)
+		if !reflect.DeepEqual(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"ChangeStats(%v, %v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, nil",
+				c.before, c.after, got, err, c.want)
+		}
+	}
+}
+
+func TestCountFraction(t *testing.T) {
+
+	cases := []struct {
+		count Count
+		want  float64
+	}{
+		{Count{}, 0},
+		{Count{Leaves: 4, Changed: 1}, 0.25},
+		{Count{Leaves: 2, Changed: 2}, 1},
+	}
+
+	for i, c := range cases {
+		got := c.count.Fraction()
+		if got != c.want {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"%+v.Fraction()\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.count, got, c.want)
+		}
+	}
+}
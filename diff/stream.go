@@ -0,0 +1,66 @@
+//go:build go1.23
+
+package diff
+
+import "context"
+
+/*
+Stream diffs before and after on a new goroutine, sending
+each difference found, as it would be by Diffs, on the first
+channel returned. The diff proceeds only as fast as the
+differences are received, so a consumer writing them
+somewhere slow holds it back rather than letting them pile
+up in memory.
+
+Once the diff is done the first channel is closed, after
+which the second yields the error that ended it, if any, and
+is closed in turn:
+
+	diffs, errc := diff.Stream(before, after)
+	for d := range diffs {
+		write(d)
+	}
+	if err := <-errc; err != nil {
+		return err
+	}
+
+The first channel must be drained, or StreamCtx used and its
+context cancelled, for the goroutine to finish. Stream is
+built on All and so requires Go 1.23 or later.
+*/
+func Stream(before, after interface{}, opts ...Option) (<-chan Diff, <-chan error) {
+	return StreamCtx(context.Background(), before, after, opts...)
+}
+
+/*
+StreamCtx works the same as Stream but abandons the diff if
+ctx is cancelled or its deadline passes, in which case the
+second channel yields ctx.Err(). Consumers may stop receiving
+differences once they've cancelled ctx.
+*/
+func StreamCtx(ctx context.Context, before, after interface{}, opts ...Option) (<-chan Diff, <-chan error) {
+
+	diffs := make(chan Diff)
+	errc := make(chan error, 1)
+
+	opts = append([]Option{withContext(ctx)}, opts...)
+
+	go func() {
+		defer close(errc)
+		defer close(diffs)
+		for d, err := range All(before, after, opts...) {
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case diffs <- d:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return diffs, errc
+}
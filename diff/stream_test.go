@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+
+	before := []int{1, 2, 3}
+	after := []int{1, 5, 6, 7}
+	want := []string{
+		"[1] changed from 2 to 5",
+		"[2] changed from 3 to 6",
+		"[3] added 7",
+	}
+
+	diffs, errc := Stream(before, after)
+
+	var got []string
+	for d := range diffs {
+		switch d.Kind {
+		case Change:
+			got = append(got, fmt.Sprintf("%s changed from %v to %v", d.Name, d.Before, d.After))
+		case Add:
+			got = append(got, fmt.Sprintf("%s added %v", d.Name, d.After))
+		}
+	}
+	err := <-errc
+
+	if !equal(got, want) || err != nil {
+		t.Errorf(
+			"Stream(%v, %v)\n"+
+				"    sent %q, %v\n"+
+				"    wanted %q, nil",
+			before, after, got, err, want)
+	}
+
+	diffs, errc = Stream(1, 2)
+	if _, ok := <-diffs; ok {
+		t.Errorf("Stream(1, 2) sent a difference, wanted none")
+	}
+	if err := <-errc; !errors.Is(err, ErrNotObject) {
+		t.Errorf("Stream(1, 2) returned %v, wanted ErrNotObject", err)
+	}
+}
+
+func TestStreamCtx(t *testing.T) {
+
+	before := make([]int, 100)
+	after := make([]int, 100)
+	for i := range after {
+		after[i] = i + 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	diffs, errc := StreamCtx(ctx, before, after)
+
+	<-diffs
+	cancel()
+
+	// Differences already being sent may still arrive.
+	n := 0
+	for range diffs {
+		n++
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) || n > 1 {
+		t.Errorf(
+			"StreamCtx(ctx, before, after) after cancel\n"+
+				"    sent %d more, returned %v\n"+
+				"    wanted at most 1 more, context.Canceled",
+			n, err)
+	}
+}
@@ -0,0 +1,31 @@
+package diff
+
+import (
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+/*
+inLocation returns the time held by v in loc, dropping its
+monotonic clock reading, so that times of the same instant
+compare as equal.
+*/
+func inLocation(v *reflect.Value, loc *time.Location) *reflect.Value {
+	if v == nil {
+		return nil
+	}
+	t := v.Interface().(time.Time).In(loc)
+	r := reflect.ValueOf(t)
+	return &r
+}
+
+/*
+diffTime compares two time.Time values as leaves after
+converting them to the location set with WithTimeLocation.
+*/
+func (d *differ) diffTime(v1, v2 *reflect.Value) error {
+	loc := d.opts.timeLocation
+	return d.diffAtom(inLocation(v1, loc), inLocation(v2, loc))
+}
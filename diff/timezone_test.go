@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWithTimeLocation(t *testing.T) {
+
+	type event struct {
+		Name  string
+		Start time.Time
+		At    []interface{}
+	}
+
+	oslo := time.FixedZone("CET", 3600)
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	later := start.Add(2 * time.Hour)
+
+	cases := []struct {
+		before event
+		after  event
+		loc    *time.Location
+		want   []string
+	}{
+		{
+			event{"a", start, []interface{}{start}},
+			event{"a", start.In(oslo), []interface{}{start.In(oslo)}},
+			time.UTC,
+			nil,
+		},
+		{
+			event{"a", start, nil},
+			event{"a", later.In(oslo), nil},
+			time.UTC,
+			[]string{
+				`.Start changed from 2024-03-01 09:00:00 +0000 UTC to 2024-03-01 11:00:00 +0000 UTC`,
+			},
+		},
+		{
+			event{"a", start, []interface{}{start}},
+			event{"a", later, []interface{}{start, later}},
+			oslo,
+			[]string{
+				`.Start changed from 2024-03-01 10:00:00 +0100 CET to 2024-03-01 12:00:00 +0100 CET`,
+				`.At[1] added 2024-03-01 12:00:00 +0100 CET`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		got, err := Objects(c.before, c.after, WithTimeLocation(c.loc))
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Objects(%v, %v, WithTimeLocation(%v))\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c.before, c.after, c.loc, got, err, c.want)
+		}
+	}
+}
+
+func TestWithTimeLocationNil(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WithTimeLocation(nil) didn't panic")
+		}
+	}()
+
+	WithTimeLocation(nil)
+}
@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/jakebowkett/go-diff/diff/internal/toml"
+)
+
+/*
+TOML parses before and after as TOML documents and returns
+the difference between them as Objects does. Tables are
+diffed as maps, so a key in a table named "server" is
+reported as `["server"]["port"]`. Dates and times are
+compared as they were written.
+
+An error is returned if either document cannot be parsed.
+*/
+func TOML(before, after []byte, opts ...Option) (changes []string, err error) {
+	return TOMLF(Format{}, before, after, opts...)
+}
+
+/*
+TOMLF works the same as TOML with an additional parameter
+allowing for custom formatting, as with ObjectsF.
+*/
+func TOMLF(format Format, before, after []byte, opts ...Option) (changes []string, err error) {
+
+	t1, err := toml.Unmarshal(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %v", err)
+	}
+	t2, err := toml.Unmarshal(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %v", err)
+	}
+
+	return objects(format, t1, t2, opts)
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTOML(t *testing.T) {
+
+	cases := []struct {
+		before  string
+		after   string
+		want    []string
+		wantErr bool
+	}{
+		// Identical documents.
+		{
+			"[server]\nport = 80\n",
+			"[server]\nport = 80 # http\n",
+			nil,
+			false,
+		},
+
+		// Changed value in a table.
+		{
+			"[server]\nport = 80\n",
+			"[server]\nport = 443\n",
+			[]string{`["server"]["port"] changed from 80 to 443`},
+			false,
+		},
+
+		// Table only in after.
+		{
+			"",
+			"[server]\nhost = 'localhost'\n",
+			[]string{`["server"]["host"] added "localhost"`},
+			false,
+		},
+
+		// Array element removed.
+		{
+			"ports = [80, 443]",
+			"ports = [80]",
+			[]string{`["ports"][1] deleted 443`},
+			false,
+		},
+
+		// Value changing type.
+		{
+			"a = 1",
+			"a = '1'",
+			[]string{`["a"] changed from 1 to "1"`},
+			false,
+		},
+
+		// Invalid document.
+		{
+			"a = 1",
+			"a = ",
+			nil,
+			true,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := TOML([]byte(c.before), []byte(c.after))
+		if !equal(got, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"TOML(%q, %q)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, error: %v",
+				c.before, c.after, got, err, c.want, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package diff
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+/*
+Tracker diffs successive versions of an object, such as one
+polled by a reconciliation loop, each against the version
+before it. It keeps its own copy of the last version, so the
+caller may modify the object in place between calls.
+
+Unchanged structs, maps, slices, and arrays are skipped as
+with WithHashPruning, which a Tracker always uses unless
+WithUnchanged is given. Each version is hashed once, as a
+whole, when it is given to Next, and its hashes are kept
+for the next call, so no version is hashed twice.
+
+Pointers are copied as they are, not what they point to,
+since they are compared by address. A value reached through
+a pointer is therefore shared between the last version and
+the next, and modifying it in place, such as setting a field
+of a struct pointed to, is not detected as a change.
+
+A Tracker is not safe for concurrent use.
+*/
+type Tracker struct {
+	opts      options
+	templates *templates
+	last      *reflect.Value
+	hashes    *hashNode
+}
+
+/*
+NewTracker returns a Tracker rendering with format and opts.
+Empty strings in format are substituted as they would be by
+ObjectsF. An error is returned if the templates cannot be
+parsed.
+*/
+func NewTracker(format Format, opts ...Option) (*Tracker, error) {
+
+	o := newOptions(append(opts, WithHashPruning()))
+	if o.unchanged {
+		o.hashPruning = false
+	}
+
+	t, err := parseFormat(format, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tracker{opts: o, templates: t}, nil
+}
+
+/*
+Next returns the differences between v and the version
+given to the previous call of Next, in the same form as
+ObjectsF, and remembers v for the next call. The first call,
+and the first after Reset, returns no differences. The same
+restrictions apply to v as to the arguments of Objects, and
+each version must be of the same type as the last. If an
+error is returned v is not remembered, except for errors
+collected with WithAggregateErrors.
+*/
+func (t *Tracker) Next(v interface{}) (changes []string, err error) {
+
+	defer observe(time.Now(), &err)
+
+	v2 := reflect.ValueOf(v)
+
+	if t.last == nil {
+		if err := isObj(reflect.TypeOf(v), "v"); err != nil {
+			return nil, err
+		}
+		t.remember(v2, t.hash(v2))
+		return nil, nil
+	}
+
+	if err := validate(t.last.Interface(), v); err != nil {
+		return nil, err
+	}
+
+	hashes := t.hash(v2)
+	d := differ{
+		opts:      t.opts,
+		templates: t.templates,
+		hashes:    []hashPair{{t.hashes, hashes}},
+	}
+	err = d.run(t.last, &v2)
+	if err != nil {
+		return nil, err
+	}
+
+	t.remember(v2, hashes)
+	if len(d.errs) > 0 {
+		return d.changes, errors.Join(d.errs...)
+	}
+
+	return d.changes, nil
+}
+
+/*
+Reset forgets the last version, so that the next call of
+Next starts afresh.
+*/
+func (t *Tracker) Reset() {
+	t.last = nil
+	t.hashes = nil
+}
+
+// hash returns the nodes of the hashes of v, or nil if the
+// Tracker doesn't prune unchanged values.
+func (t *Tracker) hash(v reflect.Value) *hashNode {
+	if !t.opts.hashPruning {
+		return nil
+	}
+	_, n := hashNodes(v, t.opts.protoNumbers)
+	return n
+}
+
+func (t *Tracker) remember(v reflect.Value, hashes *hashNode) {
+	c := deepCopy(v)
+	t.last = &c
+	t.hashes = hashes
+}
+
+/*
+deepCopy returns a copy of v sharing no structs, maps,
+slices, arrays, or interfaces with it, including those held
+in unexported fields. Pointers, funcs, and channels are
+shared.
+*/
+func deepCopy(v reflect.Value) reflect.Value {
+
+	switch v.Kind() {
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			f := field(c.Field(i))
+			f.Set(deepCopy(*f))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	}
+
+	return v
+}
@@ -0,0 +1,167 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTracker(t *testing.T) {
+
+	type pod struct {
+		Name   string
+		Labels map[string]string
+		Ports  []int
+		notes  []string
+	}
+	type cluster struct {
+		Pods  []pod
+		Extra map[interface{}]interface{}
+	}
+
+	c := cluster{
+		Pods: []pod{
+			{"a", map[string]string{"app": "x"}, []int{80}, []string{"n"}},
+			{"b", map[string]string{"app": "y"}, []int{443}, nil},
+		},
+		Extra: map[interface{}]interface{}{1: []int{1}, int8(1): []int{1}},
+	}
+
+	tr, err := NewTracker(Format{})
+	if err != nil {
+		t.Fatalf("NewTracker(Format{}) returned %v", err)
+	}
+
+	steps := []struct {
+		change func()
+		want   []string
+	}{
+		{
+			func() {},
+			nil,
+		},
+		{
+			func() {},
+			nil,
+		},
+		{
+			func() {
+				c.Pods[0].Labels["app"] = "z"
+				c.Pods[1].Ports[0] = 8443
+				c.Pods[0].notes[0] = "m"
+			},
+			[]string{
+				`.Pods[0].Labels["app"] changed from "x" to "z"`,
+				`.Pods[0].notes[0] changed from "n" to "m"`,
+				`.Pods[1].Ports[0] changed from 443 to 8443`,
+			},
+		},
+		{
+			func() {
+				c.Pods = c.Pods[:1]
+				c.Extra[int8(1)] = []int{2}
+			},
+			[]string{
+				`.Pods[1].Name deleted "b"`,
+				`.Pods[1].Labels["app"] deleted "y"`,
+				`.Pods[1].Ports[0] deleted 8443`,
+				`.Extra[1][0] changed from 1 to 2`,
+			},
+		},
+		{
+			func() {
+				c.Pods = append(c.Pods, pod{Name: "b", Ports: []int{443}})
+			},
+			[]string{
+				`.Pods[1].Name added "b"`,
+				`.Pods[1].Ports[0] added 443`,
+			},
+		},
+	}
+
+	for i, s := range steps {
+		s.change()
+		got, err := tr.Next(c)
+		if !equal(got, s.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Tracker.Next(%v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				c, got, err, s.want)
+		}
+	}
+
+	if _, err := tr.Next([]int{1}); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("Tracker.Next([]int{1}) returned %v, wanted ErrKindMismatch", err)
+	}
+
+	tr.Reset()
+	if got, err := tr.Next([]int{1}); got != nil || err != nil {
+		t.Errorf("Tracker.Next([]int{1}) after Reset returned %q, %v, wanted nil, nil", got, err)
+	}
+	if got, err := tr.Next([]int{2}); !equal(got, []string{"[0] changed from 1 to 2"}) || err != nil {
+		t.Errorf("Tracker.Next([]int{2}) returned %q, %v, wanted one change", got, err)
+	}
+}
+
+func TestTrackerHashes(t *testing.T) {
+
+	type node struct {
+		Name string
+		Next *node
+	}
+	type graph struct {
+		Nodes []node
+		Root  *node
+	}
+
+	g := graph{Nodes: []node{{Name: "a"}}, Root: &node{Name: "r"}}
+
+	tr, err := NewTracker(Format{})
+	if err != nil {
+		t.Fatalf("NewTracker(Format{}) returned %v", err)
+	}
+
+	steps := []struct {
+		change func()
+		want   []string
+	}{
+		{
+			func() {},
+			nil,
+		},
+		{
+			func() { g.Nodes[0].Name = "b" },
+			[]string{`.Nodes[0].Name changed from "a" to "b"`},
+		},
+		// What Root points to is shared with the last version
+		// so changing it in place isn't seen.
+		{
+			func() { g.Root.Name = "s" },
+			nil,
+		},
+		{
+			func() { g.Root = &node{Name: "t"} },
+			[]string{`.Root changed from {s <nil>} to {t <nil>}`},
+		},
+	}
+
+	for i, s := range steps {
+		s.change()
+		got, err := tr.Next(g)
+		if !equal(got, s.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Tracker.Next(%v)\n"+
+					"    return %q, %v\n"+
+					"    wanted %q, nil",
+				g, got, err, s.want)
+		}
+		if tr.hashes == nil || tr.hashes.sum != hashValue(reflect.ValueOf(g)) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf("Tracker kept hashes %v, wanted those of %v", tr.hashes, g)
+		}
+	}
+}
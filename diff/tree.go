@@ -0,0 +1,100 @@
+package diff
+
+/*
+Node is a location in the tree returned by Tree. The tree
+mirrors the structure of the objects that were diffed, but
+only contains the locations of differences and the structs,
+maps, slices, and arrays leading to them. Use WithUnchanged
+to include every leaf.
+*/
+type Node struct {
+
+	// Segment is the step from the node's parent to the
+	// node, and Path is the whole route from the root. Both
+	// are empty for the root.
+	Segment Segment
+	Path    Path
+
+	// Kind summarises the differences at and beneath the
+	// node. It is the kind of every difference if they are
+	// all alike, such as Add for a map entry that was added
+	// in its entirety, and Change otherwise. It is zero if
+	// there are no differences at all.
+	Kind Kind
+
+	// Diffs holds the differences reported at exactly this
+	// location. This is usually one difference for a leaf and
+	// none for anything else, but an element that moved is
+	// followed by whatever took its place.
+	Diffs []Diff
+
+	Children []*Node
+}
+
+/*
+Tree works the same as Diffs but arranges the differences
+into a tree that mirrors the structure of before and after,
+which is convenient for rendering them hierarchically. The
+root Node stands for the objects themselves.
+*/
+func Tree(before, after interface{}, opts ...Option) (*Node, error) {
+
+	diffs, err := Diffs(before, after, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &Node{}
+
+	for _, d := range diffs {
+		n := root
+		for i, s := range d.Path {
+			n = n.child(s, d.Path[:i+1:i+1])
+		}
+		n.Diffs = append(n.Diffs, d)
+	}
+
+	root.summarise()
+	return root, nil
+}
+
+/*
+child returns the child of n at s, adding it if needed.
+Differences are found depth first, so if the child exists
+it's always the last one added.
+*/
+func (n *Node) child(s Segment, path Path) *Node {
+
+	if last := len(n.Children) - 1; last >= 0 && n.Children[last].Segment == s {
+		return n.Children[last]
+	}
+
+	c := &Node{Segment: s, Path: path}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// summarise sets the Kind of n and everything beneath it.
+func (n *Node) summarise() {
+
+	var kind Kind
+	merge := func(k Kind) {
+		switch kind {
+		case 0:
+			kind = k
+		case k:
+		default:
+			kind = Change
+		}
+	}
+
+	for _, d := range n.Diffs {
+		merge(d.Kind)
+	}
+	for _, c := range n.Children {
+		c.summarise()
+		merge(c.Kind)
+	}
+
+	n.Kind = kind
+}
@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+
+	type settings struct {
+		Theme string
+		Tags  []string
+	}
+	type user struct {
+		Name     string
+		Settings settings
+		Extra    map[string]settings
+	}
+
+	cases := []struct {
+		before user
+		after  user
+		opts   []Option
+		want   []string
+	}{
+		{
+			user{Name: "a"},
+			user{Name: "a"},
+			nil,
+			[]string{`Kind(0)`},
+		},
+		{
+			user{
+				Name:     "a",
+				Settings: settings{"dark", []string{"x"}},
+			},
+			user{
+				Name:     "b",
+				Settings: settings{"dark", []string{"x", "y"}},
+				Extra: map[string]settings{
+					"k": {"light", nil},
+				},
+			},
+			nil,
+			[]string{
+				`change`,
+				`  .Name change 1`,
+				`  .Settings add`,
+				`    .Tags add`,
+				`      [1] add 1`,
+				`  .Extra add`,
+				`    ["k"] add`,
+				`      .Theme add 1`,
+			},
+		},
+		{
+			user{Settings: settings{Tags: []string{"a", "b", "c"}}},
+			user{Settings: settings{Tags: []string{"c", "a", "x"}}},
+			[]Option{WithMoves()},
+			[]string{
+				`change`,
+				`  .Settings change`,
+				`    .Tags change`,
+				`      [1] delete 1`,
+				`      [2] change 2`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		root, err := Tree(c.before, c.after, c.opts...)
+		var got []string
+		if root != nil {
+			got = printTree(root, "", nil)
+		}
+
+		if !equal(got, c.want) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"Tree(%v, %v)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, nil",
+				c.before, c.after, strings.Join(got, "\n"), err, strings.Join(c.want, "\n"))
+		}
+	}
+}
+
+func printTree(n *Node, indent string, lines []string) []string {
+
+	line := indent + n.Segment.String() + " " + n.Kind.String()
+	if len(n.Diffs) > 0 {
+		line += fmt.Sprintf(" %d", len(n.Diffs))
+	}
+	if indent == "" {
+		line = n.Kind.String()
+	}
+	lines = append(lines, line)
+
+	for _, c := range n.Children {
+		lines = printTree(c, indent+"  ", lines)
+	}
+
+	return lines
+}
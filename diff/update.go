@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/*
+UpdateSet returns the SET clause of an SQL UPDATE statement
+assigning the columns whose fields differ between before and
+after, such as "name = ?, email = ?", along with the values
+of those fields in after as arguments for its placeholders.
+If nothing differs set is empty and args is nil.
+
+The arguments before and after must be structs of the same
+type, or pointers to them. Each exported field is a column
+named by its `db` struct tag, or by the field's name if it
+has none. Fields tagged `db:"-"` are skipped, as are
+unexported fields. The fields of an embedded struct without
+a tag are treated as fields of the outer struct.
+
+Fields are compared as they would be by Equal with opts, so
+WithTransform may be used to ignore insignificant changes.
+Values are passed as they are, which suits types such as
+sql.NullString and time.Time that database drivers accept.
+*/
+func UpdateSet(before, after interface{}, opts ...Option) (set string, args []interface{}, err error) {
+
+	defer observe(time.Now(), &err)
+
+	v1 := reflect.Indirect(reflect.ValueOf(before))
+	v2 := reflect.Indirect(reflect.ValueOf(after))
+
+	for _, arg := range []struct {
+		v     reflect.Value
+		which string
+	}{{v1, "before"}, {v2, "after"}} {
+		if !arg.v.IsValid() || arg.v.Kind() != reflect.Struct {
+			var t reflect.Type
+			if arg.v.IsValid() {
+				t = arg.v.Type()
+			}
+			return "", nil, notObject(t, arg.which, fmt.Sprintf(
+				`argument %q was %s, wanted a struct or pointer to one`,
+				arg.which, kindOf(arg.v)))
+		}
+	}
+	if err := sameNamedType(v1.Type(), v2.Type()); err != nil {
+		return "", nil, err
+	}
+	if v1.Type() != v2.Type() {
+		return "", nil, &ObjectError{
+			Err:        ErrTypeMismatch,
+			BeforeKind: reflect.Struct,
+			AfterKind:  reflect.Struct,
+			BeforeType: v1.Type().String(),
+			AfterType:  v2.Type().String(),
+			msg: fmt.Sprintf(
+				`objects must be same type - "before" was %s, "after" was %s`,
+				v1.Type(), v2.Type()),
+		}
+	}
+
+	d := differ{opts: newOptions(opts), stopEarly: true}
+
+	var cols []string
+	for _, c := range columnsOf(v1.Type(), nil) {
+
+		f1 := v1.FieldByIndex(c.index)
+		f2 := v2.FieldByIndex(c.index)
+
+		d.rewind()
+		err := d.run(&f1, &f2)
+		if err == nil {
+			continue
+		}
+		if err != errStop {
+			return "", nil, err
+		}
+
+		cols = append(cols, c.name+" = ?")
+		args = append(args, f2.Interface())
+	}
+
+	return strings.Join(cols, ", "), args, nil
+}
+
+func kindOf(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return fmt.Sprintf("of kind %q", v.Kind())
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+// columnsOf returns the columns of struct type t, whose
+// fields are found at index within the outermost struct.
+func columnsOf(t reflect.Type, index []int) []column {
+
+	var cols []column
+
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		idx := append(append([]int(nil), index...), i)
+
+		if sf.Anonymous && !tagged && sf.Type.Kind() == reflect.Struct {
+			cols = append(cols, columnsOf(sf.Type, idx)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if tagged {
+			name = strings.Split(tag, ",")[0]
+		}
+		cols = append(cols, column{name, idx})
+	}
+
+	return cols
+}
@@ -0,0 +1,125 @@
+package diff
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpdateSet(t *testing.T) {
+
+	type audit struct {
+		UpdatedBy string `db:"updated_by"`
+		version   int
+	}
+	type row struct {
+		ID    int    `db:"id"`
+		Name  string `db:"name,omitempty"`
+		Email sql.NullString
+		Tags  []string `db:"tags"`
+		Cache string   `db:"-"`
+		audit
+		note string
+	}
+
+	before := row{1, "ann", sql.NullString{}, []string{"a"}, "x", audit{"root", 1}, "n"}
+
+	cases := []struct {
+		after    interface{}
+		opts     []Option
+		wantSet  string
+		wantArgs []interface{}
+	}{
+		{
+			row{1, "Ann ", sql.NullString{String: "a@b", Valid: true}, []string{"a"}, "y", audit{"ann", 2}, "m"},
+			nil,
+			"name = ?, Email = ?, updated_by = ?",
+			[]interface{}{"Ann ", sql.NullString{String: "a@b", Valid: true}, "ann"},
+		},
+		{
+			&row{1, "ann", sql.NullString{}, []string{"a", "b"}, "x", audit{"root", 1}, "n"},
+			nil,
+			"tags = ?",
+			[]interface{}{[]string{"a", "b"}},
+		},
+		{
+			row{1, "ANN", sql.NullString{}, []string{"a"}, "x", audit{"root", 1}, "n"},
+			[]Option{WithTransform(strings.ToLower)},
+			"",
+			nil,
+		},
+	}
+
+	for i, c := range cases {
+		set, args, err := UpdateSet(before, c.after, c.opts...)
+		if set != c.wantSet || !reflect.DeepEqual(args, c.wantArgs) || err != nil {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"UpdateSet(%v, %v)\n"+
+					"    return %q, %v, %v\n"+
+					"    wanted %q, %v, nil",
+				before, c.after, set, args, err, c.wantSet, c.wantArgs)
+		}
+	}
+}
+
+func TestUpdateSetNested(t *testing.T) {
+
+	type address struct {
+		City string
+		Zip  string
+	}
+	type row struct {
+		Home address `db:"home"`
+		Work address `db:"work"`
+	}
+
+	// The diff of home stops within it, at .Zip, which
+	// mustn't be taken for the path of work's fields.
+	before := row{address{"Oslo", "0150"}, address{"Oslo", "0150"}}
+	after := row{address{"Oslo", "0151"}, address{"Bergen", "0150"}}
+
+	set, args, err := UpdateSet(before, after, WithFields(".Zip"))
+	want := []interface{}{address{"Oslo", "0151"}}
+	if set != "home = ?" || !reflect.DeepEqual(args, want) || err != nil {
+		t.Errorf(
+			"UpdateSet(%v, %v, WithFields(%q))\n"+
+				"    return %q, %v, %v\n"+
+				"    wanted %q, %v, nil",
+			before, after, ".Zip", set, args, err, "home = ?", want)
+	}
+}
+
+func TestUpdateSetErrors(t *testing.T) {
+
+	type a struct{ X int }
+	type b struct{ X int }
+	var nilA *a
+
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		want   error
+	}{
+		{nil, a{}, ErrNotObject},
+		{nilA, a{}, ErrNotObject},
+		{a{}, map[string]int{}, ErrNotObject},
+		{a{}, b{}, ErrTypeMismatch},
+		{struct{ X int }{}, struct{ Y int }{}, ErrTypeMismatch},
+	}
+
+	for i, c := range cases {
+		_, _, err := UpdateSet(c.before, c.after)
+		if !errors.Is(err, c.want) {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"UpdateSet(%v, %v)\n"+
+					"    return %v\n"+
+					"    wanted %v",
+				c.before, c.after, err, c.want)
+		}
+	}
+}
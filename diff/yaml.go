@@ -0,0 +1,53 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/jakebowkett/go-diff/diff/internal/yaml"
+)
+
+/*
+YAML parses before and after as YAML documents and returns
+the difference between them as Objects does. Mappings are
+diffed as maps and sequences as slices, so a key in a
+mapping named "server" is reported as `["server"]["port"]`.
+An empty document is treated as an empty mapping, or as an
+empty sequence if the other document is a sequence.
+
+Only the subset of YAML commonly used for configuration is
+supported. An error is returned if either document cannot be
+parsed, or uses anchors, aliases, or tags.
+*/
+func YAML(before, after []byte, opts ...Option) (changes []string, err error) {
+	return YAMLF(Format{}, before, after, opts...)
+}
+
+/*
+YAMLF works the same as YAML with an additional parameter
+allowing for custom formatting, as with ObjectsF.
+*/
+func YAMLF(format Format, before, after []byte, opts ...Option) (changes []string, err error) {
+
+	y1, err := yaml.Unmarshal(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %v", err)
+	}
+	y2, err := yaml.Unmarshal(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %v", err)
+	}
+
+	return objects(format, yamlRoot(y1, y2), yamlRoot(y2, y1), opts)
+}
+
+// yamlRoot substitutes an empty document with
+// an empty collection like other.
+func yamlRoot(v, other interface{}) interface{} {
+	if v != nil {
+		return v
+	}
+	if _, ok := other.([]interface{}); ok {
+		return []interface{}{}
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestYAML(t *testing.T) {
+
+	cases := []struct {
+		before  string
+		after   string
+		want    []string
+		wantErr bool
+	}{
+		// Identical documents.
+		{
+			"server:\n  port: 80\n",
+			"server:\n  port: 80 # http\n",
+			nil,
+			false,
+		},
+
+		// Changed value in a mapping.
+		{
+			"server:\n  port: 80\n",
+			"server:\n  port: 443\n",
+			[]string{`["server"]["port"] changed from 80 to 443`},
+			false,
+		},
+
+		// Empty document.
+		{
+			"",
+			"server:\n  host: localhost\n",
+			[]string{`["server"]["host"] added "localhost"`},
+			false,
+		},
+		{
+			"- a\n",
+			"",
+			[]string{`[0] deleted "a"`},
+			false,
+		},
+
+		// Sequence element removed.
+		{
+			"ports: [80, 443]",
+			"ports:\n  - 80\n",
+			[]string{`["ports"][1] deleted 443`},
+			false,
+		},
+
+		// Value changing type.
+		{
+			"a: 1",
+			"a: '1'",
+			[]string{`["a"] changed from 1 to "1"`},
+			false,
+		},
+
+		// Invalid document.
+		{
+			"a: 1",
+			"a: [",
+			nil,
+			true,
+		},
+
+		// Documents of different kinds.
+		{
+			"a: 1",
+			"- 1",
+			nil,
+			true,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := YAML([]byte(c.before), []byte(c.after))
+		if !equal(got, c.want) || (err != nil) != c.wantErr {
+			fmt.Printf("Case #%d:\n", i+1)
+			t.Errorf(
+				"YAML(%q, %q)\n"+
+					"    return %v, %v\n"+
+					"    wanted %v, error: %v",
+				c.before, c.after, got, err, c.want, c.wantErr)
+		}
+	}
+}